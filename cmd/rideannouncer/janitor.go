@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/obalunenko/getenv"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service"
+)
+
+const (
+	envSessionTTL      = "RIDE_ANNOUNCER_SESSION_TTL"
+	envJanitorInterval = "RIDE_ANNOUNCER_JANITOR_INTERVAL"
+	envNotifyExpired   = "RIDE_ANNOUNCER_NOTIFY_EXPIRED"
+)
+
+const (
+	defaultSessionTTL      = 24 * time.Hour
+	defaultJanitorInterval = 10 * time.Minute
+)
+
+// janitorOption returns service option for the cleanup of idle sessions configured via environment.
+func janitorOption() service.Option {
+	return service.WithJanitor(service.JanitorConfig{
+		SessionTTL:    getenv.EnvOrDefault(envSessionTTL, defaultSessionTTL),
+		Interval:      getenv.EnvOrDefault(envJanitorInterval, defaultJanitorInterval),
+		NotifyExpired: getenv.EnvOrDefault(envNotifyExpired, false),
+	})
+}
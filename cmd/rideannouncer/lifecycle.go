@@ -0,0 +1,27 @@
+package main
+
+import (
+	"time"
+
+	"github.com/obalunenko/getenv"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service"
+)
+
+const (
+	envTripFinishAfter  = "RIDE_ANNOUNCER_TRIP_FINISH_AFTER"
+	envTripArchiveAfter = "RIDE_ANNOUNCER_TRIP_ARCHIVE_AFTER"
+)
+
+const (
+	defaultTripFinishAfter  = 6 * time.Hour
+	defaultTripArchiveAfter = 7 * 24 * time.Hour
+)
+
+// lifecycleOption returns service option for the trip lifecycle configured via environment.
+func lifecycleOption() service.Option {
+	return service.WithLifecycle(service.LifecycleConfig{
+		FinishAfter:  getenv.EnvOrDefault(envTripFinishAfter, defaultTripFinishAfter),
+		ArchiveAfter: getenv.EnvOrDefault(envTripArchiveAfter, defaultTripArchiveAfter),
+	})
+}
@@ -8,21 +8,19 @@
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata" // embed timezone database, so trip timezones work in minimal images.
 
 	"github.com/obalunenko/getenv"
 	log "github.com/obalunenko/logger"
 
-	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
-	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
-	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
-	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/users"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service"
-	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service/backends"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/telegram"
 )
 
 const (
 	envTGAPIToken = "RIDE_ANNOUNCER_TELEGRAM_TOKEN"
+	envTimezone   = "RIDE_ANNOUNCER_TIMEZONE"
 )
 
 const (
@@ -35,11 +33,16 @@
 	telegram.NewCommand(service.CmdStart, "start using the bot", true),
 	telegram.NewCommand(service.CmdHelp, "show help", true),
 	telegram.NewCommand(service.CmdNewTrip, "create new trip", true),
-	telegram.NewCommand(service.CmdTrips, "show all trips", false),
-	telegram.NewCommand(service.CmdSubscribe, "subscribe to a trip", false),
-	telegram.NewCommand(service.CmdUnsubscribe, "unsubscribe from a trip", false),
+	telegram.NewCommand(service.CmdEditTrip, "edit a published trip", true),
+	telegram.NewCommand(service.CmdCancelTrip, "cancel a published trip", true),
+	telegram.NewCommand(service.CmdCancel, "cancel the current operation", true),
+	telegram.NewCommand(service.CmdTrips, "show upcoming trips", true),
+	telegram.NewCommand(service.CmdSubscribe, "subscribe to a trip", true),
+	telegram.NewCommand(service.CmdUnsubscribe, "unsubscribe from a trip", true),
 	telegram.NewCommand(service.CmdMyTrips, "show trips you've created", true),
-	telegram.NewCommand(service.CmdSubscribed, "show trips you've subscribed to", false),
+	telegram.NewCommand(service.CmdSubscribed, "show trips you've subscribed to", true),
+	telegram.NewCommand(service.CmdPolicy, "show or set who may announce trips in the group", true),
+	telegram.NewCommand(service.CmdLeader, "manage approved ride leaders of the group", true),
 }
 
 func main() {
@@ -60,8 +63,6 @@ func main() {
 		stop(fmt.Errorf("received signal %q", s.String()))
 	}()
 
-	printVersion(ctx)
-
 	log.Init(ctx, log.Params{
 		Writer: os.Stdout,
 		Level:  "DEBUG",
@@ -70,6 +71,20 @@ func main() {
 
 	ctx = log.ContextWithLogger(ctx, log.FromContext(ctx))
 
+	if len(os.Args) > 1 && os.Args[1] == cmdMigrate {
+		if err := runMigrate(ctx, os.Args[2:]); err != nil {
+			log.WithError(ctx, err).Fatal("migrate failed")
+		}
+
+		return
+	}
+
+	runBot(ctx)
+}
+
+func runBot(ctx context.Context) {
+	printVersion(ctx)
+
 	log.Info(ctx, "Starting bot")
 
 	token, err := getenv.Env[string](envTGAPIToken)
@@ -88,29 +103,37 @@ func main() {
 		log.WithError(ctx, err).Fatal("failed to create telegram bot")
 	}
 
-	sessionsRepo := sessions.NewInMemory()
-	usersRepo := users.NewInMemory()
-	statesRepo := states.NewInMemory()
-	tripsRepo := trips.NewInMemory()
+	b, closeBackends, err := newBackends(ctx)
+	if err != nil {
+		log.WithError(ctx, err).Fatal("failed to create backends for service")
+	}
 
-	params := backends.NewParams{
-		Sessions: sessionsRepo,
-		Users:    usersRepo,
-		States:   statesRepo,
-		Trips:    tripsRepo,
+	defer closeBackends()
+
+	loc, err := time.LoadLocation(getenv.EnvOrDefault(envTimezone, "UTC"))
+	if err != nil {
+		log.WithError(ctx, err).Fatal("failed to load timezone")
 	}
 
-	b, err := backends.New(params)
+	svcOpts, err := updatesOptions()
 	if err != nil {
-		log.WithError(ctx, err).Fatal("failed to create backends for service")
+		log.WithError(ctx, err).Fatal("failed to configure updates receiving")
 	}
 
-	svc, err := service.New(bot, b)
+	svcOpts = append(svcOpts, service.WithLocation(loc), janitorOption(), lifecycleOption(), remindersOption(),
+		shutdownOption(), dispatchOption(), sendOption())
+
+	svc, err := service.New(bot, b, svcOpts...)
 	if err != nil {
 		log.WithError(ctx, err).Fatal("failed to create service")
 	}
 
-	svc.Start(ctx)
+	if err = svc.Start(ctx); err != nil {
+		// Fatal exits right away, so the storage is closed before.
+		closeBackends()
+
+		log.WithError(ctx, err).Fatal("failed to start service")
+	}
 
 	<-ctx.Done()
 
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/migrations"
+)
+
+const cmdMigrate = "migrate"
+
+const (
+	migrateUpCmd     = "up"
+	migrateDownCmd   = "down"
+	migrateStatusCmd = "status"
+)
+
+const migrateUsage = "usage: rideannouncer migrate up|down|status"
+
+// runMigrate executes migrate subcommand against the SQLite database.
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := openSQLite(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer closeDB(ctx, db)()
+
+	m, err := migrations.New(db)
+	if err != nil {
+		return fmt.Errorf("failed to create migrator: %w", err)
+	}
+
+	switch args[0] {
+	case migrateUpCmd:
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+
+		log.WithField(ctx, "applied", len(applied)).Info("Database is up to date")
+	case migrateDownCmd:
+		mg, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+
+		log.WithFields(ctx, log.Fields{
+			"version": mg.Version,
+			"name":    mg.Name,
+		}).Info("Rolled back one migration")
+	case migrateStatusCmd:
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		return printMigrationsStatus(list)
+	default:
+		return fmt.Errorf("unknown migrate command %q: %s", args[0], migrateUsage)
+	}
+
+	return nil
+}
+
+func migrateUp(ctx context.Context, db *sql.DB) error {
+	m, err := migrations.New(db)
+	if err != nil {
+		return fmt.Errorf("failed to create migrator: %w", err)
+	}
+
+	if _, err = m.Up(ctx); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return nil
+}
+
+func printMigrationsStatus(list []migrations.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if _, err := fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT"); err != nil {
+		return err
+	}
+
+	for _, s := range list {
+		appliedAt := "pending"
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+
+		if _, err := fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"time"
+
+	"github.com/obalunenko/getenv"
+	"github.com/obalunenko/getenv/option"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service"
+)
+
+const (
+	envReminderOffsets      = "RIDE_ANNOUNCER_REMINDER_OFFSETS"
+	envReminderInterval     = "RIDE_ANNOUNCER_REMINDER_INTERVAL"
+	envRemindParticipants   = "RIDE_ANNOUNCER_REMIND_PARTICIPANTS"
+	envRemindAnnouncedChats = "RIDE_ANNOUNCER_REMIND_CHAT"
+)
+
+const defaultReminderInterval = time.Minute
+
+// defaultReminderOffsets are sent a day and an hour before the trip start.
+var defaultReminderOffsets = []time.Duration{24 * time.Hour, time.Hour}
+
+// remindersOption returns service option for reminders about upcoming trips configured via environment.
+// Reminders are disabled when there are no recipients.
+func remindersOption() service.Option {
+	cfg := service.ReminderConfig{
+		Offsets:      getenv.EnvOrDefault(envReminderOffsets, defaultReminderOffsets, option.WithSeparator(",")),
+		Interval:     getenv.EnvOrDefault(envReminderInterval, defaultReminderInterval),
+		Participants: getenv.EnvOrDefault(envRemindParticipants, true),
+		Chat:         getenv.EnvOrDefault(envRemindAnnouncedChats, false),
+	}
+
+	if !cfg.Participants && !cfg.Chat {
+		cfg.Offsets = nil
+	}
+
+	return service.WithReminders(cfg)
+}
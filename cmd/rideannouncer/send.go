@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/obalunenko/getenv"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service"
+)
+
+const (
+	envSendGlobalLimit = "RIDE_ANNOUNCER_SEND_GLOBAL_LIMIT"
+	envSendGroupLimit  = "RIDE_ANNOUNCER_SEND_GROUP_LIMIT"
+	envSendMaxRetries  = "RIDE_ANNOUNCER_SEND_MAX_RETRIES"
+)
+
+const (
+	defaultSendGlobalLimit = 30
+	defaultSendGroupLimit  = 20
+	defaultSendMaxRetries  = 3
+)
+
+// sendOption returns service option for delivery of outgoing messages configured via environment.
+func sendOption() service.Option {
+	return service.WithSend(service.SendConfig{
+		GlobalPerSecond: getenv.EnvOrDefault(envSendGlobalLimit, defaultSendGlobalLimit),
+		GroupPerMinute:  getenv.EnvOrDefault(envSendGroupLimit, defaultSendGroupLimit),
+		MaxRetries:      getenv.EnvOrDefault(envSendMaxRetries, defaultSendMaxRetries),
+	})
+}
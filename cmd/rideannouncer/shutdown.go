@@ -0,0 +1,24 @@
+package main
+
+import (
+	"time"
+
+	"github.com/obalunenko/getenv"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service"
+)
+
+const (
+	envShutdownTimeout      = "RIDE_ANNOUNCER_SHUTDOWN_TIMEOUT"
+	envShutdownAnnouncement = "RIDE_ANNOUNCER_SHUTDOWN_ANNOUNCEMENT"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+// shutdownOption returns service option for graceful shutdown configured via environment.
+func shutdownOption() service.Option {
+	return service.WithShutdown(service.ShutdownConfig{
+		DrainTimeout: getenv.EnvOrDefault(envShutdownTimeout, defaultShutdownTimeout),
+		Announcement: getenv.EnvOrDefault(envShutdownAnnouncement, ""),
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/obalunenko/getenv"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/database"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/users"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service/backends"
+)
+
+const (
+	envStorage       = "RIDE_ANNOUNCER_STORAGE"
+	envSQLitePath    = "RIDE_ANNOUNCER_SQLITE_PATH"
+	envSQLiteMigrate = "RIDE_ANNOUNCER_SQLITE_AUTO_MIGRATE"
+)
+
+const (
+	storageMemory = "memory"
+	storageSQLite = "sqlite"
+)
+
+const defaultSQLitePath = "rideannouncer.db"
+
+// newBackends creates repositories for the storage selected via environment.
+// Returned close function must be called on exit.
+func newBackends(ctx context.Context) (*backends.Backends, func(), error) {
+	storage := getenv.EnvOrDefault(envStorage, storageMemory)
+
+	log.WithField(ctx, "storage", storage).Info("Initializing storage")
+
+	var (
+		params  backends.NewParams
+		closeFn = func() {}
+	)
+
+	switch storage {
+	case storageMemory:
+		params = backends.NewParams{
+			Sessions:     sessions.NewInMemory(),
+			Users:        users.NewInMemory(),
+			States:       states.NewInMemory(),
+			Trips:        trips.NewInMemory(),
+			Participants: participants.NewInMemory(),
+			Chats:        chats.NewInMemory(),
+			Reminders:    reminders.NewInMemory(),
+		}
+	case storageSQLite:
+		db, err := openSQLite(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		closeFn = closeDB(ctx, db)
+
+		if getenv.EnvOrDefault(envSQLiteMigrate, true) {
+			if err = migrateUp(ctx, db); err != nil {
+				closeFn()
+
+				return nil, nil, err
+			}
+		}
+
+		params = backends.NewParams{
+			Sessions:     sessions.NewSQLite(db),
+			Users:        users.NewSQLite(db),
+			States:       states.NewSQLite(db),
+			Trips:        trips.NewSQLite(db),
+			Participants: participants.NewSQLite(db),
+			Chats:        chats.NewSQLite(db),
+			Reminders:    reminders.NewSQLite(db),
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported storage %q", storage)
+	}
+
+	b, err := backends.New(params)
+	if err != nil {
+		closeFn()
+
+		return nil, nil, err
+	}
+
+	return b, closeFn, nil
+}
+
+func openSQLite(ctx context.Context) (*sql.DB, error) {
+	db, err := database.Open(ctx, getenv.EnvOrDefault(envSQLitePath, defaultSQLitePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	return db, nil
+}
+
+func closeDB(ctx context.Context, db *sql.DB) func() {
+	return func() {
+		if err := db.Close(); err != nil {
+			log.WithError(ctx, err).Error("Failed to close database")
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/obalunenko/getenv"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service"
+)
+
+const (
+	envUpdatesMode       = "RIDE_ANNOUNCER_UPDATES_MODE"
+	envWebhookURL        = "RIDE_ANNOUNCER_WEBHOOK_URL"
+	envWebhookPath       = "RIDE_ANNOUNCER_WEBHOOK_PATH"
+	envWebhookListenAddr = "RIDE_ANNOUNCER_WEBHOOK_LISTEN_ADDR"
+	envWebhookSecret     = "RIDE_ANNOUNCER_WEBHOOK_SECRET_TOKEN"
+	envWebhookTLSCert    = "RIDE_ANNOUNCER_WEBHOOK_TLS_CERT"
+	envWebhookTLSKey     = "RIDE_ANNOUNCER_WEBHOOK_TLS_KEY"
+)
+
+const (
+	updatesModePolling = "polling"
+	updatesModeWebhook = "webhook"
+)
+
+const (
+	defaultWebhookPath       = "/telegram/webhook"
+	defaultWebhookListenAddr = ":8080"
+)
+
+// updatesOptions returns service options for the updates mode selected via environment.
+func updatesOptions() ([]service.Option, error) {
+	mode := getenv.EnvOrDefault(envUpdatesMode, updatesModePolling)
+
+	switch mode {
+	case updatesModePolling:
+		return nil, nil
+	case updatesModeWebhook:
+		webhookURL, err := getenv.Env[string](envWebhookURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get webhook url: %w", err)
+		}
+
+		secret, err := getenv.Env[string](envWebhookSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get webhook secret token: %w", err)
+		}
+
+		return []service.Option{
+			service.WithWebhook(service.WebhookConfig{
+				URL:         webhookURL,
+				Path:        getenv.EnvOrDefault(envWebhookPath, defaultWebhookPath),
+				ListenAddr:  getenv.EnvOrDefault(envWebhookListenAddr, defaultWebhookListenAddr),
+				SecretToken: secret,
+				CertFile:    getenv.EnvOrDefault(envWebhookTLSCert, ""),
+				KeyFile:     getenv.EnvOrDefault(envWebhookTLSKey, ""),
+			}),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported updates mode %q", mode)
+	}
+}
+
+const (
+	envWorkers          = "RIDE_ANNOUNCER_WORKERS"
+	envUpdatesQueueSize = "RIDE_ANNOUNCER_UPDATES_QUEUE_SIZE"
+)
+
+const (
+	defaultWorkers          = 16
+	defaultUpdatesQueueSize = 256
+)
+
+// dispatchOption returns service option for concurrent processing of updates configured via environment.
+func dispatchOption() service.Option {
+	return service.WithDispatch(service.DispatchConfig{
+		Workers:   getenv.EnvOrDefault(envWorkers, defaultWorkers),
+		QueueSize: getenv.EnvOrDefault(envUpdatesQueueSize, defaultUpdatesQueueSize),
+	})
+}
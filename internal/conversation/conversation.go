@@ -0,0 +1,429 @@
+// Package conversation runs multi-step conversations, such as trip creation wizard.
+//
+// A conversation is a list of steps, each step waits for the user input in its own session state.
+// The active conversation is stored in the session by its command, so the engine knows which
+// conversation the input belongs to.
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+
+	tgbotapi "github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+const (
+	// BackButton returns to the previous step.
+	BackButton = "⬅ Back"
+	// SkipButton skips the optional step.
+	SkipButton = "Skip"
+)
+
+var (
+	// ErrUnknownConversation is returned when there is no conversation registered for the command.
+	ErrUnknownConversation = errors.New("unknown conversation")
+	// ErrNoActiveConversation is returned when the session has no conversation in progress.
+	ErrNoActiveConversation = errors.New("no active conversation")
+	// ErrUnknownStep is returned when the session state doesn't belong to the active conversation.
+	ErrUnknownStep = errors.New("unknown conversation step")
+	// ErrNoBack is returned when the current step has no previous step.
+	ErrNoBack = errors.New("step has no previous step")
+	// ErrNotOptional is returned when the current step can't be skipped.
+	ErrNotOptional = errors.New("step is not optional")
+)
+
+// ValidationError is returned by ValidateFunc when the input is invalid.
+type ValidationError struct {
+	// Reason is shown to the user before asking again.
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// Invalid returns a validation error with the reason shown to the user.
+func Invalid(reason string) error {
+	return &ValidationError{Reason: reason}
+}
+
+// Prompt is a message asking the user for the step input.
+type Prompt struct {
+	Text string
+	// HTML is true when Text is formatted with Telegram HTML.
+	HTML     bool
+	Keyboard tgbotapi.ReplyMarkup
+}
+
+// PromptFunc builds a prompt for the step.
+// A nil prompt means that the step has already talked to the user by itself.
+type PromptFunc func(ctx context.Context, sess *models.Session) (*Prompt, error)
+
+// ValidateFunc checks the step input. A *ValidationError makes the engine ask the step again,
+// other errors are returned to the caller.
+type ValidateFunc func(ctx context.Context, sess *models.Session, input string) error
+
+// StoreFunc stores the step input. It returns the next state,
+// models.StateStart finishes the conversation and a zero state means Step.Next.
+type StoreFunc func(ctx context.Context, sess *models.Session, input string) (models.State, error)
+
+// Step is a single step of a conversation.
+type Step struct {
+	// State is a session state the step waits for the input in.
+	State models.State
+	// Prompt asks the user for the input.
+	Prompt PromptFunc
+	// Validate checks the input before it's stored, optional.
+	Validate ValidateFunc
+	// Store stores the input. Steps without Store don't accept text input and ask again.
+	Store StoreFunc
+	// Next is a state of the next step, models.StateStart finishes the conversation.
+	Next models.State
+	// Back is a state of the previous step, zero when going back is not allowed.
+	Back models.State
+	// Optional steps could be skipped.
+	Optional bool
+}
+
+// Conversation is a multi-step conversation started by a command.
+type Conversation struct {
+	// Command starts the conversation.
+	Command string
+	// Begin prepares the session for the conversation, optional.
+	Begin func(ctx context.Context, sess *models.Session) error
+	// Cancel cleans up the session when the conversation is canceled, optional.
+	Cancel func(ctx context.Context, sess *models.Session) error
+	// Steps of the conversation, the first one is asked on start.
+	Steps []Step
+}
+
+func (c *Conversation) step(state models.State) (*Step, bool) {
+	for i := range c.Steps {
+		if c.Steps[i].State == state {
+			return &c.Steps[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// SendFunc sends a prompt to the session chat.
+type SendFunc func(ctx context.Context, sess *models.Session, p Prompt) error
+
+// Engine runs registered conversations.
+type Engine struct {
+	conversations map[string]*Conversation
+	send          SendFunc
+}
+
+// NewEngine creates a new engine with given conversations.
+func NewEngine(send SendFunc, conversations ...*Conversation) (*Engine, error) {
+	e := &Engine{
+		conversations: make(map[string]*Conversation, len(conversations)),
+		send:          send,
+	}
+
+	states := make(map[models.State]string)
+
+	for _, c := range conversations {
+		if len(c.Steps) == 0 {
+			return nil, fmt.Errorf("conversation %q has no steps", c.Command)
+		}
+
+		if _, ok := e.conversations[c.Command]; ok {
+			return nil, fmt.Errorf("conversation %q is already registered", c.Command)
+		}
+
+		for _, st := range c.Steps {
+			if cmd, ok := states[st.State]; ok {
+				return nil, fmt.Errorf("state %s is already used by conversation %q", st.State, cmd)
+			}
+
+			states[st.State] = c.Command
+		}
+
+		e.conversations[c.Command] = c
+	}
+
+	return e, nil
+}
+
+// Active returns the conversation in progress in the session.
+func (e *Engine) Active(sess *models.Session) (*Conversation, bool) {
+	if sess.UserState.Command == "" {
+		return nil, false
+	}
+
+	c, ok := e.conversations[sess.UserState.Command]
+	if !ok {
+		return nil, false
+	}
+
+	if _, ok = c.step(sess.UserState.State); !ok {
+		return nil, false
+	}
+
+	return c, true
+}
+
+// Start starts the conversation for the command and asks the first step.
+func (e *Engine) Start(ctx context.Context, sess *models.Session, command string) error {
+	c, ok := e.conversations[command]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownConversation, command)
+	}
+
+	sess.UserState.Command = c.Command
+
+	if c.Begin != nil {
+		if err := c.Begin(ctx, sess); err != nil {
+			return fmt.Errorf("failed to begin conversation %q: %w", c.Command, err)
+		}
+	}
+
+	return e.moveTo(ctx, sess, c.Steps[0].State)
+}
+
+// Ask asks the current step again.
+func (e *Engine) Ask(ctx context.Context, sess *models.Session) error {
+	_, st, err := e.current(sess)
+	if err != nil {
+		return err
+	}
+
+	return e.ask(ctx, sess, st, "")
+}
+
+// Handle handles the user input on the current step.
+// Back and Skip buttons are handled as well.
+func (e *Engine) Handle(ctx context.Context, sess *models.Session, input string) error {
+	_, st, err := e.current(sess)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case input == BackButton && st.Back != 0:
+		return e.moveTo(ctx, sess, st.Back)
+	case input == SkipButton && st.Optional:
+		return e.moveTo(ctx, sess, st.Next)
+	}
+
+	if st.Store == nil {
+		return e.ask(ctx, sess, st, "")
+	}
+
+	if st.Validate != nil {
+		if err = st.Validate(ctx, sess, input); err != nil {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				return fmt.Errorf("failed to validate %s input: %w", st.State, err)
+			}
+
+			log.WithFields(ctx, log.Fields{
+				"command": sess.UserState.Command,
+				"step":    st.State.String(),
+				"reason":  verr.Reason,
+			}).Info("Conversation input validation failed")
+
+			return e.ask(ctx, sess, st, verr.Reason)
+		}
+	}
+
+	next, err := st.Store(ctx, sess, input)
+	if err != nil {
+		return fmt.Errorf("failed to store %s input: %w", st.State, err)
+	}
+
+	if next == 0 {
+		next = st.Next
+	}
+
+	return e.moveTo(ctx, sess, next)
+}
+
+// Reject asks the current step again with the reason shown to the user.
+// It's used for input handled outside of the engine, such as uploaded files.
+func (e *Engine) Reject(ctx context.Context, sess *models.Session, reason string) error {
+	_, st, err := e.current(sess)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"command": sess.UserState.Command,
+		"step":    st.State.String(),
+		"reason":  reason,
+	}).Info("Conversation input rejected")
+
+	return e.ask(ctx, sess, st, reason)
+}
+
+// Advance moves on once the current step input is handled outside of the engine, such as uploaded files.
+// The next state is interpreted as the one returned by StoreFunc.
+func (e *Engine) Advance(ctx context.Context, sess *models.Session, next models.State) error {
+	_, st, err := e.current(sess)
+	if err != nil {
+		return err
+	}
+
+	if next == 0 {
+		next = st.Next
+	}
+
+	return e.moveTo(ctx, sess, next)
+}
+
+// Back returns to the previous step.
+func (e *Engine) Back(ctx context.Context, sess *models.Session) error {
+	_, st, err := e.current(sess)
+	if err != nil {
+		return err
+	}
+
+	if st.Back == 0 {
+		return ErrNoBack
+	}
+
+	return e.moveTo(ctx, sess, st.Back)
+}
+
+// Skip skips the current optional step.
+func (e *Engine) Skip(ctx context.Context, sess *models.Session) error {
+	_, st, err := e.current(sess)
+	if err != nil {
+		return err
+	}
+
+	if !st.Optional {
+		return ErrNotOptional
+	}
+
+	return e.moveTo(ctx, sess, st.Next)
+}
+
+// Cancel cancels the conversation in progress.
+func (e *Engine) Cancel(ctx context.Context, sess *models.Session) error {
+	c, ok := e.conversations[sess.UserState.Command]
+	if !ok {
+		return ErrNoActiveConversation
+	}
+
+	if c.Cancel != nil {
+		if err := c.Cancel(ctx, sess); err != nil {
+			return fmt.Errorf("failed to cancel conversation %q: %w", c.Command, err)
+		}
+	}
+
+	sess.UserState.State = models.StateStart
+
+	finish(sess)
+
+	return nil
+}
+
+func (e *Engine) current(sess *models.Session) (*Conversation, *Step, error) {
+	c, ok := e.conversations[sess.UserState.Command]
+	if !ok {
+		return nil, nil, ErrNoActiveConversation
+	}
+
+	st, ok := c.step(sess.UserState.State)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w %s in conversation %q", ErrUnknownStep, sess.UserState.State, c.Command)
+	}
+
+	return c, st, nil
+}
+
+// moveTo moves the session to the state and asks the step.
+func (e *Engine) moveTo(ctx context.Context, sess *models.Session, state models.State) error {
+	sess.UserState.State = state
+
+	if state == models.StateStart {
+		finish(sess)
+
+		return nil
+	}
+
+	_, st, err := e.current(sess)
+	if err != nil {
+		return err
+	}
+
+	if err = e.ask(ctx, sess, st, ""); err != nil {
+		return err
+	}
+
+	// Step could finish the conversation by itself while asking, e.g. when there is nothing to ask.
+	if sess.UserState.State == models.StateStart {
+		finish(sess)
+	}
+
+	return nil
+}
+
+func (e *Engine) ask(ctx context.Context, sess *models.Session, st *Step, reason string) error {
+	p, err := st.Prompt(ctx, sess)
+	if err != nil {
+		return fmt.Errorf("failed to prompt %s: %w", st.State, err)
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	if reason != "" {
+		if p.HTML {
+			reason = html.EscapeString(reason)
+		}
+
+		p.Text = fmt.Sprintf("%s. %s", reason, p.Text)
+	}
+
+	p.Keyboard = withControls(p.Keyboard, st)
+
+	return e.send(ctx, sess, *p)
+}
+
+// withControls adds Back and Skip buttons to the reply keyboard of the step prompt.
+// Other keyboards are returned as is.
+func withControls(keyboard tgbotapi.ReplyMarkup, st *Step) tgbotapi.ReplyMarkup {
+	var row []tgbotapi.KeyboardButton
+
+	if st.Back != 0 {
+		row = append(row, tu.KeyboardButton(BackButton))
+	}
+
+	if st.Optional {
+		row = append(row, tu.KeyboardButton(SkipButton))
+	}
+
+	if len(row) == 0 {
+		return keyboard
+	}
+
+	switch k := keyboard.(type) {
+	case nil:
+		return tu.Keyboard(row).WithResizeKeyboard().WithOneTimeKeyboard()
+	case *tgbotapi.ReplyKeyboardMarkup:
+		// Prompt keyboards could be shared between calls, so they are not modified.
+		cp := *k
+		cp.Keyboard = append(slices.Clone(k.Keyboard), row)
+
+		return &cp
+	default:
+		return keyboard
+	}
+}
+
+func finish(sess *models.Session) {
+	sess.UserState.Command = ""
+}
@@ -0,0 +1,399 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+type sentPrompts []string
+
+func (p *sentPrompts) send(_ context.Context, _ *models.Session, prompt Prompt) error {
+	*p = append(*p, prompt.Text)
+
+	return nil
+}
+
+func prompt(text string) PromptFunc {
+	return func(context.Context, *models.Session) (*Prompt, error) {
+		return &Prompt{Text: text}, nil
+	}
+}
+
+func store(values map[models.State]string, state models.State) StoreFunc {
+	return func(_ context.Context, _ *models.Session, input string) (models.State, error) {
+		values[state] = input
+
+		return 0, nil
+	}
+}
+
+func newTestEngine(t *testing.T) (*Engine, *sentPrompts, map[models.State]string) {
+	t.Helper()
+
+	var sent sentPrompts
+
+	values := make(map[models.State]string)
+
+	e, err := NewEngine(sent.send, &Conversation{
+		Command: "newtrip",
+		Steps: []Step{
+			{
+				State:  models.StateNewTripName,
+				Prompt: prompt("name?"),
+				Validate: func(_ context.Context, _ *models.Session, input string) error {
+					if input == "" {
+						return Invalid("Name is empty")
+					}
+
+					return nil
+				},
+				Store: store(values, models.StateNewTripName),
+				Next:  models.StateNewTripDescription,
+			},
+			{
+				State:    models.StateNewTripDescription,
+				Prompt:   prompt("description?"),
+				Store:    store(values, models.StateNewTripDescription),
+				Next:     models.StateNewTripConfirm,
+				Back:     models.StateNewTripName,
+				Optional: true,
+			},
+			{
+				State:  models.StateNewTripConfirm,
+				Prompt: prompt("confirm?"),
+				Store: func(_ context.Context, _ *models.Session, input string) (models.State, error) {
+					if input == "no" {
+						return models.StateStart, nil
+					}
+
+					return 0, nil
+				},
+				Next: models.StateNewTripPublish,
+				Back: models.StateNewTripDescription,
+			},
+			{
+				State:  models.StateNewTripPublish,
+				Prompt: prompt("publish?"),
+				Back:   models.StateNewTripConfirm,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	return e, &sent, values
+}
+
+func newSession() *models.Session {
+	return &models.Session{
+		User:      &models.User{ID: 1},
+		ChatID:    1,
+		UserState: models.UserState{State: models.StateStart},
+	}
+}
+
+func TestEngine_Flow(t *testing.T) {
+	ctx := context.Background()
+
+	e, sent, values := newTestEngine(t)
+	sess := newSession()
+
+	_, ok := e.Active(sess)
+	assert.False(t, ok)
+
+	require.NoError(t, e.Start(ctx, sess, "newtrip"))
+	assert.Equal(t, "newtrip", sess.UserState.Command)
+	assert.Equal(t, models.StateNewTripName, sess.UserState.State)
+
+	c, ok := e.Active(sess)
+	require.True(t, ok)
+	assert.Equal(t, "newtrip", c.Command)
+
+	require.NoError(t, e.Handle(ctx, sess, ""))
+	assert.Equal(t, models.StateNewTripName, sess.UserState.State, "invalid input keeps the step")
+
+	require.NoError(t, e.Handle(ctx, sess, "Morning ride"))
+	assert.Equal(t, models.StateNewTripDescription, sess.UserState.State)
+
+	require.NoError(t, e.Handle(ctx, sess, "Easy pace"))
+	assert.Equal(t, models.StateNewTripConfirm, sess.UserState.State)
+
+	require.NoError(t, e.Handle(ctx, sess, "yes"))
+	assert.Equal(t, models.StateNewTripPublish, sess.UserState.State)
+
+	require.NoError(t, e.Handle(ctx, sess, "text"))
+	assert.Equal(t, models.StateNewTripPublish, sess.UserState.State, "step without store asks again")
+
+	assert.Equal(t, sentPrompts{
+		"name?",
+		"Name is empty. name?",
+		"description?",
+		"confirm?",
+		"publish?",
+		"publish?",
+	}, *sent)
+
+	assert.Equal(t, map[models.State]string{
+		models.StateNewTripName:        "Morning ride",
+		models.StateNewTripDescription: "Easy pace",
+	}, values)
+}
+
+func TestEngine_Finish(t *testing.T) {
+	ctx := context.Background()
+
+	e, _, _ := newTestEngine(t)
+	sess := newSession()
+
+	sess.UserState.Command = "newtrip"
+	sess.UserState.State = models.StateNewTripConfirm
+
+	require.NoError(t, e.Handle(ctx, sess, "no"))
+	assert.Equal(t, models.StateStart, sess.UserState.State)
+	assert.Empty(t, sess.UserState.Command)
+
+	_, ok := e.Active(sess)
+	assert.False(t, ok)
+
+	require.ErrorIs(t, e.Handle(ctx, sess, "text"), ErrNoActiveConversation)
+}
+
+func TestEngine_BackAndSkip(t *testing.T) {
+	ctx := context.Background()
+
+	e, _, values := newTestEngine(t)
+	sess := newSession()
+
+	require.NoError(t, e.Start(ctx, sess, "newtrip"))
+
+	require.ErrorIs(t, e.Back(ctx, sess), ErrNoBack)
+	require.ErrorIs(t, e.Skip(ctx, sess), ErrNotOptional)
+
+	require.NoError(t, e.Handle(ctx, sess, "Morning ride"))
+
+	require.NoError(t, e.Skip(ctx, sess))
+	assert.Equal(t, models.StateNewTripConfirm, sess.UserState.State)
+	assert.NotContains(t, values, models.StateNewTripDescription)
+
+	require.NoError(t, e.Back(ctx, sess))
+	assert.Equal(t, models.StateNewTripDescription, sess.UserState.State)
+
+	require.NoError(t, e.Back(ctx, sess))
+	assert.Equal(t, models.StateNewTripName, sess.UserState.State)
+}
+
+func TestEngine_RejectAndAdvance(t *testing.T) {
+	ctx := context.Background()
+
+	e, sent, _ := newTestEngine(t)
+	sess := newSession()
+
+	require.ErrorIs(t, e.Advance(ctx, sess, 0), ErrNoActiveConversation)
+
+	require.NoError(t, e.Start(ctx, sess, "newtrip"))
+
+	require.NoError(t, e.Reject(ctx, sess, "File is too big"))
+	assert.Equal(t, models.StateNewTripName, sess.UserState.State)
+
+	require.NoError(t, e.Advance(ctx, sess, 0))
+	assert.Equal(t, models.StateNewTripDescription, sess.UserState.State, "zero state moves to the next step")
+
+	require.NoError(t, e.Advance(ctx, sess, models.StateNewTripPublish))
+	assert.Equal(t, models.StateNewTripPublish, sess.UserState.State)
+
+	require.NoError(t, e.Advance(ctx, sess, models.StateStart))
+	assert.Equal(t, models.StateStart, sess.UserState.State)
+	assert.Empty(t, sess.UserState.Command)
+
+	assert.Equal(t, sentPrompts{
+		"name?",
+		"File is too big. name?",
+		"description?",
+		"publish?",
+	}, *sent)
+}
+
+func TestEngine_UnknownConversation(t *testing.T) {
+	e, _, _ := newTestEngine(t)
+
+	require.ErrorIs(t, e.Start(context.Background(), newSession(), "unknown"), ErrUnknownConversation)
+}
+
+func TestNewEngine_Invalid(t *testing.T) {
+	send := func(context.Context, *models.Session, Prompt) error { return nil }
+
+	tests := []struct {
+		name          string
+		conversations []*Conversation
+	}{
+		{
+			name: "no steps",
+			conversations: []*Conversation{
+				{Command: "a"},
+			},
+		},
+		{
+			name: "duplicate command",
+			conversations: []*Conversation{
+				{Command: "a", Steps: []Step{{State: models.StateNewTripName}}},
+				{Command: "a", Steps: []Step{{State: models.StateNewTripDate}}},
+			},
+		},
+		{
+			name: "shared state",
+			conversations: []*Conversation{
+				{Command: "a", Steps: []Step{{State: models.StateNewTripName}}},
+				{Command: "b", Steps: []Step{{State: models.StateNewTripName}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEngine(send, tt.conversations...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEngine_ValidateFailure(t *testing.T) {
+	ctx := context.Background()
+
+	var sent sentPrompts
+
+	errBackend := errors.New("backend is down")
+
+	e, err := NewEngine(sent.send, &Conversation{
+		Command: "newtrip",
+		Steps: []Step{
+			{
+				State:  models.StateNewTripName,
+				Prompt: prompt("name?"),
+				Validate: func(context.Context, *models.Session, string) error {
+					return errBackend
+				},
+				Store: store(map[models.State]string{}, models.StateNewTripName),
+				Next:  models.StateStart,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	sess := newSession()
+
+	require.NoError(t, e.Start(ctx, sess, "newtrip"))
+	require.ErrorIs(t, e.Handle(ctx, sess, "Morning ride"), errBackend)
+	assert.Equal(t, models.StateNewTripName, sess.UserState.State)
+	assert.Equal(t, sentPrompts{"name?"}, sent)
+}
+
+func TestEngine_ControlButtons(t *testing.T) {
+	ctx := context.Background()
+
+	e, sent, values := newTestEngine(t)
+	sess := newSession()
+
+	require.NoError(t, e.Start(ctx, sess, "newtrip"))
+
+	require.NoError(t, e.Handle(ctx, sess, BackButton), "back is a name on the first step")
+	assert.Equal(t, models.StateNewTripDescription, sess.UserState.State)
+	assert.Equal(t, BackButton, values[models.StateNewTripName])
+
+	require.NoError(t, e.Handle(ctx, sess, SkipButton))
+	assert.Equal(t, models.StateNewTripConfirm, sess.UserState.State)
+
+	require.NoError(t, e.Handle(ctx, sess, SkipButton), "confirm step is not optional")
+	assert.Equal(t, models.StateNewTripPublish, sess.UserState.State)
+
+	require.NoError(t, e.Handle(ctx, sess, BackButton), "step without store could go back")
+	assert.Equal(t, models.StateNewTripConfirm, sess.UserState.State)
+
+	assert.Equal(t, sentPrompts{"name?", "description?", "confirm?", "publish?", "confirm?"}, *sent)
+	assert.NotContains(t, values, models.StateNewTripDescription)
+}
+
+func TestEngine_Cancel(t *testing.T) {
+	ctx := context.Background()
+
+	var canceled bool
+
+	e, err := NewEngine(func(context.Context, *models.Session, Prompt) error { return nil }, &Conversation{
+		Command: "newtrip",
+		Cancel: func(context.Context, *models.Session) error {
+			canceled = true
+
+			return nil
+		},
+		Steps: []Step{
+			{State: models.StateNewTripName, Prompt: prompt("name?")},
+		},
+	})
+	require.NoError(t, err)
+
+	sess := newSession()
+
+	require.ErrorIs(t, e.Cancel(ctx, sess), ErrNoActiveConversation)
+
+	require.NoError(t, e.Start(ctx, sess, "newtrip"))
+	require.NoError(t, e.Cancel(ctx, sess))
+
+	assert.True(t, canceled)
+	assert.Equal(t, models.StateStart, sess.UserState.State)
+	assert.Empty(t, sess.UserState.Command)
+}
+
+func TestWithControls(t *testing.T) {
+	shared := tu.Keyboard(tu.KeyboardRow(tu.KeyboardButton("yes")))
+
+	tests := []struct {
+		name     string
+		keyboard tgbotapi.ReplyMarkup
+		step     Step
+		want     tgbotapi.ReplyMarkup
+	}{
+		{
+			name:     "no controls",
+			keyboard: shared,
+			step:     Step{},
+			want:     shared,
+		},
+		{
+			name:     "no keyboard",
+			keyboard: nil,
+			step:     Step{Back: models.StateNewTripName, Optional: true},
+			want: tu.Keyboard(
+				tu.KeyboardRow(tu.KeyboardButton(BackButton), tu.KeyboardButton(SkipButton)),
+			).WithResizeKeyboard().WithOneTimeKeyboard(),
+		},
+		{
+			name:     "reply keyboard",
+			keyboard: shared,
+			step:     Step{Back: models.StateNewTripName},
+			want: tu.Keyboard(
+				tu.KeyboardRow(tu.KeyboardButton("yes")),
+				tu.KeyboardRow(tu.KeyboardButton(BackButton)),
+			),
+		},
+		{
+			name:     "inline keyboard",
+			keyboard: tu.InlineKeyboard(),
+			step:     Step{Back: models.StateNewTripName},
+			want:     tu.InlineKeyboard(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, withControls(tt.keyboard, &tt.step))
+		})
+	}
+
+	assert.Len(t, shared.Keyboard, 1, "shared keyboard must not be modified")
+}
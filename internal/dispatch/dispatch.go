@@ -0,0 +1,342 @@
+// Package dispatch orders update processing.
+//
+// Updates with the same key, e.g. from the same user, are handled one by one in the order they were received,
+// while updates with different keys are handled in parallel by a bounded number of workers.
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	log "github.com/obalunenko/logger"
+)
+
+// KeyFunc returns a key updates are ordered by, false means the update could be handled in any order.
+type KeyFunc func(update tgbotapi.Update) (int64, bool)
+
+// Params contains the parameters for New.
+type Params struct {
+	// Workers is a maximum number of updates handled at the same time.
+	Workers int
+	// QueueSize is a maximum number of updates waiting or being handled.
+	// Receiving of new updates is paused when the queue is full.
+	QueueSize int
+	// Key returns a key updates are ordered by.
+	Key KeyFunc
+}
+
+// Stats contains dispatcher metrics.
+type Stats struct {
+	// Queued is a number of updates waiting for their turn or a free worker.
+	Queued int
+	// Running is a number of updates being handled.
+	Running int
+	// Processed is a total number of handled updates.
+	Processed uint64
+	// Dropped is a total number of updates dropped on shutdown without being handled.
+	Dropped uint64
+	// Throttled is a total number of times receiving of updates was paused because the queue was full.
+	Throttled uint64
+	// MaxWait is the longest time an update waited before being handled.
+	MaxWait time.Duration
+}
+
+// Dispatcher orders update processing.
+type Dispatcher struct {
+	key     KeyFunc
+	workers chan struct{}
+	pending chan struct{}
+
+	mu   sync.Mutex
+	last map[int64]chan struct{}
+	// tickets are turns of updates not finished yet.
+	tickets map[*ticket]struct{}
+
+	// drainCtx is done when updates waiting for their turn must be dropped, see Abort.
+	drainCtx context.Context
+	abort    context.CancelFunc
+
+	running   atomic.Int64
+	processed atomic.Uint64
+	dropped   atomic.Uint64
+	throttled atomic.Uint64
+	maxWait   atomic.Int64
+}
+
+// New creates a new dispatcher.
+func New(p Params) (*Dispatcher, error) {
+	if p.Workers <= 0 {
+		return nil, errors.New("workers must be positive")
+	}
+
+	if p.QueueSize < p.Workers {
+		return nil, errors.New("queue size must not be less than workers")
+	}
+
+	if p.Key == nil {
+		return nil, errors.New("key func is nil")
+	}
+
+	drainCtx, abort := context.WithCancel(context.Background())
+
+	return &Dispatcher{
+		key:      p.Key,
+		workers:  make(chan struct{}, p.Workers),
+		pending:  make(chan struct{}, p.QueueSize),
+		last:     make(map[int64]chan struct{}),
+		tickets:  make(map[*ticket]struct{}),
+		drainCtx: drainCtx,
+		abort:    abort,
+	}, nil
+}
+
+type ticketKey struct{}
+
+// ticket is the update turn, the update is handled after the previous update with the same key is done.
+type ticket struct {
+	key      int64
+	keyed    bool
+	prev     <-chan struct{}
+	done     chan struct{}
+	queuedAt time.Time
+	// entered is set once the update reaches Middleware, guarded by Dispatcher.mu.
+	entered bool
+}
+
+// Sequence assigns turns to updates from in and forwards them to the returned channel, which is consumed
+// by the bot handler. The returned channel is closed when in is closed or ctx is done.
+func (d *Dispatcher) Sequence(ctx context.Context, in <-chan tgbotapi.Update) <-chan tgbotapi.Update {
+	out := make(chan tgbotapi.Update)
+
+	go func() {
+		defer close(out)
+
+		for {
+			var (
+				update tgbotapi.Update
+				ok     bool
+			)
+
+			select {
+			case <-ctx.Done():
+				return
+			case update, ok = <-in:
+				if !ok {
+					return
+				}
+			}
+
+			if !d.reserve(ctx) {
+				return
+			}
+
+			t := d.newTicket(update)
+
+			select {
+			case <-ctx.Done():
+				d.finish(t, false)
+
+				return
+			case out <- update.WithContext(context.WithValue(update.Context(), ticketKey{}, t)):
+			}
+		}
+	}()
+
+	return out
+}
+
+// reserve takes a place in the queue, blocking while the queue is full.
+func (d *Dispatcher) reserve(ctx context.Context) bool {
+	select {
+	case d.pending <- struct{}{}:
+		return true
+	default:
+	}
+
+	d.throttled.Add(1)
+
+	log.WithField(ctx, "queue_size", cap(d.pending)).Warn("Updates queue is full, receiving is paused")
+
+	select {
+	case d.pending <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (d *Dispatcher) newTicket(update tgbotapi.Update) *ticket {
+	t := &ticket{
+		done:     make(chan struct{}),
+		queuedAt: time.Now(),
+	}
+
+	key, ok := d.key(update)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.tickets[t] = struct{}{}
+
+	if !ok {
+		return t
+	}
+
+	t.key = key
+	t.keyed = true
+	t.prev = d.last[key]
+
+	d.last[key] = t.done
+
+	return t
+}
+
+// finish passes the turn to the next update with the same key and frees the place in the queue.
+// Handled tells whether the update reached the handlers. Finishing the ticket again does nothing.
+func (d *Dispatcher) finish(t *ticket, handled bool) {
+	d.mu.Lock()
+
+	if _, ok := d.tickets[t]; !ok {
+		d.mu.Unlock()
+
+		return
+	}
+
+	delete(d.tickets, t)
+	close(t.done)
+
+	if t.keyed && d.last[t.key] == t.done {
+		delete(d.last, t.key)
+	}
+
+	d.mu.Unlock()
+
+	<-d.pending
+
+	if handled {
+		d.processed.Add(1)
+
+		return
+	}
+
+	d.dropped.Add(1)
+}
+
+// Middleware waits for the update turn and a free worker before passing the update further.
+// It must be the first middleware, so no handler runs out of turn.
+// Neither waiting nor handling is interrupted by the update context, which the bot handler cancels on stop,
+// so updates already queued are drained on shutdown until Abort is called.
+func (d *Dispatcher) Middleware() th.Middleware {
+	return func(bot *tgbotapi.Bot, update tgbotapi.Update, next th.Handler) {
+		t, ok := update.Context().Value(ticketKey{}).(*ticket)
+		if !ok {
+			// Update didn't pass Sequence, so it's handled right away.
+			next(bot, update)
+
+			return
+		}
+
+		d.enter(t)
+
+		var handled bool
+
+		defer func() {
+			d.finish(t, handled)
+		}()
+
+		if t.prev != nil {
+			select {
+			case <-t.prev:
+			case <-d.drainCtx.Done():
+				return
+			}
+		}
+
+		select {
+		case d.workers <- struct{}{}:
+		case <-d.drainCtx.Done():
+			return
+		}
+
+		defer func() {
+			<-d.workers
+		}()
+
+		// Turn and abort may come at the same time, aborted updates are never handled.
+		if d.drainCtx.Err() != nil {
+			return
+		}
+
+		d.observeWait(time.Since(t.queuedAt))
+
+		d.running.Add(1)
+		defer d.running.Add(-1)
+
+		handled = true
+
+		// Bot handler skips the rest of the chain once the update context is canceled.
+		next(bot, update.WithContext(context.WithoutCancel(update.Context())))
+	}
+}
+
+// Abort drops updates waiting for their turn or a free worker, updates being handled are not interrupted.
+// It is called when the shutdown drain deadline expires.
+func (d *Dispatcher) Abort() {
+	d.abort()
+}
+
+func (d *Dispatcher) enter(t *ticket) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t.entered = true
+}
+
+// Release drops updates that never reached Middleware. It is called after the bot handler is stopped:
+// the bot handler skips updates it starts after stop, and their turns would block later updates
+// with the same key and their places in the queue forever.
+func (d *Dispatcher) Release() {
+	d.mu.Lock()
+
+	tickets := make([]*ticket, 0, len(d.tickets))
+
+	for t := range d.tickets {
+		if !t.entered {
+			tickets = append(tickets, t)
+		}
+	}
+
+	d.mu.Unlock()
+
+	for _, t := range tickets {
+		d.finish(t, false)
+	}
+}
+
+func (d *Dispatcher) observeWait(wait time.Duration) {
+	for {
+		current := d.maxWait.Load()
+		if int64(wait) <= current || d.maxWait.CompareAndSwap(current, int64(wait)) {
+			return
+		}
+	}
+}
+
+// Stats returns current dispatcher metrics.
+func (d *Dispatcher) Stats() Stats {
+	running := int(d.running.Load())
+
+	return Stats{
+		Queued:    max(len(d.pending)-running, 0),
+		Running:   running,
+		Processed: d.processed.Load(),
+		Dropped:   d.dropped.Load(),
+		Throttled: d.throttled.Load(),
+		MaxWait:   time.Duration(d.maxWait.Load()),
+	}
+}
@@ -0,0 +1,399 @@
+package dispatch
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func userKey(update tgbotapi.Update) (int64, bool) {
+	if update.Message == nil || update.Message.From == nil {
+		return 0, false
+	}
+
+	return update.Message.From.ID, true
+}
+
+func message(updateID int, userID int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		Message:  &tgbotapi.Message{From: &tgbotapi.User{ID: userID}},
+	}
+}
+
+// run handles updates like telego bot handler does: every update in its own goroutine.
+func run(ctx context.Context, d *Dispatcher, updates <-chan tgbotapi.Update, handler th.Handler) *sync.WaitGroup {
+	var wg sync.WaitGroup
+
+	mw := d.Middleware()
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for update := range d.Sequence(ctx, updates) {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				mw(nil, update, handler)
+			}()
+		}
+	}()
+
+	return &wg
+}
+
+func TestNew_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		params Params
+	}{
+		{name: "no workers", params: Params{Workers: 0, QueueSize: 1, Key: userKey}},
+		{name: "small queue", params: Params{Workers: 2, QueueSize: 1, Key: userKey}},
+		{name: "no key", params: Params{Workers: 1, QueueSize: 1}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.params)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDispatcher_OrderPerKey(t *testing.T) {
+	const (
+		users          = 5
+		updatesPerUser = 20
+	)
+
+	d, err := New(Params{Workers: 3, QueueSize: 10, Key: userKey})
+	require.NoError(t, err)
+
+	updates := make(chan tgbotapi.Update)
+
+	var (
+		mu      sync.Mutex
+		handled = make(map[int64][]int)
+		running atomic.Int64
+		maxRun  atomic.Int64
+		perUser = make(map[int64]*atomic.Int64)
+	)
+
+	for u := range users {
+		perUser[int64(u)] = &atomic.Int64{}
+	}
+
+	wg := run(context.Background(), d, updates, func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		userID := update.Message.From.ID
+
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		if cur := maxRun.Load(); n > cur {
+			maxRun.CompareAndSwap(cur, n)
+		}
+
+		assert.Equal(t, int64(1), perUser[userID].Add(1), "updates of the same user must not run concurrently")
+		defer perUser[userID].Add(-1)
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		handled[userID] = append(handled[userID], update.UpdateID)
+		mu.Unlock()
+	})
+
+	for i := range updatesPerUser {
+		for u := range users {
+			updates <- message(i, int64(u))
+		}
+	}
+
+	close(updates)
+	wg.Wait()
+
+	want := make([]int, updatesPerUser)
+	for i := range want {
+		want[i] = i
+	}
+
+	for u := range users {
+		assert.Equal(t, want, handled[int64(u)], "user %d", u)
+	}
+
+	assert.LessOrEqual(t, maxRun.Load(), int64(3), "workers limit")
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(users*updatesPerUser), stats.Processed)
+	assert.Zero(t, stats.Queued)
+	assert.Zero(t, stats.Running)
+}
+
+func TestDispatcher_Backpressure(t *testing.T) {
+	d, err := New(Params{Workers: 1, QueueSize: 2, Key: userKey})
+	require.NoError(t, err)
+
+	updates := make(chan tgbotapi.Update, 3)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+
+	wg := run(context.Background(), d, updates, func(*tgbotapi.Bot, tgbotapi.Update) {
+		started <- struct{}{}
+		<-release
+	})
+
+	for i := range 3 {
+		updates <- message(i, 1)
+	}
+
+	<-started
+
+	require.Eventually(t, func() bool {
+		return d.Stats().Throttled == 1
+	}, time.Second, time.Millisecond, "third update must wait for a place in the queue")
+
+	stats := d.Stats()
+	assert.Equal(t, 1, stats.Running)
+	assert.Equal(t, 1, stats.Queued)
+
+	close(release)
+	close(updates)
+	wg.Wait()
+
+	assert.Equal(t, uint64(3), d.Stats().Processed)
+}
+
+func TestDispatcher_Unkeyed(t *testing.T) {
+	d, err := New(Params{Workers: 2, QueueSize: 2, Key: userKey})
+	require.NoError(t, err)
+
+	updates := make(chan tgbotapi.Update, 2)
+
+	var handled atomic.Int64
+
+	wg := run(context.Background(), d, updates, func(*tgbotapi.Bot, tgbotapi.Update) {
+		handled.Add(1)
+	})
+
+	updates <- tgbotapi.Update{UpdateID: 1}
+	updates <- tgbotapi.Update{UpdateID: 2}
+
+	close(updates)
+	wg.Wait()
+
+	assert.Equal(t, int64(2), handled.Load())
+}
+
+// entered returns a number of updates that reached the middleware and are not finished yet.
+func entered(d *Dispatcher) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var n int
+
+	for t := range d.tickets {
+		if t.entered {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestDispatcher_DrainOnShutdown(t *testing.T) {
+	const queued = 5
+
+	d, err := New(Params{Workers: 1, QueueSize: queued, Key: userKey})
+	require.NoError(t, err)
+
+	bot, err := tgbotapi.NewBot("1:"+strings.Repeat("a", 35), tgbotapi.WithDiscardLogger())
+	require.NoError(t, err)
+
+	updates := make(chan tgbotapi.Update, queued)
+
+	seqCtx, stopSequence := context.WithCancel(context.Background())
+	defer stopSequence()
+
+	handler, err := th.NewBotHandler(bot, d.Sequence(seqCtx, updates))
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, queued)
+
+	var handled atomic.Int64
+
+	// Contexts the bot handler gave to the updates, they are canceled on stop.
+	contexts := make(chan context.Context, queued)
+
+	handler.Use(func(bot *tgbotapi.Bot, update tgbotapi.Update, next th.Handler) {
+		contexts <- update.Context()
+		next(bot, update)
+	})
+	handler.Use(d.Middleware())
+	handler.Handle(func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		started <- struct{}{}
+		<-release
+
+		if update.Context().Err() == nil {
+			handled.Add(1)
+		}
+	})
+
+	go handler.Start()
+
+	for i := range queued {
+		updates <- message(i, 1)
+	}
+
+	<-started
+
+	require.Eventually(t, func() bool {
+		return entered(d) == queued
+	}, time.Second, time.Millisecond)
+
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		handler.Stop()
+	}()
+
+	// Stop cancels contexts of the updates, queued updates must still reach the handler.
+	first := <-contexts
+
+	require.Eventually(t, func() bool {
+		return first.Err() != nil
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	<-stopped
+
+	stopSequence()
+	d.Release()
+
+	assert.Equal(t, int64(queued), handled.Load(), "queued updates must be handled after the stop signal")
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(queued), stats.Processed)
+	assert.Zero(t, stats.Dropped)
+	assert.Zero(t, stats.Queued)
+}
+
+func TestDispatcher_Release(t *testing.T) {
+	d, err := New(Params{Workers: 1, QueueSize: 2, Key: userKey})
+	require.NoError(t, err)
+
+	updates := make(chan tgbotapi.Update, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mw := d.Middleware()
+	out := d.Sequence(ctx, updates)
+
+	updates <- message(1, 1)
+	updates <- message(2, 1)
+
+	// Bot handler skips the first update, so it never reaches the middleware.
+	<-out
+	second := <-out
+
+	done := make(chan struct{})
+
+	var handled atomic.Int64
+
+	go func() {
+		defer close(done)
+
+		mw(nil, second, func(*tgbotapi.Bot, tgbotapi.Update) {
+			handled.Add(1)
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		return entered(d) == 1
+	}, time.Second, time.Millisecond)
+
+	d.Release()
+	<-done
+
+	assert.Equal(t, int64(1), handled.Load(), "update must not wait for the turn of the skipped one")
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(1), stats.Processed)
+	assert.Equal(t, uint64(1), stats.Dropped)
+	assert.Zero(t, stats.Queued)
+}
+
+func TestDispatcher_Abort(t *testing.T) {
+	d, err := New(Params{Workers: 1, QueueSize: 2, Key: userKey})
+	require.NoError(t, err)
+
+	updates := make(chan tgbotapi.Update, 2)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	var handled atomic.Int64
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mw := d.Middleware()
+	out := d.Sequence(ctx, updates)
+
+	updates <- message(1, 1)
+	updates <- message(2, 1)
+
+	first := <-out
+	second := <-out
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		mw(nil, first, func(*tgbotapi.Bot, tgbotapi.Update) {
+			started <- struct{}{}
+			<-release
+			handled.Add(1)
+		})
+	}()
+
+	<-started
+
+	go func() {
+		defer wg.Done()
+
+		mw(nil, second, func(*tgbotapi.Bot, tgbotapi.Update) {
+			handled.Add(1)
+		})
+	}()
+
+	// Waiting update is dropped when the drain deadline expires, the running one is finished.
+	d.Abort()
+	close(release)
+	wg.Wait()
+	cancel()
+
+	assert.Equal(t, int64(1), handled.Load())
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(1), stats.Processed)
+	assert.Equal(t, uint64(1), stats.Dropped)
+}
@@ -0,0 +1,279 @@
+// Package gpx parses GPX files offline and computes route statistics.
+//
+// Track points are used when the file has any, route points otherwise, since a file with both
+// usually describes the same ride as planned and as recorded. Distance is summed within track
+// segments and routes only, so gaps between segments, e.g. a pause in recording, are not counted.
+package gpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	// ErrInvalid is returned when the input is not a valid GPX file.
+	ErrInvalid = errors.New("invalid GPX file")
+	// ErrNoPoints is returned when the file has less than two track or route points.
+	ErrNoPoints = errors.New("no track or route points")
+)
+
+const (
+	// earthRadius is the mean Earth radius in meters.
+	earthRadius = 6371008.8
+	// elevationThreshold is the minimal elevation change in meters counted into gain and loss,
+	// smaller changes are treated as GPS noise.
+	elevationThreshold = 3.0
+	// gradientDistance is the minimal distance in meters the gradient is measured over,
+	// shorter stretches make noisy spikes.
+	gradientDistance = 100.0
+)
+
+// Bounds is a bounding box of the route.
+type Bounds struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Stats is a summary of the route.
+type Stats struct {
+	// Distance is the total distance in meters.
+	Distance float64
+	// HasElevation is true when points have elevation, elevation stats are zero otherwise.
+	HasElevation bool
+	// ElevationGain is the total ascent in meters.
+	ElevationGain float64
+	// ElevationLoss is the total descent in meters.
+	ElevationLoss float64
+	// MaxGradient is the steepest climb in percent.
+	MaxGradient float64
+	// Bounds is the bounding box of all points.
+	Bounds Bounds
+}
+
+type document struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []point `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []point `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+type point struct {
+	Latitude  float64  `xml:"lat,attr"`
+	Longitude float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele"`
+}
+
+// Parse reads the GPX file and computes its stats.
+func Parse(r io.Reader) (*Stats, error) {
+	var doc document
+
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	var tracks, routes [][]point
+
+	for _, t := range doc.Tracks {
+		for _, s := range t.Segments {
+			tracks = append(tracks, s.Points)
+		}
+	}
+
+	for _, r := range doc.Routes {
+		routes = append(routes, r.Points)
+	}
+
+	lines := tracks
+	if countPoints(lines) == 0 {
+		lines = routes
+	}
+
+	if countPoints(lines) < 2 {
+		return nil, ErrNoPoints
+	}
+
+	for _, line := range lines {
+		for _, p := range line {
+			if err := p.validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return compute(lines), nil
+}
+
+// validate checks the point coordinates and elevation are finite numbers within range.
+func (p point) validate() error {
+	if !finite(p.Latitude) || !finite(p.Longitude) ||
+		p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+		return fmt.Errorf("%w: coordinates %f, %f are out of range", ErrInvalid, p.Latitude, p.Longitude)
+	}
+
+	if p.Elevation != nil && !finite(*p.Elevation) {
+		return fmt.Errorf("%w: elevation %f is not a number", ErrInvalid, *p.Elevation)
+	}
+
+	return nil
+}
+
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func countPoints(lines [][]point) int {
+	var n int
+
+	for _, line := range lines {
+		n += len(line)
+	}
+
+	return n
+}
+
+func compute(lines [][]point) *Stats {
+	var first point
+
+	for _, line := range lines {
+		if len(line) != 0 {
+			first = line[0]
+
+			break
+		}
+	}
+
+	stats := &Stats{
+		Bounds: Bounds{
+			MinLatitude:  first.Latitude,
+			MinLongitude: first.Longitude,
+			MaxLatitude:  first.Latitude,
+			MaxLongitude: first.Longitude,
+		},
+	}
+
+	for _, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		// Distance from the line start to each point.
+		dist := make([]float64, len(line))
+
+		for i, p := range line {
+			stats.Bounds.extend(p)
+
+			if i > 0 {
+				dist[i] = dist[i-1] + distance(line[i-1], p)
+			}
+		}
+
+		stats.Distance += dist[len(line)-1]
+
+		gain, loss, ok := elevationChange(line)
+		if !ok {
+			continue
+		}
+
+		stats.HasElevation = true
+		stats.ElevationGain += gain
+		stats.ElevationLoss += loss
+		stats.MaxGradient = math.Max(stats.MaxGradient, maxGradient(line, dist))
+	}
+
+	return stats
+}
+
+func (b *Bounds) extend(p point) {
+	b.MinLatitude = math.Min(b.MinLatitude, p.Latitude)
+	b.MinLongitude = math.Min(b.MinLongitude, p.Longitude)
+	b.MaxLatitude = math.Max(b.MaxLatitude, p.Latitude)
+	b.MaxLongitude = math.Max(b.MaxLongitude, p.Longitude)
+}
+
+// distance returns the great-circle distance between points in meters.
+func distance(a, b point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dlat := lat2 - lat1
+	dlon := radians(b.Longitude - a.Longitude)
+
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dlon/2), 2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// elevationChange returns the total ascent and descent of the line, points without elevation are skipped.
+// Changes are counted once they exceed elevationThreshold, so GPS noise doesn't add up.
+func elevationChange(line []point) (gain, loss float64, ok bool) {
+	var ref float64
+
+	for _, p := range line {
+		if p.Elevation == nil {
+			continue
+		}
+
+		ele := *p.Elevation
+
+		if !ok {
+			ref, ok = ele, true
+
+			continue
+		}
+
+		switch d := ele - ref; {
+		case d >= elevationThreshold:
+			gain += d
+			ref = ele
+		case d <= -elevationThreshold:
+			loss -= d
+			ref = ele
+		}
+	}
+
+	return gain, loss, ok
+}
+
+// maxGradient returns the steepest climb of the line in percent, measured over at least gradientDistance.
+// dist is the distance from the line start to each point.
+func maxGradient(line []point, dist []float64) float64 {
+	var (
+		result float64
+		j      int
+	)
+
+	for i := range line {
+		if line[i].Elevation == nil {
+			continue
+		}
+
+		if j <= i {
+			j = i + 1
+		}
+
+		for j < len(line) && (line[j].Elevation == nil || dist[j]-dist[i] < gradientDistance) {
+			j++
+		}
+
+		if j == len(line) {
+			break
+		}
+
+		grade := (*line[j].Elevation - *line[i].Elevation) / (dist[j] - dist[i]) * 100
+
+		result = math.Max(result, grade)
+	}
+
+	return result
+}
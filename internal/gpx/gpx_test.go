@@ -0,0 +1,228 @@
+package gpx_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/gpx"
+)
+
+// 0.001 degree of latitude is about 111.19 meters.
+const latStep = 111.195
+
+func TestParse(t *testing.T) {
+	type expected struct {
+		stats *gpx.Stats
+		err   error
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected expected
+	}{
+		{
+			name: "track with elevation",
+			input: `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk>
+    <name>Morning ride</name>
+    <trkseg>
+      <trkpt lat="50.000" lon="30.000"><ele>100</ele></trkpt>
+      <trkpt lat="50.001" lon="30.000"><ele>110</ele></trkpt>
+      <trkpt lat="50.002" lon="30.000"><ele>105</ele></trkpt>
+      <trkpt lat="50.003" lon="30.000"><ele>125</ele></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`,
+			expected: expected{
+				stats: &gpx.Stats{
+					Distance:      3 * latStep,
+					HasElevation:  true,
+					ElevationGain: 30,
+					ElevationLoss: 5,
+					MaxGradient:   20 / latStep * 100,
+					Bounds: gpx.Bounds{
+						MinLatitude:  50,
+						MinLongitude: 30,
+						MaxLatitude:  50.003,
+						MaxLongitude: 30,
+					},
+				},
+			},
+		},
+		{
+			name: "elevation noise is ignored",
+			input: `<gpx><trk><trkseg>
+      <trkpt lat="50.000" lon="30.000"><ele>100</ele></trkpt>
+      <trkpt lat="50.001" lon="30.000"><ele>101.5</ele></trkpt>
+      <trkpt lat="50.002" lon="30.000"><ele>100</ele></trkpt>
+      <trkpt lat="50.003" lon="30.000"><ele>102</ele></trkpt>
+</trkseg></trk></gpx>`,
+			expected: expected{
+				stats: &gpx.Stats{
+					Distance:     3 * latStep,
+					HasElevation: true,
+					MaxGradient:  2 / latStep * 100,
+					Bounds: gpx.Bounds{
+						MinLatitude:  50,
+						MinLongitude: 30,
+						MaxLatitude:  50.003,
+						MaxLongitude: 30,
+					},
+				},
+			},
+		},
+		{
+			name: "gap between segments is not counted",
+			input: `<gpx><trk>
+  <trkseg>
+    <trkpt lat="50.000" lon="30.000"/>
+    <trkpt lat="50.001" lon="30.000"/>
+  </trkseg>
+  <trkseg>
+    <trkpt lat="50.010" lon="30.010"/>
+    <trkpt lat="50.011" lon="30.010"/>
+  </trkseg>
+</trk></gpx>`,
+			expected: expected{
+				stats: &gpx.Stats{
+					Distance: 2 * latStep,
+					Bounds: gpx.Bounds{
+						MinLatitude:  50,
+						MinLongitude: 30,
+						MaxLatitude:  50.011,
+						MaxLongitude: 30.01,
+					},
+				},
+			},
+		},
+		{
+			name: "route is used when there is no track",
+			input: `<gpx><rte>
+  <rtept lat="-33.001" lon="151.000"><ele>10</ele></rtept>
+  <rtept lat="-33.000" lon="151.000"><ele>0</ele></rtept>
+</rte></gpx>`,
+			expected: expected{
+				stats: &gpx.Stats{
+					Distance:      latStep,
+					HasElevation:  true,
+					ElevationLoss: 10,
+					Bounds: gpx.Bounds{
+						MinLatitude:  -33.001,
+						MinLongitude: 151,
+						MaxLatitude:  -33,
+						MaxLongitude: 151,
+					},
+				},
+			},
+		},
+		{
+			name: "track is preferred over route",
+			input: `<gpx>
+  <rte>
+    <rtept lat="10.000" lon="10.000"/>
+    <rtept lat="10.100" lon="10.000"/>
+  </rte>
+  <trk><trkseg>
+    <trkpt lat="50.000" lon="30.000"/>
+    <trkpt lat="50.001" lon="30.000"/>
+  </trkseg></trk>
+</gpx>`,
+			expected: expected{
+				stats: &gpx.Stats{
+					Distance: latStep,
+					Bounds: gpx.Bounds{
+						MinLatitude:  50,
+						MinLongitude: 30,
+						MaxLatitude:  50.001,
+						MaxLongitude: 30,
+					},
+				},
+			},
+		},
+		{
+			name:  "not xml",
+			input: "just some text",
+			expected: expected{
+				err: gpx.ErrInvalid,
+			},
+		},
+		{
+			name:  "coordinates out of range",
+			input: `<gpx><trk><trkseg><trkpt lat="95" lon="30"/><trkpt lat="50" lon="30"/></trkseg></trk></gpx>`,
+			expected: expected{
+				err: gpx.ErrInvalid,
+			},
+		},
+		{
+			name:  "latitude is NaN",
+			input: `<gpx><trk><trkseg><trkpt lat="NaN" lon="30"/><trkpt lat="50" lon="30"/></trkseg></trk></gpx>`,
+			expected: expected{
+				err: gpx.ErrInvalid,
+			},
+		},
+		{
+			name:  "longitude is infinite",
+			input: `<gpx><trk><trkseg><trkpt lat="50" lon="+Inf"/><trkpt lat="50" lon="30"/></trkseg></trk></gpx>`,
+			expected: expected{
+				err: gpx.ErrInvalid,
+			},
+		},
+		{
+			name: "elevation is NaN",
+			input: `<gpx><trk><trkseg><trkpt lat="50" lon="30"><ele>NaN</ele></trkpt>` +
+				`<trkpt lat="50.01" lon="30"><ele>100</ele></trkpt></trkseg></trk></gpx>`,
+			expected: expected{
+				err: gpx.ErrInvalid,
+			},
+		},
+		{
+			name: "elevation is infinite",
+			input: `<gpx><trk><trkseg><trkpt lat="50" lon="30"><ele>100</ele></trkpt>` +
+				`<trkpt lat="50.01" lon="30"><ele>-Inf</ele></trkpt></trkseg></trk></gpx>`,
+			expected: expected{
+				err: gpx.ErrInvalid,
+			},
+		},
+		{
+			name:  "single point",
+			input: `<gpx><trk><trkseg><trkpt lat="50" lon="30"/></trkseg></trk></gpx>`,
+			expected: expected{
+				err: gpx.ErrNoPoints,
+			},
+		},
+		{
+			name:  "no points",
+			input: `<gpx><wpt lat="50" lon="30"/></gpx>`,
+			expected: expected{
+				err: gpx.ErrNoPoints,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gpx.Parse(strings.NewReader(tt.input))
+			if tt.expected.err != nil {
+				require.ErrorIs(t, err, tt.expected.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			want := tt.expected.stats
+
+			assert.InDelta(t, want.Distance, got.Distance, 0.5)
+			assert.Equal(t, want.HasElevation, got.HasElevation)
+			assert.InDelta(t, want.ElevationGain, got.ElevationGain, 1e-9)
+			assert.InDelta(t, want.ElevationLoss, got.ElevationLoss, 1e-9)
+			assert.InDelta(t, want.MaxGradient, got.MaxGradient, 0.01)
+			assert.Equal(t, want.Bounds, got.Bounds)
+		})
+	}
+}
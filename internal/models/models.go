@@ -3,6 +3,7 @@
 
 import (
 	"encoding/json"
+	"slices"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -18,14 +19,154 @@
 )
 
 // Trip represents a trip.
+// StartsAt is in the trip's timezone and is zero until the date is set.
+// Participants are ordered by join time.
+// ChatID is the chat the trip is announced in and MessageID is the announcement there,
+// both are zero until the trip is published.
 type Trip struct {
-	ID          TripID    `json:"ID,omitempty"`
-	Name        string    `json:"name,omitempty"`
-	Date        string    `json:"date,omitempty"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at,omitempty"`
-	UpdatedAt   time.Time `json:"updated_at,omitempty"`
-	CreatedBy   *User     `json:"created_by,omitempty"`
+	ID          TripID     `json:"ID,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	StartsAt    time.Time  `json:"starts_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+	CreatedBy   *User      `json:"created_by,omitempty"`
+	ChatID      ChatID     `json:"chat_id,omitempty"`
+	MessageID   int        `json:"message_id,omitempty"`
+	Status      TripStatus `json:"status,omitempty"`
+	// CancelReason is the reason the trip was cancelled for, empty if it's not cancelled.
+	CancelReason string `json:"cancel_reason,omitempty"`
+	// MeetingPoint is nil until it's set. MeetingMessageID is its location message posted
+	// in reply to the announcement, zero if there is none.
+	MeetingPoint     *MeetingPoint `json:"meeting_point,omitempty"`
+	MeetingMessageID int           `json:"meeting_message_id,omitempty"`
+	// Track is nil until the route GPX file is uploaded.
+	Track *Track `json:"track,omitempty"`
+	// RouteLink is nil until the route link is set.
+	RouteLink *RouteLink `json:"route_link,omitempty"`
+	// Times the trip reached the statuses, zero if it didn't.
+	PublishedAt  time.Time      `json:"published_at,omitempty"`
+	CancelledAt  time.Time      `json:"cancelled_at,omitempty"`
+	StartedAt    time.Time      `json:"started_at,omitempty"`
+	FinishedAt   time.Time      `json:"finished_at,omitempty"`
+	ArchivedAt   time.Time      `json:"archived_at,omitempty"`
+	Participants []*Participant `json:"participants,omitempty"`
+}
+
+// MeetingPoint is a place riders gather at before the trip.
+type MeetingPoint struct {
+	// Label is a venue name or an address, empty when only coordinates are known.
+	Label string `json:"label,omitempty"`
+	// HasLocation is false when only the label is known, e.g. a typed address.
+	HasLocation bool    `json:"has_location,omitempty"`
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+}
+
+// Track is an uploaded GPX file of the trip route with stats computed from it.
+// Distances and elevations are in meters.
+type Track struct {
+	// FileID is the Telegram file ID of the uploaded file.
+	FileID   string  `json:"file_id,omitempty"`
+	FileName string  `json:"file_name,omitempty"`
+	Distance float64 `json:"distance,omitempty"`
+	// HasElevation is false when the file has no elevation data, elevation stats are zero then.
+	HasElevation  bool    `json:"has_elevation,omitempty"`
+	ElevationGain float64 `json:"elevation_gain,omitempty"`
+	ElevationLoss float64 `json:"elevation_loss,omitempty"`
+	// MaxGradient is the steepest climb in percent.
+	MaxGradient float64 `json:"max_gradient,omitempty"`
+	// Bounding box of the route.
+	MinLatitude  float64 `json:"min_latitude,omitempty"`
+	MinLongitude float64 `json:"min_longitude,omitempty"`
+	MaxLatitude  float64 `json:"max_latitude,omitempty"`
+	MaxLongitude float64 `json:"max_longitude,omitempty"`
+}
+
+// RouteLink is a link to the trip route on a route planner, such as Strava or Komoot.
+type RouteLink struct {
+	// Provider is the route planner name, it's shown to users as the link label.
+	Provider string `json:"provider,omitempty"`
+	// ID is the route ID at the provider.
+	ID  string `json:"id,omitempty"`
+	URL string `json:"url,omitempty"`
+}
+
+// TripStatus is a stage of the trip lifecycle.
+type TripStatus string
+
+const (
+	// TripDraft is a trip being created, it's visible only to its creator.
+	TripDraft TripStatus = "draft"
+	// TripPublished is an announced trip users may join.
+	TripPublished TripStatus = "published"
+	// TripCancelled is a trip cancelled before it finished.
+	TripCancelled TripStatus = "cancelled"
+	// TripStarted is a trip in progress.
+	TripStarted TripStatus = "started"
+	// TripFinished is a trip that took place.
+	TripFinished TripStatus = "finished"
+	// TripArchived is a cancelled or finished trip hidden from lists.
+	TripArchived TripStatus = "archived"
+)
+
+// tripTransitions are statuses a trip may move to from each status.
+var tripTransitions = map[TripStatus][]TripStatus{
+	TripDraft:     {TripPublished},
+	TripPublished: {TripStarted, TripCancelled},
+	TripStarted:   {TripFinished, TripCancelled},
+	TripCancelled: {TripArchived},
+	TripFinished:  {TripArchived},
+}
+
+// Valid checks if status is known.
+func (s TripStatus) Valid() bool {
+	switch s {
+	case TripDraft, TripPublished, TripCancelled, TripStarted, TripFinished, TripArchived:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanTransitionTo checks if a trip in the status may move to the next status.
+func (s TripStatus) CanTransitionTo(next TripStatus) bool {
+	return slices.Contains(tripTransitions[s], next)
+}
+
+// Joinable reports whether users may join or leave a trip in the status.
+func (s TripStatus) Joinable() bool {
+	return s == TripPublished
+}
+
+// Editable reports whether the creator may change details of a trip in the status.
+func (s TripStatus) Editable() bool {
+	return s == TripPublished
+}
+
+// shortIDLen is a length of the trip short ID.
+const shortIDLen = 8
+
+// ShortID returns a short human-friendly trip identifier that users can type.
+func (t Trip) ShortID() string {
+	return t.ID.String()[:shortIDLen]
+}
+
+// HasParticipant checks if user participates in the trip.
+func (t Trip) HasParticipant(userID UserID) bool {
+	for _, p := range t.Participants {
+		if p.User != nil && p.User.ID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Participant represents a user participating in a trip.
+type Participant struct {
+	User     *User     `json:"user,omitempty"`
+	JoinedAt time.Time `json:"joined_at,omitempty"`
 }
 
 func (t Trip) String() string {
@@ -49,19 +190,34 @@ type User struct {
 	Lastname  string `json:"lastname,omitempty"`
 }
 
+// Chat represents a group chat the bot has been added to.
+type Chat struct {
+	ID    ChatID `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+	Type  string `json:"type,omitempty"`
+	// Active is false when the bot was removed from the chat.
+	Active bool `json:"active,omitempty"`
+	// Policy defines who may announce trips in the chat.
+	Policy string `json:"policy,omitempty"`
+}
+
 // Session represents a session.
 type Session struct {
 	ID        uuid.UUID `json:"id,omitempty"`
 	User      *User     `json:"user,omitempty"`
 	ChatID    ChatID    `json:"chat_id,omitempty"`
 	UserState UserState `json:"state,omitempty"`
+	// UpdatedAt is the time of the last user activity.
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
 
 // UserState represents a user state.
 type UserState struct {
-	ID    uuid.UUID
-	State State
-	Trip  *Trip
+	ID uuid.UUID
+	// Command is a command of the active conversation, empty when there is no conversation in progress.
+	Command string
+	State   State
+	Trip    *Trip
 }
 
 //go:generate stringer -type=State -output=state_string.go -trimprefix=State
@@ -88,6 +244,32 @@ type UserState struct {
 	StateNewTripConfirm
 	// StateNewTripPublish means that trip on progress and waiting for trip publish
 	StateNewTripPublish
+	// StateEditTripSelect means that trip editing is started and waiting for the trip to edit
+	StateEditTripSelect
+	// StateEditTripField means that trip is being edited and waiting for the field to change
+	StateEditTripField
+	// StateEditTripName means that trip is being edited and waiting for a new trip name
+	StateEditTripName
+	// StateEditTripDate means that trip is being edited and waiting for a new trip date
+	StateEditTripDate
+	// StateEditTripTime means that trip is being edited and waiting for a new trip time
+	StateEditTripTime
+	// StateEditTripDescription means that trip is being edited and waiting for a new trip description
+	StateEditTripDescription
+	// StateCancelTripSelect means that trip cancellation is started and waiting for the trip to cancel
+	StateCancelTripSelect
+	// StateCancelTripConfirm means that trip is being cancelled and waiting for confirmation
+	StateCancelTripConfirm
+	// StateCancelTripReason means that trip is being cancelled and waiting for the cancellation reason
+	StateCancelTripReason
+	// StateNewTripMeetingPoint means that trip on progress and waiting for trip meeting point
+	StateNewTripMeetingPoint
+	// StateEditTripMeetingPoint means that trip is being edited and waiting for a new meeting point
+	StateEditTripMeetingPoint
+	// StateNewTripTrack means that trip on progress and waiting for trip track GPX file
+	StateNewTripTrack
+	// StateEditTripTrack means that trip is being edited and waiting for a new track GPX file
+	StateEditTripTrack
 
 	stateSentinel // Sentinel value.
 )
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestTripStatus_CanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		from models.TripStatus
+		to   models.TripStatus
+		want bool
+	}{
+		{from: models.TripDraft, to: models.TripPublished, want: true},
+		{from: models.TripDraft, to: models.TripCancelled, want: false},
+		{from: models.TripPublished, to: models.TripStarted, want: true},
+		{from: models.TripPublished, to: models.TripCancelled, want: true},
+		{from: models.TripPublished, to: models.TripDraft, want: false},
+		{from: models.TripStarted, to: models.TripFinished, want: true},
+		{from: models.TripStarted, to: models.TripCancelled, want: true},
+		{from: models.TripFinished, to: models.TripArchived, want: true},
+		{from: models.TripCancelled, to: models.TripArchived, want: true},
+		{from: models.TripCancelled, to: models.TripPublished, want: false},
+		{from: models.TripArchived, to: models.TripPublished, want: false},
+		{from: models.TripPublished, to: models.TripPublished, want: false},
+		{from: models.TripStatus("unknown"), to: models.TripPublished, want: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestTripStatus_Predicates(t *testing.T) {
+	for _, s := range []models.TripStatus{
+		models.TripDraft, models.TripPublished, models.TripCancelled,
+		models.TripStarted, models.TripFinished, models.TripArchived,
+	} {
+		assert.True(t, s.Valid(), s)
+		assert.Equal(t, s == models.TripPublished, s.Joinable(), s)
+		assert.Equal(t, s == models.TripPublished, s.Editable(), s)
+	}
+
+	assert.False(t, models.TripStatus("unknown").Valid())
+}
@@ -17,12 +17,25 @@ func _() {
 	_ = x[StateNewTripDescription-6]
 	_ = x[StateNewTripConfirm-7]
 	_ = x[StateNewTripPublish-8]
-	_ = x[stateSentinel-9]
+	_ = x[StateEditTripSelect-9]
+	_ = x[StateEditTripField-10]
+	_ = x[StateEditTripName-11]
+	_ = x[StateEditTripDate-12]
+	_ = x[StateEditTripTime-13]
+	_ = x[StateEditTripDescription-14]
+	_ = x[StateCancelTripSelect-15]
+	_ = x[StateCancelTripConfirm-16]
+	_ = x[StateCancelTripReason-17]
+	_ = x[StateNewTripMeetingPoint-18]
+	_ = x[StateEditTripMeetingPoint-19]
+	_ = x[StateNewTripTrack-20]
+	_ = x[StateEditTripTrack-21]
+	_ = x[stateSentinel-22]
 }
 
-const _State_name = "stateUnknownStartNewTripNewTripNameNewTripDateNewTripTimeNewTripDescriptionNewTripConfirmNewTripPublishstateSentinel"
+const _State_name = "stateUnknownStartNewTripNewTripNameNewTripDateNewTripTimeNewTripDescriptionNewTripConfirmNewTripPublishEditTripSelectEditTripFieldEditTripNameEditTripDateEditTripTimeEditTripDescriptionCancelTripSelectCancelTripConfirmCancelTripReasonNewTripMeetingPointEditTripMeetingPointNewTripTrackEditTripTrackstateSentinel"
 
-var _State_index = [...]uint8{0, 12, 17, 24, 35, 46, 57, 75, 89, 103, 116}
+var _State_index = [...]uint16{0, 12, 17, 24, 35, 46, 57, 75, 89, 103, 117, 130, 142, 154, 166, 185, 201, 218, 234, 253, 273, 285, 298, 311}
 
 func (i State) String() string {
 	if i >= State(len(_State_index)-1) {
@@ -1,6 +1,9 @@
 package ops
 
 import (
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
@@ -12,4 +15,7 @@ type backends interface {
 	SessionsRepository() sessions.Repository
 	TripsRepository() trips.Repository
 	StatesRepository() states.Repository
+	ParticipantsRepository() participants.Repository
+	ChatsRepository() chats.Repository
+	RemindersRepository() reminders.Repository
 }
@@ -0,0 +1,175 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+)
+
+var (
+	// ErrAlreadyLeader is returned when a user is already a ride leader in a chat.
+	ErrAlreadyLeader = errors.New("already a leader")
+	// ErrNotLeader is returned when a user is not a ride leader in a chat.
+	ErrNotLeader = errors.New("not a leader")
+)
+
+// SaveChatParams is a params for SaveChat function.
+type SaveChatParams struct {
+	ChatID int64
+	Title  string
+	Type   string
+	// Active is true while the bot is a member of the chat.
+	Active  bool
+	AddedBy int64
+}
+
+// SaveChat registers a chat or updates the registered one.
+func SaveChat(ctx context.Context, b backends, p SaveChatParams) (*models.Chat, error) {
+	c, err := b.ChatsRepository().SaveChat(ctx, chats.SaveChatParams{
+		ID:      p.ChatID,
+		Title:   p.Title,
+		Type:    p.Type,
+		Active:  p.Active,
+		AddedBy: p.AddedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save chat: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"chat_id": c.ID,
+		"active":  c.Active,
+	}).Debug("Chat saved")
+
+	return toModelChat(c), nil
+}
+
+// GetChat returns a registered chat by ID.
+func GetChat(ctx context.Context, b backends, id int64) (*models.Chat, error) {
+	c, err := b.ChatsRepository().GetChat(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get chat: %w", err)
+	}
+
+	return toModelChat(c), nil
+}
+
+// ListActiveChats returns chats the bot is a member of.
+func ListActiveChats(ctx context.Context, b backends) ([]*models.Chat, error) {
+	list, err := b.ChatsRepository().ListActiveChats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list active chats: %w", err)
+	}
+
+	result := make([]*models.Chat, 0, len(list))
+
+	for _, c := range list {
+		result = append(result, toModelChat(c))
+	}
+
+	return result, nil
+}
+
+// SetChatPolicy sets who may announce trips in the chat.
+func SetChatPolicy(ctx context.Context, b backends, chatID int64, policy string) error {
+	if err := b.ChatsRepository().SetPolicy(ctx, chatID, policy); err != nil {
+		return fmt.Errorf("set chat policy: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"target_chat_id": chatID,
+		"policy":         policy,
+	}).Debug("Chat policy changed")
+
+	return nil
+}
+
+// AddLeader approves the user as a ride leader in the chat.
+func AddLeader(ctx context.Context, b backends, chatID, userID, addedBy int64) error {
+	_, err := b.ChatsRepository().AddLeader(ctx, chatID, userID, addedBy)
+	if err != nil {
+		if errors.Is(err, chats.ErrLeaderAlreadyExists) {
+			return ErrAlreadyLeader
+		}
+
+		return fmt.Errorf("add leader: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"target_chat_id": chatID,
+		"leader_id":      userID,
+	}).Debug("Leader added")
+
+	return nil
+}
+
+// RemoveLeader revokes the ride leader approval of the user in the chat.
+func RemoveLeader(ctx context.Context, b backends, chatID, userID int64) error {
+	err := b.ChatsRepository().RemoveLeader(ctx, chatID, userID)
+	if err != nil {
+		if errors.Is(err, chats.ErrLeaderNotFound) {
+			return ErrNotLeader
+		}
+
+		return fmt.Errorf("remove leader: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"target_chat_id": chatID,
+		"leader_id":      userID,
+	}).Debug("Leader removed")
+
+	return nil
+}
+
+// IsLeader checks if the user is an approved ride leader in the chat.
+func IsLeader(ctx context.Context, b backends, chatID, userID int64) (bool, error) {
+	_, err := b.ChatsRepository().GetLeader(ctx, chatID, userID)
+	if err != nil {
+		if errors.Is(err, chats.ErrLeaderNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get leader: %w", err)
+	}
+
+	return true, nil
+}
+
+// ListLeaders returns ride leaders of the chat.
+func ListLeaders(ctx context.Context, b backends, chatID int64) ([]*models.User, error) {
+	list, err := b.ChatsRepository().ListLeaders(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("list leaders: %w", err)
+	}
+
+	result := make([]*models.User, 0, len(list))
+
+	for _, l := range list {
+		user, err := GetUser(ctx, b, l.UserID)
+		if err != nil {
+			log.WithError(ctx, err).WithField("leader_id", l.UserID).Warn("Failed to get leader user, skipping")
+
+			continue
+		}
+
+		result = append(result, user)
+	}
+
+	return result, nil
+}
+
+func toModelChat(c *chats.Chat) *models.Chat {
+	return &models.Chat{
+		ID:     c.ID,
+		Title:  c.Title,
+		Type:   c.Type,
+		Active: c.Active,
+		Policy: c.Policy,
+	}
+}
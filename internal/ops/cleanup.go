@@ -0,0 +1,117 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+// ExpireSessions deletes sessions without activity since idleSince.
+// A session is deleted only if it's still idle at the time of deletion, so users active meanwhile keep theirs.
+// States of expired sessions are reset when the users start a new session.
+// Expired sessions are returned, so callers can notify users.
+func ExpireSessions(ctx context.Context, b backends, idleSince time.Time) ([]*models.Session, error) {
+	list, err := ListSessions(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []*models.Session
+
+	for _, sess := range list {
+		if !sess.UpdatedAt.Before(idleSince) {
+			continue
+		}
+
+		if err = b.SessionsRepository().DeleteIdleSession(ctx, sess.User.ID, idleSince); err != nil {
+			if errors.Is(err, sessions.ErrNotFound) {
+				continue
+			}
+
+			return expired, fmt.Errorf("failed to delete session of user[%d]: %w", sess.User.ID, err)
+		}
+
+		log.WithFields(ctx, log.Fields{
+			"user_id": sess.User.ID,
+			"chat_id": sess.ChatID,
+		}).Debug("Session expired")
+
+		expired = append(expired, sess)
+	}
+
+	return expired, nil
+}
+
+// PurgeDraftTrips permanently removes not published trips, that are not updated since updatedBefore
+// and not used by any user state anymore. States without a session are not in use, they are reset
+// when the user starts a new session. Number of removed trips is returned.
+func PurgeDraftTrips(ctx context.Context, b backends, updatedBefore time.Time) (int, error) {
+	sessList, err := b.SessionsRepository().ListSessions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	active := make(map[uuid.UUID]struct{}, len(sessList))
+
+	for _, sess := range sessList {
+		if sess.StateID != nil {
+			active[*sess.StateID] = struct{}{}
+		}
+	}
+
+	list, err := b.StatesRepository().ListStates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list states: %w", err)
+	}
+
+	inUse := make(map[uuid.UUID]struct{}, len(list))
+
+	for _, st := range list {
+		if _, ok := active[st.ID]; !ok {
+			continue
+		}
+
+		if st.TripID != nil {
+			inUse[*st.TripID] = struct{}{}
+		}
+	}
+
+	tripsList, err := b.TripsRepository().ListTrips(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list trips: %w", err)
+	}
+
+	var purged int
+
+	for _, t := range tripsList {
+		if t.Status != trips.StatusDraft || !t.UpdatedAt.Before(updatedBefore) {
+			continue
+		}
+
+		if _, ok := inUse[t.ID]; ok {
+			continue
+		}
+
+		if err = b.TripsRepository().PurgeTrip(ctx, t.ID); err != nil {
+			if errors.Is(err, trips.ErrNotFound) {
+				continue
+			}
+
+			return purged, fmt.Errorf("failed to purge trip[%s]: %w", t.ID, err)
+		}
+
+		log.WithField(ctx, "trip_id", t.ID).Debug("Draft trip purged")
+
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,131 @@
+package ops_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
+)
+
+func TestExpireSessions(t *testing.T) {
+	ctx := context.Background()
+	b := opstest.NewBackends(t)
+
+	idle := opstest.NewSession(t, b, 1)
+	active := opstest.NewSession(t, b, 2)
+
+	trip := opstest.NewTrip(t, b, idle.User.ID, startsAt)
+
+	idle.UserState.State = models.StateNewTripName
+	idle.UserState.Command = "newtrip"
+	idle.UserState.Trip = trip
+	require.NoError(t, ops.UpdateSession(ctx, b, idle))
+
+	cutoff := tick()
+
+	require.NoError(t, ops.TouchSession(ctx, b, active))
+
+	expired, err := ops.ExpireSessions(ctx, b, cutoff)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, idle.User.ID, expired[0].User.ID)
+	assert.Equal(t, models.StateNewTripName, expired[0].UserState.State, "expired session is returned as it was")
+
+	_, err = ops.GetSession(ctx, b, idle.User)
+	require.ErrorIs(t, err, sessions.ErrNotFound)
+
+	_, err = ops.GetSession(ctx, b, active.User)
+	require.NoError(t, err)
+
+	// The conversation starts over in a new session.
+	sess, err := ops.CreateSession(ctx, b, ops.CreateSessionParams{User: idle.User, ChatID: idle.ChatID})
+	require.NoError(t, err)
+	assert.Equal(t, idle.UserState.ID, sess.UserState.ID)
+	assert.Equal(t, models.StateStart, sess.UserState.State)
+	assert.Empty(t, sess.UserState.Command)
+	assert.Nil(t, sess.UserState.Trip)
+
+	expired, err = ops.ExpireSessions(ctx, b, cutoff)
+	require.NoError(t, err)
+	assert.Empty(t, expired, "sessions active since cutoff are kept")
+}
+
+func TestExpireSessions_ActiveMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	b := opstest.NewBackends(t)
+
+	sess := opstest.NewSession(t, b, 1)
+
+	cutoff := tick()
+
+	// The session was idle when listed, but the user came back before it's deleted.
+	require.NoError(t, ops.TouchSession(ctx, b, sess))
+
+	err := b.SessionsRepository().DeleteIdleSession(ctx, sess.User.ID, cutoff)
+	require.ErrorIs(t, err, sessions.ErrNotFound)
+
+	_, err = ops.GetSession(ctx, b, sess.User)
+	require.NoError(t, err)
+}
+
+func TestPurgeDraftTrips(t *testing.T) {
+	ctx := context.Background()
+	b := opstest.NewBackends(t)
+
+	sess := opstest.NewSession(t, b, 1)
+	expiring := opstest.NewSession(t, b, 2)
+
+	orphan := opstest.NewTrip(t, b, sess.User.ID, startsAt)
+	inProgress := opstest.NewTrip(t, b, sess.User.ID, startsAt)
+	abandoned := opstest.NewTrip(t, b, expiring.User.ID, startsAt)
+	published := opstest.NewTrip(t, b, sess.User.ID, startsAt)
+
+	_, err := ops.SetTripStatus(ctx, b, published.ID, models.TripPublished)
+	require.NoError(t, err)
+
+	sess.UserState.Trip = inProgress
+	require.NoError(t, ops.UpdateSession(ctx, b, sess))
+
+	expiring.UserState.Trip = abandoned
+	require.NoError(t, ops.UpdateSession(ctx, b, expiring))
+
+	cutoff := tick()
+
+	recent := opstest.NewTrip(t, b, sess.User.ID, startsAt)
+
+	expired, err := ops.ExpireSessions(ctx, b, cutoff)
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+
+	// Keep the first user's session and its trip in progress.
+	_, err = ops.CreateSession(ctx, b, ops.CreateSessionParams{User: sess.User, ChatID: sess.ChatID})
+	require.NoError(t, err)
+	require.NoError(t, ops.UpdateSession(ctx, b, sess))
+
+	purged, err := ops.PurgeDraftTrips(ctx, b, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	testCases := []struct {
+		name string
+		trip *models.Trip
+		kept bool
+	}{
+		{name: "not used", trip: orphan, kept: false},
+		{name: "in progress", trip: inProgress, kept: true},
+		{name: "used by expired session", trip: abandoned, kept: false},
+		{name: "published", trip: published, kept: true},
+		{name: "updated after cutoff", trip: recent, kept: true},
+	}
+
+	for _, tt := range testCases {
+		_, err = ops.GetTrip(ctx, b, tt.trip.ID)
+		assert.Equal(t, tt.kept, err == nil, tt.name)
+	}
+}
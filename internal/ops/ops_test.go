@@ -0,0 +1,19 @@
+package ops_test
+
+import (
+	"time"
+)
+
+// startsAt is a start time of trips in tests that do not depend on the clock.
+var startsAt = time.Date(2030, time.May, 1, 8, 0, 0, 0, time.UTC)
+
+// tick makes sure the clock moves on, so timestamps before and after it differ.
+func tick() time.Time {
+	time.Sleep(10 * time.Millisecond)
+
+	now := time.Now()
+
+	time.Sleep(10 * time.Millisecond)
+
+	return now
+}
@@ -0,0 +1,87 @@
+// Package opstest provides fixtures for tests of the business logic built on ops.
+package opstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/users"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service/backends"
+)
+
+// NewBackends returns backends with empty in-memory repositories.
+func NewBackends(t testing.TB) *backends.Backends {
+	t.Helper()
+
+	b, err := backends.New(backends.NewParams{
+		Users:        users.NewInMemory(),
+		States:       states.NewInMemory(),
+		Sessions:     sessions.NewInMemory(),
+		Trips:        trips.NewInMemory(),
+		Participants: participants.NewInMemory(),
+		Chats:        chats.NewInMemory(),
+		Reminders:    reminders.NewInMemory(),
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+// NewUser creates the user.
+func NewUser(t testing.TB, b *backends.Backends, userID int64) *models.User {
+	t.Helper()
+
+	user, err := ops.CreateUser(context.Background(), b, ops.CreateUserParams{
+		UserID:    userID,
+		Firstname: "Rider",
+	})
+	require.NoError(t, err)
+
+	return user
+}
+
+// NewSession creates the user and its session in the private chat.
+func NewSession(t testing.TB, b *backends.Backends, userID int64) *models.Session {
+	t.Helper()
+
+	sess, err := ops.CreateSession(context.Background(), b, ops.CreateSessionParams{
+		User:   NewUser(t, b, userID),
+		ChatID: userID,
+	})
+	require.NoError(t, err)
+
+	return sess
+}
+
+// NewTrip creates a trip of the user starting at startsAt and moves it through statuses in order.
+// Without statuses the trip stays a draft.
+func NewTrip(t testing.TB, b *backends.Backends, userID int64, startsAt time.Time, statuses ...models.TripStatus) *models.Trip {
+	t.Helper()
+
+	ctx := context.Background()
+
+	trip, err := ops.CreateTrip(ctx, b, ops.CreateTripParams{
+		Name:      "Morning ride",
+		StartsAt:  startsAt,
+		CreatedBy: userID,
+	})
+	require.NoError(t, err)
+
+	for _, status := range statuses {
+		trip, err = ops.SetTripStatus(ctx, b, trip.ID, status)
+		require.NoError(t, err)
+	}
+
+	return trip
+}
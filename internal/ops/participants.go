@@ -0,0 +1,215 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+var (
+	// ErrTripNotPublished is returned when a user tries to join or leave a trip that is not published,
+	// e.g. it's a draft or it's cancelled.
+	ErrTripNotPublished = errors.New("trip is not published")
+	// ErrAlreadyJoined is returned when a user already participates in a trip.
+	ErrAlreadyJoined = errors.New("already joined the trip")
+	// ErrNotJoined is returned when a user doesn't participate in a trip.
+	ErrNotJoined = errors.New("not joined the trip")
+	// ErrTripNotFound is returned when a trip can't be found by reference.
+	ErrTripNotFound = errors.New("trip not found")
+	// ErrAmbiguousTripRef is returned when a trip reference matches several trips.
+	ErrAmbiguousTripRef = errors.New("trip reference matches several trips")
+)
+
+// JoinTrip adds user to the trip participants.
+func JoinTrip(ctx context.Context, b backends, tripID uuid.UUID, userID int64) (*models.Trip, error) {
+	t, err := b.TripsRepository().GetTripByID(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("get trip by ID: %w", err)
+	}
+
+	if !models.TripStatus(t.Status).Joinable() {
+		return nil, ErrTripNotPublished
+	}
+
+	_, err = b.ParticipantsRepository().AddParticipant(ctx, tripID, userID)
+	if err != nil {
+		if errors.Is(err, participants.ErrAlreadyExists) {
+			return nil, ErrAlreadyJoined
+		}
+
+		return nil, fmt.Errorf("add participant: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"trip_id": tripID,
+		"user_id": userID,
+	}).Debug("User joined trip")
+
+	return GetTrip(ctx, b, tripID)
+}
+
+// LeaveTrip removes user from the trip participants.
+func LeaveTrip(ctx context.Context, b backends, tripID uuid.UUID, userID int64) (*models.Trip, error) {
+	t, err := b.TripsRepository().GetTripByID(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("get trip by ID: %w", err)
+	}
+
+	if !models.TripStatus(t.Status).Joinable() {
+		return nil, ErrTripNotPublished
+	}
+
+	err = b.ParticipantsRepository().RemoveParticipant(ctx, tripID, userID)
+	if err != nil {
+		if errors.Is(err, participants.ErrNotFound) {
+			return nil, ErrNotJoined
+		}
+
+		return nil, fmt.Errorf("remove participant: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"trip_id": tripID,
+		"user_id": userID,
+	}).Debug("User left trip")
+
+	return GetTrip(ctx, b, tripID)
+}
+
+// ListJoinedTrips returns trips the user participates in ordered by join time, archived trips are skipped.
+func ListJoinedTrips(ctx context.Context, b backends, userID int64) ([]*models.Trip, error) {
+	list, err := b.ParticipantsRepository().ListParticipantsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list participants by user: %w", err)
+	}
+
+	result := make([]*models.Trip, 0, len(list))
+
+	for _, p := range list {
+		t, err := GetTrip(ctx, b, p.TripID)
+		if err != nil {
+			if errors.Is(err, trips.ErrNotFound) {
+				// Trip was deleted after user joined it.
+				continue
+			}
+
+			return nil, err
+		}
+
+		if t.Status == models.TripArchived {
+			continue
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// ListPublishedTrips returns all published trips ordered by creation time.
+func ListPublishedTrips(ctx context.Context, b backends) ([]*models.Trip, error) {
+	list, err := b.TripsRepository().ListTrips(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list trips: %w", err)
+	}
+
+	result := make([]*models.Trip, 0, len(list))
+
+	for _, t := range list {
+		if t.Status != trips.StatusPublished {
+			continue
+		}
+
+		trip, err := GetTrip(ctx, b, t.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, trip)
+	}
+
+	return result, nil
+}
+
+// FindTrip returns a trip by reference, which is either a full trip ID or its short ID.
+func FindTrip(ctx context.Context, b backends, ref string) (*models.Trip, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if ref == "" {
+		return nil, ErrTripNotFound
+	}
+
+	if id, err := uuid.FromString(ref); err == nil {
+		t, err := GetTrip(ctx, b, id)
+		if err != nil {
+			if errors.Is(err, trips.ErrNotFound) {
+				return nil, ErrTripNotFound
+			}
+
+			return nil, err
+		}
+
+		return t, nil
+	}
+
+	list, err := b.TripsRepository().ListTrips(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list trips: %w", err)
+	}
+
+	var found *trips.Trip
+
+	for _, t := range list {
+		if !strings.HasPrefix(t.ID.String(), ref) {
+			continue
+		}
+
+		if found != nil {
+			return nil, ErrAmbiguousTripRef
+		}
+
+		found = t
+	}
+
+	if found == nil {
+		return nil, ErrTripNotFound
+	}
+
+	return GetTrip(ctx, b, found.ID)
+}
+
+// listParticipants returns trip participants with resolved users.
+func listParticipants(ctx context.Context, b backends, tripID uuid.UUID) ([]*models.Participant, error) {
+	list, err := b.ParticipantsRepository().ListParticipantsByTrip(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("list participants by trip: %w", err)
+	}
+
+	result := make([]*models.Participant, 0, len(list))
+
+	for _, p := range list {
+		user, err := GetUser(ctx, b, p.UserID)
+		if err != nil {
+			log.WithError(ctx, err).WithFields(log.Fields{
+				"trip_id": tripID,
+				"user_id": p.UserID,
+			}).Warn("Failed to get participant user, skipping")
+
+			continue
+		}
+
+		result = append(result, &models.Participant{
+			User:     user,
+			JoinedAt: p.JoinedAt,
+		})
+	}
+
+	return result, nil
+}
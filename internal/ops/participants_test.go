@@ -0,0 +1,133 @@
+package ops_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+func TestJoinAndLeaveTrip(t *testing.T) {
+	ctx := context.Background()
+	b := opstest.NewBackends(t)
+
+	const (
+		creatorID int64 = 1
+		riderID   int64 = 2
+	)
+
+	opstest.NewUser(t, b, creatorID)
+	opstest.NewUser(t, b, riderID)
+
+	published := opstest.NewTrip(t, b, creatorID, startsAt, models.TripPublished).ID
+	draft := opstest.NewTrip(t, b, creatorID, startsAt).ID
+	cancelled := opstest.NewTrip(t, b, creatorID, startsAt, models.TripPublished, models.TripCancelled).ID
+
+	testCases := []struct {
+		name       string
+		id         uuid.UUID
+		join       bool
+		wantErr    error
+		wantJoined bool
+	}{
+		{
+			name:    "leave not joined",
+			id:      published,
+			join:    false,
+			wantErr: ops.ErrNotJoined,
+		},
+		{
+			name:       "join",
+			id:         published,
+			join:       true,
+			wantJoined: true,
+		},
+		{
+			name:    "join twice",
+			id:      published,
+			join:    true,
+			wantErr: ops.ErrAlreadyJoined,
+		},
+		{
+			name:       "leave",
+			id:         published,
+			join:       false,
+			wantJoined: false,
+		},
+		{
+			name:    "leave twice",
+			id:      published,
+			join:    false,
+			wantErr: ops.ErrNotJoined,
+		},
+		{
+			name:    "join draft",
+			id:      draft,
+			join:    true,
+			wantErr: ops.ErrTripNotPublished,
+		},
+		{
+			name:    "leave draft",
+			id:      draft,
+			join:    false,
+			wantErr: ops.ErrTripNotPublished,
+		},
+		{
+			name:    "join cancelled",
+			id:      cancelled,
+			join:    true,
+			wantErr: ops.ErrTripNotPublished,
+		},
+		{
+			name:    "leave cancelled",
+			id:      cancelled,
+			join:    false,
+			wantErr: ops.ErrTripNotPublished,
+		},
+		{
+			name:    "join unknown",
+			id:      uuid.Must(uuid.NewV4()),
+			join:    true,
+			wantErr: trips.ErrNotFound,
+		},
+		{
+			name:    "leave unknown",
+			id:      uuid.Must(uuid.NewV4()),
+			join:    false,
+			wantErr: trips.ErrNotFound,
+		},
+	}
+
+	// Cases run in order, each one continues from the state the previous left.
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			toggle := ops.LeaveTrip
+			if tt.join {
+				toggle = ops.JoinTrip
+			}
+
+			trip, err := toggle(ctx, b, tt.id, riderID)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, trip)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.id, trip.ID)
+			assert.Equal(t, tt.wantJoined, trip.HasParticipant(riderID))
+
+			stored, err := ops.GetTrip(ctx, b, tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantJoined, stored.HasParticipant(riderID))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
+)
+
+// ErrReminderSent is returned when the reminder is already sent.
+var ErrReminderSent = errors.New("reminder is already sent")
+
+// DueReminder is a trip reminder that should be sent now.
+type DueReminder struct {
+	Trip *models.Trip
+	// Offset is how long before the trip start the reminder was due.
+	Offset time.Duration
+}
+
+// ListDueReminders returns not sent reminders of upcoming trips, which time has come according to offsets.
+// When several reminders of a trip are due, e.g. after downtime, only the one closest to the start is returned,
+// the earlier ones are outdated and never sent.
+func ListDueReminders(ctx context.Context, b backends, now time.Time, offsets []time.Duration) ([]*DueReminder, error) {
+	if len(offsets) == 0 {
+		return nil, nil
+	}
+
+	list, err := ListUpcomingTrips(ctx, b, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*DueReminder
+
+	for _, t := range list {
+		sent, err := b.RemindersRepository().ListRemindersByTrip(ctx, t.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list reminders of trip[%s]: %w", t.ID, err)
+		}
+
+		offset, ok := dueOffset(t.StartsAt.Sub(now), offsets, sent)
+		if !ok {
+			continue
+		}
+
+		due = append(due, &DueReminder{Trip: t, Offset: offset})
+	}
+
+	return due, nil
+}
+
+// dueOffset returns the smallest offset, which is already due when the trip starts in left,
+// and which is closer to the start than all sent reminders.
+func dueOffset(left time.Duration, offsets []time.Duration, sent []*reminders.Reminder) (time.Duration, bool) {
+	var (
+		offset time.Duration
+		found  bool
+	)
+
+	for _, o := range offsets {
+		if o < left {
+			continue
+		}
+
+		if slices.ContainsFunc(sent, func(r *reminders.Reminder) bool {
+			return r.Offset <= o
+		}) {
+			continue
+		}
+
+		if !found || o < offset {
+			offset, found = o, true
+		}
+	}
+
+	return offset, found
+}
+
+// ClaimReminder records the reminder as sent before sending it, so it's never sent twice,
+// even by concurrent bot instances. ErrReminderSent is returned when it's claimed already.
+func ClaimReminder(ctx context.Context, b backends, tripID uuid.UUID, offset time.Duration) error {
+	_, err := b.RemindersRepository().AddReminder(ctx, tripID, offset)
+	if err != nil {
+		if errors.Is(err, reminders.ErrAlreadyExists) {
+			return ErrReminderSent
+		}
+
+		return fmt.Errorf("add reminder: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"trip_id": tripID,
+		"offset":  offset.String(),
+	}).Debug("Reminder claimed")
+
+	return nil
+}
+
+// ResetReminders forgets reminders sent about the trip, so they are sent again relative to its new start time.
+func ResetReminders(ctx context.Context, b backends, tripID uuid.UUID) error {
+	if err := b.RemindersRepository().DeleteRemindersByTrip(ctx, tripID); err != nil {
+		return fmt.Errorf("delete reminders of trip[%s]: %w", tripID, err)
+	}
+
+	log.WithField(ctx, "trip_id", tripID).Debug("Reminders reset")
+
+	return nil
+}
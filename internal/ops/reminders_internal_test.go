@@ -0,0 +1,111 @@
+package ops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
+)
+
+func TestDueOffset(t *testing.T) {
+	offsets := []time.Duration{24 * time.Hour, 2 * time.Hour, 30 * time.Minute}
+
+	sent := func(offsets ...time.Duration) []*reminders.Reminder {
+		list := make([]*reminders.Reminder, 0, len(offsets))
+
+		for _, o := range offsets {
+			list = append(list, &reminders.Reminder{Offset: o})
+		}
+
+		return list
+	}
+
+	testCases := []struct {
+		name   string
+		left   time.Duration
+		sent   []*reminders.Reminder
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name: "nothing due yet",
+			left: 25 * time.Hour,
+		},
+		{
+			name:   "first reminder due",
+			left:   24 * time.Hour,
+			want:   24 * time.Hour,
+			wantOK: true,
+		},
+		{
+			name:   "first reminder overdue",
+			left:   23 * time.Hour,
+			want:   24 * time.Hour,
+			wantOK: true,
+		},
+		{
+			name: "first reminder sent",
+			left: 23 * time.Hour,
+			sent: sent(24 * time.Hour),
+		},
+		{
+			name:   "next reminder due after the sent one",
+			left:   time.Hour,
+			sent:   sent(24 * time.Hour),
+			want:   2 * time.Hour,
+			wantOK: true,
+		},
+		{
+			name:   "several due after downtime, the closest to the start is chosen",
+			left:   20 * time.Minute,
+			want:   30 * time.Minute,
+			wantOK: true,
+		},
+		{
+			name:   "several due after downtime with the first one sent",
+			left:   20 * time.Minute,
+			sent:   sent(24 * time.Hour),
+			want:   30 * time.Minute,
+			wantOK: true,
+		},
+		{
+			name: "later reminder sent, earlier ones are outdated",
+			left: 20 * time.Minute,
+			sent: sent(30 * time.Minute),
+		},
+		{
+			name: "all reminders sent",
+			left: 10 * time.Minute,
+			sent: sent(24*time.Hour, 2*time.Hour, 30*time.Minute),
+		},
+		{
+			name:   "published after the first offset has passed",
+			left:   10 * time.Hour,
+			want:   24 * time.Hour,
+			wantOK: true,
+		},
+		{
+			name:   "published after all offsets have passed",
+			left:   10 * time.Minute,
+			want:   30 * time.Minute,
+			wantOK: true,
+		},
+		{
+			name:   "sent with an offset no longer configured",
+			left:   20 * time.Minute,
+			sent:   sent(time.Hour),
+			want:   30 * time.Minute,
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := dueOffset(tt.left, offsets, tt.sent)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
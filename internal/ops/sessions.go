@@ -36,13 +36,15 @@ func GetSession(ctx context.Context, b backends, user *models.User) (*models.Ses
 	}
 
 	resp := models.Session{
-		ID:     sess.ID,
-		User:   user,
-		ChatID: sess.ChatID,
+		ID:        sess.ID,
+		User:      user,
+		ChatID:    sess.ChatID,
+		UpdatedAt: sess.UpdatedAt,
 		UserState: models.UserState{
-			ID:    state.ID,
-			State: models.State(state.Step),
-			Trip:  trip,
+			ID:      state.ID,
+			Command: state.Command,
+			State:   models.State(state.Step),
+			Trip:    trip,
 		},
 	}
 
@@ -83,6 +85,20 @@ func CreateSession(ctx context.Context, b backends, p CreateSessionParams) (*mod
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	// The state is left from an expired session, the conversation starts over.
+	if state.Step != uint(models.StateStart) || state.TripID != nil || state.Command != "" {
+		err = b.StatesRepository().UpdateState(ctx, &states.State{
+			ID:      state.ID,
+			UserID:  p.User.ID,
+			TripID:  nil,
+			Command: "",
+			Step:    uint(models.StateStart),
+		})
+		if err != nil && !errors.Is(err, states.ErrNoChanges) {
+			return nil, fmt.Errorf("failed to reset state of user[%d]: %w", p.User.ID, err)
+		}
+	}
+
 	log.WithFields(ctx, log.Fields{
 		"user_id": p.User.ID,
 		"chat_id": p.ChatID,
@@ -106,10 +122,10 @@ func UpdateSession(ctx context.Context, b backends, sess *models.Session) error
 		ID:      sess.UserState.ID,
 		UserID:  uid,
 		TripID:  tid,
-		Command: "",
+		Command: sess.UserState.Command,
 		Step:    uint(sess.UserState.State),
 	})
-	if err != nil {
+	if err != nil && !errors.Is(err, states.ErrNoChanges) {
 		return fmt.Errorf("failed to update state: %w", err)
 	}
 
@@ -129,12 +145,22 @@ func UpdateSession(ctx context.Context, b backends, sess *models.Session) error
 		"chat_id":          sess.ChatID,
 		"session_id":       sess.ID,
 		"current_state":    sess.UserState.State.String(),
+		"command":          sess.UserState.Command,
 		"trip_in_progress": sess.UserState.Trip != nil,
 	}).Debug("Session updated")
 
 	return nil
 }
 
+// TouchSession marks the user session as active.
+func TouchSession(ctx context.Context, b backends, sess *models.Session) error {
+	if err := b.SessionsRepository().TouchSession(ctx, sess.User.ID); err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return nil
+}
+
 // ListSessions returns list of sessions.
 func ListSessions(ctx context.Context, b backends) ([]*models.Session, error) {
 	list, err := b.SessionsRepository().ListSessions(ctx)
@@ -169,13 +195,15 @@ func ListSessions(ctx context.Context, b backends) ([]*models.Session, error) {
 		}
 
 		resp = append(resp, &models.Session{
-			ID:     sess.ID,
-			User:   user,
-			ChatID: sess.ChatID,
+			ID:        sess.ID,
+			User:      user,
+			ChatID:    sess.ChatID,
+			UpdatedAt: sess.UpdatedAt,
 			UserState: models.UserState{
-				ID:    state.ID,
-				State: models.State(state.Step),
-				Trip:  trip,
+				ID:      state.ID,
+				Command: state.Command,
+				State:   models.State(state.Step),
+				Trip:    trip,
 			},
 		})
 	}
@@ -0,0 +1,134 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+// ErrInvalidTransition is returned when the trip can't move to the requested status from its current one.
+var ErrInvalidTransition = errors.New("invalid trip status transition")
+
+// SetTripStatus moves the trip to the status, only transitions allowed by the trip lifecycle are made.
+func SetTripStatus(ctx context.Context, b backends, id uuid.UUID, status models.TripStatus) (*models.Trip, error) {
+	return setTripStatus(ctx, b, id, status, func(from models.TripStatus) error {
+		return b.TripsRepository().SetStatus(ctx, id, string(from), string(status))
+	})
+}
+
+// CancelTrip cancels the trip and records the reason it was cancelled for.
+func CancelTrip(ctx context.Context, b backends, id uuid.UUID, reason string) (*models.Trip, error) {
+	return setTripStatus(ctx, b, id, models.TripCancelled, func(from models.TripStatus) error {
+		return b.TripsRepository().CancelTrip(ctx, id, string(from), reason)
+	})
+}
+
+// setTripStatus checks the transition of the trip to the status and makes it with set.
+func setTripStatus(
+	ctx context.Context,
+	b backends,
+	id uuid.UUID,
+	status models.TripStatus,
+	set func(from models.TripStatus) error,
+) (*models.Trip, error) {
+	t, err := b.TripsRepository().GetTripByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get trip by ID: %w", err)
+	}
+
+	from := models.TripStatus(t.Status)
+
+	if !from.CanTransitionTo(status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, status)
+	}
+
+	if err = set(from); err != nil {
+		if errors.Is(err, trips.ErrStatusConflict) {
+			return nil, fmt.Errorf("%w: %s -> %s: status changed concurrently", ErrInvalidTransition, from, status)
+		}
+
+		return nil, fmt.Errorf("set trip status: %w", err)
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"trip_id": id,
+		"from":    from,
+		"to":      status,
+	}).Debug("Trip status changed")
+
+	return GetTrip(ctx, b, id)
+}
+
+// AdvanceTripsParams is a params for AdvanceTrips function.
+type AdvanceTripsParams struct {
+	// Now is the current time.
+	Now time.Time
+	// FinishAfter is how long after the start a trip is considered finished.
+	FinishAfter time.Duration
+	// ArchiveAfter is how long finished and cancelled trips stay visible before they are archived.
+	ArchiveAfter time.Duration
+}
+
+// AdvanceTrips moves trips along the lifecycle as time goes: published trips start at their start time,
+// started trips finish after FinishAfter, finished and cancelled trips are archived after ArchiveAfter.
+// Trips whose status changed are returned.
+func AdvanceTrips(ctx context.Context, b backends, p AdvanceTripsParams) ([]*models.Trip, error) {
+	list, err := b.TripsRepository().ListTrips(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list trips: %w", err)
+	}
+
+	var changed []*models.Trip
+
+	for _, t := range list {
+		next, ok := nextTripStatus(t, p)
+		if !ok {
+			continue
+		}
+
+		trip, err := SetTripStatus(ctx, b, t.ID, next)
+		if err != nil {
+			if errors.Is(err, ErrInvalidTransition) || errors.Is(err, trips.ErrNotFound) {
+				// Trip was changed or deleted concurrently, it's handled on the next run.
+				continue
+			}
+
+			return changed, err
+		}
+
+		changed = append(changed, trip)
+	}
+
+	return changed, nil
+}
+
+// nextTripStatus returns the status the trip should have moved to by now.
+func nextTripStatus(t *trips.Trip, p AdvanceTripsParams) (models.TripStatus, bool) {
+	switch t.Status {
+	case trips.StatusPublished:
+		if !t.StartsAt.IsZero() && !t.StartsAt.After(p.Now) {
+			return models.TripStarted, true
+		}
+	case trips.StatusStarted:
+		if !t.StartsAt.Add(p.FinishAfter).After(p.Now) {
+			return models.TripFinished, true
+		}
+	case trips.StatusFinished:
+		if !t.FinishedAt.Add(p.ArchiveAfter).After(p.Now) {
+			return models.TripArchived, true
+		}
+	case trips.StatusCancelled:
+		if !t.CancelledAt.Add(p.ArchiveAfter).After(p.Now) {
+			return models.TripArchived, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,105 @@
+package ops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+func TestNextTripStatus(t *testing.T) {
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	p := AdvanceTripsParams{
+		Now:          now,
+		FinishAfter:  6 * time.Hour,
+		ArchiveAfter: 7 * 24 * time.Hour,
+	}
+
+	testCases := []struct {
+		name   string
+		trip   trips.Trip
+		want   models.TripStatus
+		wantOK bool
+	}{
+		{
+			name: "draft is never advanced",
+			trip: trips.Trip{Status: trips.StatusDraft, StartsAt: now.Add(-time.Hour)},
+		},
+		{
+			name: "published before the start",
+			trip: trips.Trip{Status: trips.StatusPublished, StartsAt: now.Add(time.Minute)},
+		},
+		{
+			name:   "published at the start",
+			trip:   trips.Trip{Status: trips.StatusPublished, StartsAt: now},
+			want:   models.TripStarted,
+			wantOK: true,
+		},
+		{
+			name:   "published after the start",
+			trip:   trips.Trip{Status: trips.StatusPublished, StartsAt: now.Add(-time.Hour)},
+			want:   models.TripStarted,
+			wantOK: true,
+		},
+		{
+			name: "published without start time",
+			trip: trips.Trip{Status: trips.StatusPublished},
+		},
+		{
+			name: "started before finish",
+			trip: trips.Trip{Status: trips.StatusStarted, StartsAt: now.Add(-5 * time.Hour)},
+		},
+		{
+			name:   "started at finish",
+			trip:   trips.Trip{Status: trips.StatusStarted, StartsAt: now.Add(-6 * time.Hour)},
+			want:   models.TripFinished,
+			wantOK: true,
+		},
+		{
+			name:   "started without start time",
+			trip:   trips.Trip{Status: trips.StatusStarted},
+			want:   models.TripFinished,
+			wantOK: true,
+		},
+		{
+			name: "finished recently",
+			trip: trips.Trip{Status: trips.StatusFinished, FinishedAt: now.Add(-24 * time.Hour)},
+		},
+		{
+			name:   "finished long ago",
+			trip:   trips.Trip{Status: trips.StatusFinished, FinishedAt: now.Add(-7 * 24 * time.Hour)},
+			want:   models.TripArchived,
+			wantOK: true,
+		},
+		{
+			name: "cancelled recently",
+			trip: trips.Trip{Status: trips.StatusCancelled, StartsAt: now.Add(-30 * 24 * time.Hour), CancelledAt: now.Add(-time.Hour)},
+		},
+		{
+			name: "cancelled recently without start time",
+			trip: trips.Trip{Status: trips.StatusCancelled, CancelledAt: now.Add(-time.Hour)},
+		},
+		{
+			name:   "cancelled long ago",
+			trip:   trips.Trip{Status: trips.StatusCancelled, StartsAt: now, CancelledAt: now.Add(-8 * 24 * time.Hour)},
+			want:   models.TripArchived,
+			wantOK: true,
+		},
+		{
+			name: "archived is final",
+			trip: trips.Trip{Status: trips.StatusArchived, StartsAt: now.Add(-30 * 24 * time.Hour)},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nextTripStatus(&tt.trip, p)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
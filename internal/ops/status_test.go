@@ -0,0 +1,106 @@
+package ops_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+)
+
+func TestAdvanceTrips(t *testing.T) {
+	ctx := context.Background()
+	b := opstest.NewBackends(t)
+
+	// Status times are recorded by the repository clock, so the trips are relative to it.
+	now := time.Now().UTC()
+
+	opstest.NewUser(t, b, 1)
+
+	draft := opstest.NewTrip(t, b, 1, now.Add(-time.Hour))
+	upcoming := opstest.NewTrip(t, b, 1, now.Add(time.Hour), models.TripPublished)
+	starting := opstest.NewTrip(t, b, 1, now.Add(-time.Minute), models.TripPublished)
+	noStart := opstest.NewTrip(t, b, 1, time.Time{}, models.TripPublished)
+	riding := opstest.NewTrip(t, b, 1, now.Add(-time.Hour), models.TripPublished, models.TripStarted)
+	finishing := opstest.NewTrip(t, b, 1, now.Add(-7*time.Hour), models.TripPublished, models.TripStarted)
+	cancelled := opstest.NewTrip(t, b, 1, now.Add(time.Hour), models.TripPublished, models.TripCancelled)
+
+	p := ops.AdvanceTripsParams{
+		Now:          now,
+		FinishAfter:  6 * time.Hour,
+		ArchiveAfter: 7 * 24 * time.Hour,
+	}
+
+	changed, err := ops.AdvanceTrips(ctx, b, p)
+	require.NoError(t, err)
+
+	got := make(map[string]models.TripStatus, len(changed))
+	for _, trip := range changed {
+		got[trip.ID.String()] = trip.Status
+	}
+
+	assert.Equal(t, map[string]models.TripStatus{
+		starting.ID.String():  models.TripStarted,
+		finishing.ID.String(): models.TripFinished,
+	}, got)
+
+	// Statuses are advanced one step per run, nothing changes until time goes.
+	changed, err = ops.AdvanceTrips(ctx, b, p)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	p.Now = now.Add(p.ArchiveAfter + time.Minute)
+
+	changed, err = ops.AdvanceTrips(ctx, b, p)
+	require.NoError(t, err)
+
+	got = make(map[string]models.TripStatus, len(changed))
+	for _, trip := range changed {
+		got[trip.ID.String()] = trip.Status
+	}
+
+	assert.Equal(t, map[string]models.TripStatus{
+		upcoming.ID.String():  models.TripStarted,
+		starting.ID.String():  models.TripFinished,
+		riding.ID.String():    models.TripFinished,
+		finishing.ID.String(): models.TripArchived,
+		cancelled.ID.String(): models.TripArchived,
+	}, got)
+
+	for _, trip := range []*models.Trip{draft, noStart} {
+		stored, err := ops.GetTrip(ctx, b, trip.ID)
+		require.NoError(t, err)
+		assert.Equal(t, trip.Status, stored.Status, "trip %s must not change", trip.ID)
+	}
+}
+
+func TestCancelTrip(t *testing.T) {
+	ctx := context.Background()
+	b := opstest.NewBackends(t)
+
+	opstest.NewSession(t, b, 1)
+
+	trip := opstest.NewTrip(t, b, 1, startsAt)
+
+	_, err := ops.CancelTrip(ctx, b, trip.ID, "rain")
+	require.ErrorIs(t, err, ops.ErrInvalidTransition)
+
+	stored, err := ops.GetTrip(ctx, b, trip.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TripDraft, stored.Status)
+	assert.Empty(t, stored.CancelReason)
+
+	_, err = ops.SetTripStatus(ctx, b, trip.ID, models.TripPublished)
+	require.NoError(t, err)
+
+	cancelled, err := ops.CancelTrip(ctx, b, trip.ID, "rain")
+	require.NoError(t, err)
+	assert.Equal(t, models.TripCancelled, cancelled.Status)
+	assert.Equal(t, "rain", cancelled.CancelReason)
+	assert.False(t, cancelled.CancelledAt.IsZero())
+}
@@ -3,6 +3,8 @@
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 	log "github.com/obalunenko/logger"
@@ -14,14 +16,21 @@
 // CreateTripParams is a params for CreateTrip function.
 type CreateTripParams struct {
 	Name        string
-	Date        string
 	Description string
-	CreatedBy   int64
+	// StartsAt is a trip start time, its location is stored as the trip timezone.
+	StartsAt  time.Time
+	CreatedBy int64
 }
 
 // CreateTrip creates a new trip.
 func CreateTrip(ctx context.Context, b backends, p CreateTripParams) (*models.Trip, error) {
-	t, err := b.TripsRepository().CreateTrip(ctx, p.Name, p.Date, p.Description, p.CreatedBy)
+	t, err := b.TripsRepository().CreateTrip(ctx, trips.CreateTripParams{
+		Name:        p.Name,
+		Description: p.Description,
+		StartsAt:    p.StartsAt,
+		Timezone:    p.StartsAt.Location().String(),
+		CreatedBy:   p.CreatedBy,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -45,36 +54,105 @@ func GetTrip(ctx context.Context, b backends, id uuid.UUID) (*models.Trip, error
 		return nil, fmt.Errorf("get user by ID: %w", err)
 	}
 
-	return &models.Trip{
-		ID:          t.ID,
-		Name:        t.Name,
-		Date:        t.Date,
-		Description: t.Description,
-		CreatedAt:   t.CreatedAt,
-		UpdatedAt:   t.UpdatedAt,
-		CreatedBy: &models.User{
-			ID:        user.ID,
-			Username:  user.Username,
-			Firstname: user.Firstname,
-			Lastname:  user.Lastname,
-		},
-	}, nil
+	list, err := listParticipants(ctx, b, t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	trip := toModelTrip(ctx, t, &models.User{
+		ID:        user.ID,
+		Username:  user.Username,
+		Firstname: user.Firstname,
+		Lastname:  user.Lastname,
+	})
+
+	trip.Participants = list
+
+	return trip, nil
 }
 
 // UpdateTripParams is a params for UpdateTrip function.
 type UpdateTripParams struct {
 	Name        *string
-	Date        *string
 	Description *string
-	Completed   *bool
+	// StartsAt is a trip start time, its location is stored as the trip timezone.
+	StartsAt *time.Time
+	// ChatID is the chat the trip is announced in.
+	ChatID *int64
+	// MessageID is the announcement message in the chat.
+	MessageID *int
+	// CancelReason is the reason the trip was cancelled for.
+	CancelReason *string
+	// MeetingPoint replaces the trip meeting point, a zero meeting point removes it.
+	MeetingPoint *models.MeetingPoint
+	// MeetingMessageID is the meeting point location message in the chat.
+	MeetingMessageID *int
+	// Track replaces the trip track, a zero track removes it.
+	Track *models.Track
+	// RouteLink replaces the trip route link, a zero link removes it.
+	RouteLink *models.RouteLink
 }
 
 // UpdateTrip updates a trip.
 func UpdateTrip(ctx context.Context, b backends, id uuid.UUID, p UpdateTripParams) (*models.Trip, error) {
+	var timezone *string
+
+	if p.StartsAt != nil {
+		tz := p.StartsAt.Location().String()
+		timezone = &tz
+	}
+
+	var meetingPoint *trips.MeetingPoint
+
+	if mp := p.MeetingPoint; mp != nil {
+		meetingPoint = &trips.MeetingPoint{
+			Label:       mp.Label,
+			HasLocation: mp.HasLocation,
+			Latitude:    mp.Latitude,
+			Longitude:   mp.Longitude,
+		}
+	}
+
+	var track *trips.Track
+
+	if t := p.Track; t != nil {
+		track = &trips.Track{
+			FileID:        t.FileID,
+			FileName:      t.FileName,
+			Distance:      t.Distance,
+			HasElevation:  t.HasElevation,
+			ElevationGain: t.ElevationGain,
+			ElevationLoss: t.ElevationLoss,
+			MaxGradient:   t.MaxGradient,
+			MinLatitude:   t.MinLatitude,
+			MinLongitude:  t.MinLongitude,
+			MaxLatitude:   t.MaxLatitude,
+			MaxLongitude:  t.MaxLongitude,
+		}
+	}
+
+	var routeLink *trips.RouteLink
+
+	if l := p.RouteLink; l != nil {
+		routeLink = &trips.RouteLink{
+			Provider: l.Provider,
+			ID:       l.ID,
+			URL:      l.URL,
+		}
+	}
+
 	err := b.TripsRepository().UpdateTrip(ctx, id, trips.UpdateTripParams{
-		Name:        p.Name,
-		Date:        p.Date,
-		Description: p.Description,
+		Name:             p.Name,
+		Description:      p.Description,
+		StartsAt:         p.StartsAt,
+		Timezone:         timezone,
+		ChatID:           p.ChatID,
+		MessageID:        p.MessageID,
+		CancelReason:     p.CancelReason,
+		MeetingPoint:     meetingPoint,
+		MeetingMessageID: p.MeetingMessageID,
+		Track:            track,
+		RouteLink:        routeLink,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("update trip: %w", err)
@@ -101,7 +179,7 @@ func DeleteTrip(ctx context.Context, b backends, id uuid.UUID) error {
 	return nil
 }
 
-// ListTripsByUser return list of trips by user.
+// ListTripsByUser return list of trips by user, archived trips are skipped.
 func ListTripsByUser(ctx context.Context, b backends, user *models.User) ([]*models.Trip, error) {
 	list, err := b.TripsRepository().ListTripsByUser(ctx, user.ID)
 	if err != nil {
@@ -111,16 +189,125 @@ func ListTripsByUser(ctx context.Context, b backends, user *models.User) ([]*mod
 	result := make([]*models.Trip, 0, len(list))
 
 	for _, t := range list {
-		result = append(result, &models.Trip{
-			ID:          t.ID,
-			Name:        t.Name,
-			Date:        t.Date,
-			Description: t.Description,
-			CreatedAt:   t.CreatedAt,
-			UpdatedAt:   t.UpdatedAt,
-			CreatedBy:   user,
-		})
+		if t.Status == trips.StatusArchived {
+			continue
+		}
+
+		trip := toModelTrip(ctx, t, user)
+
+		trip.Participants, err = listParticipants(ctx, b, t.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, trip)
 	}
 
 	return result, nil
 }
+
+// toModelTrip converts repository trip to model, start time is converted to the trip timezone.
+func toModelTrip(ctx context.Context, t *trips.Trip, createdBy *models.User) *models.Trip {
+	startsAt := t.StartsAt
+
+	if !startsAt.IsZero() {
+		loc, err := time.LoadLocation(t.Timezone)
+		if err != nil {
+			log.WithError(ctx, err).WithFields(log.Fields{
+				"trip_id":  t.ID,
+				"timezone": t.Timezone,
+			}).Warn("Failed to load trip timezone, UTC is used")
+
+			loc = time.UTC
+		}
+
+		startsAt = startsAt.In(loc)
+	}
+
+	var meetingPoint *models.MeetingPoint
+
+	if mp := t.MeetingPoint; mp != (trips.MeetingPoint{}) {
+		meetingPoint = &models.MeetingPoint{
+			Label:       mp.Label,
+			HasLocation: mp.HasLocation,
+			Latitude:    mp.Latitude,
+			Longitude:   mp.Longitude,
+		}
+	}
+
+	var track *models.Track
+
+	if tr := t.Track; tr.FileID != "" {
+		track = &models.Track{
+			FileID:        tr.FileID,
+			FileName:      tr.FileName,
+			Distance:      tr.Distance,
+			HasElevation:  tr.HasElevation,
+			ElevationGain: tr.ElevationGain,
+			ElevationLoss: tr.ElevationLoss,
+			MaxGradient:   tr.MaxGradient,
+			MinLatitude:   tr.MinLatitude,
+			MinLongitude:  tr.MinLongitude,
+			MaxLatitude:   tr.MaxLatitude,
+			MaxLongitude:  tr.MaxLongitude,
+		}
+	}
+
+	var routeLink *models.RouteLink
+
+	if l := t.RouteLink; l.URL != "" {
+		routeLink = &models.RouteLink{
+			Provider: l.Provider,
+			ID:       l.ID,
+			URL:      l.URL,
+		}
+	}
+
+	return &models.Trip{
+		ID:               t.ID,
+		Name:             t.Name,
+		Description:      t.Description,
+		StartsAt:         startsAt,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+		CreatedBy:        createdBy,
+		ChatID:           t.ChatID,
+		MessageID:        t.MessageID,
+		Status:           models.TripStatus(t.Status),
+		PublishedAt:      t.PublishedAt,
+		CancelledAt:      t.CancelledAt,
+		StartedAt:        t.StartedAt,
+		FinishedAt:       t.FinishedAt,
+		ArchivedAt:       t.ArchivedAt,
+		CancelReason:     t.CancelReason,
+		MeetingPoint:     meetingPoint,
+		MeetingMessageID: t.MeetingMessageID,
+		Track:            track,
+		RouteLink:        routeLink,
+	}
+}
+
+// ListUpcomingTrips returns published trips starting after now ordered by start time.
+// Trips without start time are skipped.
+func ListUpcomingTrips(ctx context.Context, b backends, now time.Time) ([]*models.Trip, error) {
+	list, err := ListPublishedTrips(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Trip, 0, len(list))
+
+	for _, t := range list {
+		if t.StartsAt.IsZero() || !t.StartsAt.After(now) {
+			continue
+		}
+
+		result = append(result, t)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].StartsAt.Before(result[j].StartsAt)
+	})
+
+	return result, nil
+}
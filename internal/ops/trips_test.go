@@ -0,0 +1,51 @@
+package ops_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+)
+
+func TestListUpcomingTrips(t *testing.T) {
+	ctx := context.Background()
+	b := opstest.NewBackends(t)
+
+	// Status times are recorded by the repository clock, so the trips are relative to it.
+	now := time.Now().UTC()
+
+	opstest.NewUser(t, b, 1)
+
+	// Trips are created out of start order, the list must be sorted by start time.
+	third := opstest.NewTrip(t, b, 1, now.Add(72*time.Hour), models.TripPublished)
+	first := opstest.NewTrip(t, b, 1, now.Add(time.Hour), models.TripPublished)
+	second := opstest.NewTrip(t, b, 1, now.Add(24*time.Hour), models.TripPublished)
+
+	opstest.NewTrip(t, b, 1, now.Add(-time.Hour), models.TripPublished)
+	opstest.NewTrip(t, b, 1, now, models.TripPublished)
+	opstest.NewTrip(t, b, 1, time.Time{}, models.TripPublished)
+	opstest.NewTrip(t, b, 1, now.Add(time.Hour))
+	opstest.NewTrip(t, b, 1, now.Add(time.Hour), models.TripPublished, models.TripCancelled)
+	opstest.NewTrip(t, b, 1, now.Add(-time.Hour), models.TripPublished, models.TripStarted)
+
+	list, err := ops.ListUpcomingTrips(ctx, b, now)
+	require.NoError(t, err)
+
+	got := make([]uuid.UUID, 0, len(list))
+	for _, trip := range list {
+		got = append(got, trip.ID)
+	}
+
+	assert.Equal(t, []uuid.UUID{first.ID, second.ID, third.ID}, got)
+
+	list, err = ops.ListUpcomingTrips(ctx, b, now.Add(100*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
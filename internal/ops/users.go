@@ -3,6 +3,9 @@
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	log "github.com/obalunenko/logger"
 
@@ -52,3 +55,27 @@ func CreateUser(ctx context.Context, b backends, p CreateUserParams) (*models.Us
 
 	return GetUser(ctx, b, p.UserID)
 }
+
+// ErrUserNotFound is returned when a user can't be found by username.
+var ErrUserNotFound = errors.New("user not found")
+
+// FindUserByUsername returns a user by username, the leading @ and letter case are ignored.
+func FindUserByUsername(ctx context.Context, b backends, username string) (*models.User, error) {
+	username = strings.TrimPrefix(username, "@")
+	if username == "" {
+		return nil, ErrUserNotFound
+	}
+
+	list, err := b.UsersRepository().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+
+	for _, u := range list {
+		if strings.EqualFold(u.Username, username) {
+			return GetUser(ctx, b, u.ID)
+		}
+	}
+
+	return nil, ErrUserNotFound
+}
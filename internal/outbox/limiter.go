@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter is a GCRA rate limiter, which hands out slots in the order they were reserved.
+type limiter struct {
+	mu sync.Mutex
+
+	// interval between requests at the sustained rate.
+	interval time.Duration
+	// burst is how far ahead of the sustained rate requests may go.
+	burst time.Duration
+	// tat is the theoretical arrival time of the next request.
+	tat time.Time
+}
+
+func newLimiter(l Limit, now time.Time) *limiter {
+	interval := l.Period / time.Duration(l.Count)
+
+	return &limiter{
+		interval: interval,
+		burst:    l.Period - interval,
+		tat:      now,
+	}
+}
+
+// reserve takes the next slot and returns how long to wait for it.
+func (l *limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tat.Before(now) {
+		l.tat = now
+	}
+
+	wait := l.tat.Sub(now) - l.burst
+
+	l.tat = l.tat.Add(l.interval)
+
+	return max(wait, 0)
+}
+
+// pause makes requests wait until the given time.
+func (l *limiter) pause(until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if tat := until.Add(l.burst); tat.After(l.tat) {
+		l.tat = tat
+	}
+}
+
+// idle reports whether all slots are free, so the limiter may be dropped.
+func (l *limiter) idle(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return !l.tat.After(now)
+}
@@ -0,0 +1,278 @@
+// Package outbox delivers requests to Telegram within its rate limits.
+//
+// Every request is bound to a chat. Requests wait for a free slot of the global limit and of the chat limit,
+// requests rejected by flood control are repeated after the time Telegram asks to wait,
+// and requests failed with transient errors are repeated with exponential backoff.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mymmrac/telego/telegoapi"
+	log "github.com/obalunenko/logger"
+)
+
+// Limit allows Count requests per Period, requests could be sent in a burst up to Count.
+type Limit struct {
+	Count  int
+	Period time.Duration
+}
+
+func (l Limit) validate() error {
+	if l.Count <= 0 || l.Period <= 0 {
+		return errors.New("count and period must be positive")
+	}
+
+	return nil
+}
+
+// Params contains the parameters for New.
+type Params struct {
+	// Global limits requests to all chats.
+	Global Limit
+	// Group limits requests to a single group chat.
+	Group Limit
+	// Private limits requests to a single private chat.
+	Private Limit
+	// MaxRetries is a maximum number of times a failed request is repeated.
+	MaxRetries int
+	// Backoff is a delay before the first repeat of a request failed with a transient error,
+	// it's doubled for every next repeat.
+	Backoff time.Duration
+	// MaxDelay is the longest delay before a repeat, a request is failed if Telegram asks to wait longer.
+	MaxDelay time.Duration
+}
+
+// Stats contains outbox metrics.
+type Stats struct {
+	// Sent is a total number of delivered requests.
+	Sent uint64
+	// Failed is a total number of requests failed after all retries.
+	Failed uint64
+	// Retried is a total number of repeats.
+	Retried uint64
+	// RateLimited is a total number of requests rejected by Telegram flood control.
+	RateLimited uint64
+}
+
+// maxIdleChats is a number of chat limiters after which limiters of idle chats are dropped.
+const maxIdleChats = 1024
+
+// Outbox delivers requests to Telegram within its rate limits.
+type Outbox struct {
+	params Params
+
+	global *limiter
+
+	mu    sync.Mutex
+	chats map[int64]*limiter
+
+	// wait is replaced in tests to skip delays.
+	wait func(ctx context.Context, d time.Duration) error
+
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+	retried     atomic.Uint64
+	rateLimited atomic.Uint64
+}
+
+// New creates a new outbox.
+func New(p Params) (*Outbox, error) {
+	if err := p.Global.validate(); err != nil {
+		return nil, fmt.Errorf("global limit: %w", err)
+	}
+
+	if err := p.Group.validate(); err != nil {
+		return nil, fmt.Errorf("group limit: %w", err)
+	}
+
+	if err := p.Private.validate(); err != nil {
+		return nil, fmt.Errorf("private limit: %w", err)
+	}
+
+	if p.MaxRetries < 0 {
+		return nil, errors.New("max retries is negative")
+	}
+
+	if p.Backoff <= 0 || p.MaxDelay < p.Backoff {
+		return nil, errors.New("backoff must be positive and not greater than max delay")
+	}
+
+	return &Outbox{
+		params: p,
+		global: newLimiter(p.Global, time.Now()),
+		chats:  make(map[int64]*limiter),
+		wait:   sleep,
+	}, nil
+}
+
+// Send calls fn when the chat and global limits allow it and repeats the call while it fails with
+// a retryable error. The last error is returned when all retries are exhausted.
+// Calls to the same chat get their slots in the order Send was called.
+func (o *Outbox) Send(ctx context.Context, chatID int64, fn func() error) error {
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if err = o.reserve(ctx, chatID); err != nil {
+			break
+		}
+
+		if err = fn(); err == nil {
+			o.sent.Add(1)
+
+			return nil
+		}
+
+		delay, retryable := o.retryDelay(chatID, attempt, err)
+		if !retryable || attempt >= o.params.MaxRetries {
+			break
+		}
+
+		o.retried.Add(1)
+
+		log.WithError(ctx, err).WithFields(log.Fields{
+			"target_chat_id": chatID,
+			"attempt":        attempt + 1,
+			"delay":          delay.String(),
+		}).Warn("Telegram request failed, retrying")
+
+		if err = o.wait(ctx, delay); err != nil {
+			break
+		}
+	}
+
+	o.failed.Add(1)
+
+	return err
+}
+
+// Do is Send for calls returning a result, e.g. the sent message.
+func Do[T any](ctx context.Context, o *Outbox, chatID int64, fn func() (T, error)) (T, error) {
+	var res T
+
+	err := o.Send(ctx, chatID, func() error {
+		var err error
+
+		res, err = fn()
+
+		return err
+	})
+
+	return res, err
+}
+
+// Stats returns current outbox metrics.
+func (o *Outbox) Stats() Stats {
+	return Stats{
+		Sent:        o.sent.Load(),
+		Failed:      o.failed.Load(),
+		Retried:     o.retried.Load(),
+		RateLimited: o.rateLimited.Load(),
+	}
+}
+
+// reserve waits for a slot of the chat limit and then for a slot of the global limit.
+func (o *Outbox) reserve(ctx context.Context, chatID int64) error {
+	if err := o.wait(ctx, o.chat(chatID).reserve(time.Now())); err != nil {
+		return err
+	}
+
+	return o.wait(ctx, o.global.reserve(time.Now()))
+}
+
+// chat returns the limiter of the chat, creating it if needed.
+func (o *Outbox) chat(chatID int64) *limiter {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if l, ok := o.chats[chatID]; ok {
+		return l
+	}
+
+	now := time.Now()
+
+	if len(o.chats) >= maxIdleChats {
+		for id, l := range o.chats {
+			if l.idle(now) {
+				delete(o.chats, id)
+			}
+		}
+	}
+
+	limit := o.params.Private
+	// Group and channel IDs are negative.
+	if chatID < 0 {
+		limit = o.params.Group
+	}
+
+	l := newLimiter(limit, now)
+
+	o.chats[chatID] = l
+
+	return l
+}
+
+// retryDelay returns how long to wait before the next attempt besides the limits, false means the error is permanent.
+func (o *Outbox) retryDelay(chatID int64, attempt int, err error) (time.Duration, bool) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	backoff := min(o.params.Backoff<<attempt, o.params.MaxDelay)
+
+	var apiErr *telegoapi.Error
+	if !errors.As(err, &apiErr) {
+		// Request didn't reach Telegram or the response was lost.
+		return backoff, true
+	}
+
+	switch {
+	case apiErr.ErrorCode == tooManyRequests:
+		o.rateLimited.Add(1)
+
+		if apiErr.Parameters == nil || apiErr.Parameters.RetryAfter <= 0 {
+			return backoff, true
+		}
+
+		delay := time.Duration(apiErr.Parameters.RetryAfter) * time.Second
+		if delay > o.params.MaxDelay {
+			return 0, false
+		}
+
+		// The next attempt and other requests to the chat wait for the chat limiter,
+		// as Telegram rejects them anyway.
+		o.chat(chatID).pause(time.Now().Add(delay))
+
+		return 0, true
+	case apiErr.ErrorCode >= serverErrors:
+		return backoff, true
+	default:
+		return 0, false
+	}
+}
+
+const (
+	tooManyRequests = 429
+	serverErrors    = 500
+)
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
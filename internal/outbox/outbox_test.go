@@ -0,0 +1,242 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testParams() Params {
+	return Params{
+		Global:     Limit{Count: 30, Period: time.Second},
+		Group:      Limit{Count: 20, Period: time.Minute},
+		Private:    Limit{Count: 5, Period: 5 * time.Second},
+		MaxRetries: 2,
+		Backoff:    time.Second,
+		MaxDelay:   time.Minute,
+	}
+}
+
+// newTestOutbox creates an outbox, which records delays instead of waiting.
+func newTestOutbox(t *testing.T, p Params) (*Outbox, *[]time.Duration) {
+	t.Helper()
+
+	o, err := New(p)
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		delays []time.Duration
+	)
+
+	o.wait = func(_ context.Context, d time.Duration) error {
+		if d > 0 {
+			mu.Lock()
+			delays = append(delays, d)
+			mu.Unlock()
+		}
+
+		return nil
+	}
+
+	return o, &delays
+}
+
+func apiError(code, retryAfter int) error {
+	err := &telegoapi.Error{ErrorCode: code, Description: "test"}
+
+	if retryAfter > 0 {
+		err.Parameters = &telegoapi.ResponseParameters{RetryAfter: retryAfter}
+	}
+
+	return fmt.Errorf("telego: sendMessage: api: %w", err)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(p *Params)
+	}{
+		{name: "no global limit", modify: func(p *Params) { p.Global = Limit{} }},
+		{name: "no group period", modify: func(p *Params) { p.Group.Period = 0 }},
+		{name: "no private count", modify: func(p *Params) { p.Private.Count = 0 }},
+		{name: "negative retries", modify: func(p *Params) { p.MaxRetries = -1 }},
+		{name: "no backoff", modify: func(p *Params) { p.Backoff = 0 }},
+		{name: "max delay less than backoff", modify: func(p *Params) { p.MaxDelay = time.Millisecond }},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testParams()
+			tt.modify(&p)
+
+			_, err := New(p)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestOutbox_Retry(t *testing.T) {
+	testCases := []struct {
+		name       string
+		errs       []error
+		wantErr    bool
+		wantCalls  int
+		wantDelays []time.Duration
+		wantStats  Stats
+	}{
+		{
+			name:      "delivered",
+			errs:      nil,
+			wantCalls: 1,
+			wantStats: Stats{Sent: 1},
+		},
+		{
+			name:       "flood control",
+			errs:       []error{apiError(429, 7)},
+			wantCalls:  2,
+			wantDelays: []time.Duration{7 * time.Second},
+			wantStats:  Stats{Sent: 1, Retried: 1, RateLimited: 1},
+		},
+		{
+			name:       "flood control without retry after",
+			errs:       []error{apiError(429, 0)},
+			wantCalls:  2,
+			wantDelays: []time.Duration{time.Second},
+			wantStats:  Stats{Sent: 1, Retried: 1, RateLimited: 1},
+		},
+		{
+			name:      "flood control wait is too long",
+			errs:      []error{apiError(429, 3600)},
+			wantErr:   true,
+			wantCalls: 1,
+			wantStats: Stats{Failed: 1, RateLimited: 1},
+		},
+		{
+			name:       "transient errors backoff",
+			errs:       []error{apiError(502, 0), errors.New("connection reset")},
+			wantCalls:  3,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second},
+			wantStats:  Stats{Sent: 1, Retried: 2},
+		},
+		{
+			name:       "retries exhausted",
+			errs:       []error{apiError(500, 0), apiError(500, 0), apiError(500, 0)},
+			wantErr:    true,
+			wantCalls:  3,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second},
+			wantStats:  Stats{Failed: 1, Retried: 2},
+		},
+		{
+			name:      "permanent error",
+			errs:      []error{apiError(400, 0)},
+			wantErr:   true,
+			wantCalls: 1,
+			wantStats: Stats{Failed: 1},
+		},
+		{
+			name:      "canceled",
+			errs:      []error{context.Canceled},
+			wantErr:   true,
+			wantCalls: 1,
+			wantStats: Stats{Failed: 1},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			o, delays := newTestOutbox(t, testParams())
+
+			var calls int
+
+			got, err := Do(context.Background(), o, 1, func() (int, error) {
+				calls++
+
+				if calls <= len(tt.errs) {
+					return 0, tt.errs[calls-1]
+				}
+
+				return 42, nil
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 42, got)
+			}
+
+			assert.Equal(t, tt.wantCalls, calls)
+
+			require.Len(t, *delays, len(tt.wantDelays))
+
+			for i, want := range tt.wantDelays {
+				assert.InDelta(t, want, (*delays)[i], float64(100*time.Millisecond))
+			}
+
+			assert.Equal(t, tt.wantStats, o.Stats())
+		})
+	}
+}
+
+func TestOutbox_FloodControlPausesChat(t *testing.T) {
+	p := testParams()
+	p.Private = Limit{Count: 100, Period: time.Second}
+
+	o, delays := newTestOutbox(t, p)
+
+	err := o.Send(context.Background(), 1, func() error {
+		if len(*delays) == 0 {
+			return apiError(429, 5)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, *delays, 1)
+
+	// Other requests to the chat wait for the flood control too, other chats are not affected.
+	assert.Greater(t, o.chat(1).reserve(time.Now()), 4*time.Second)
+	assert.Zero(t, o.chat(2).reserve(time.Now()))
+}
+
+func TestOutbox_Limits(t *testing.T) {
+	p := testParams()
+	p.Global = Limit{Count: 3, Period: 300 * time.Millisecond}
+	p.Group = Limit{Count: 2, Period: time.Minute}
+
+	o, delays := newTestOutbox(t, p)
+
+	send := func(chatID int64) {
+		require.NoError(t, o.Send(context.Background(), chatID, func() error { return nil }))
+	}
+
+	// Burst of the global limit.
+	send(1)
+	send(2)
+	send(3)
+	assert.Empty(t, *delays)
+
+	send(4)
+	require.Len(t, *delays, 1)
+	assert.InDelta(t, 100*time.Millisecond, (*delays)[0], float64(20*time.Millisecond))
+
+	// Burst of the group limit, then the group waits for the sustained rate.
+	*delays = nil
+
+	o.global = newLimiter(Limit{Count: 100, Period: time.Second}, time.Now())
+
+	send(-100)
+	send(-100)
+	assert.Empty(t, *delays)
+
+	send(-100)
+	require.Len(t, *delays, 1)
+	assert.InDelta(t, 30*time.Second, (*delays)[0], float64(time.Second))
+}
@@ -0,0 +1,207 @@
+// Package permissions checks who may announce trips in group chats.
+package permissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+)
+
+// Policy defines who may announce trips in a group.
+type Policy string
+
+const (
+	// PolicyAnyone allows any group member to announce trips.
+	PolicyAnyone Policy = "anyone"
+	// PolicyAdmins allows only group administrators to announce trips.
+	PolicyAdmins Policy = "admins"
+	// PolicyLeaders allows only approved ride leaders and group administrators to announce trips.
+	PolicyLeaders Policy = "leaders"
+)
+
+// DefaultPolicy is a policy of groups that didn't configure one.
+const DefaultPolicy = PolicyAdmins
+
+// ErrUnknownPolicy is returned when policy is not supported.
+var ErrUnknownPolicy = errors.New("unknown policy")
+
+// ParsePolicy parses a policy name.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyAnyone, PolicyAdmins, PolicyLeaders:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownPolicy, s)
+	}
+}
+
+// Policies returns all supported policies.
+func Policies() []Policy {
+	return []Policy{PolicyAnyone, PolicyAdmins, PolicyLeaders}
+}
+
+// Rights are the administrator rights of a user in a chat.
+type Rights struct {
+	// Admin is true for the chat owner and administrators.
+	Admin bool
+	// CanPin is true if the user may pin messages.
+	CanPin bool
+}
+
+// AdminsGetter returns chat administrators, it is implemented by the telegram bot client.
+type AdminsGetter interface {
+	GetChatAdministrators(params *tgbotapi.GetChatAdministratorsParams) ([]tgbotapi.ChatMember, error)
+}
+
+// Checker resolves user rights in chats, chat administrators are cached for TTL.
+// Concurrent lookups of the same chat share one request, lookups of other chats don't wait for it.
+type Checker struct {
+	api AdminsGetter
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	cache    map[int64]cacheEntry
+	inflight map[int64]*fetch
+}
+
+type cacheEntry struct {
+	admins    map[int64]Rights
+	expiresAt time.Time
+}
+
+// fetch is a request of chat administrators in progress, done is closed once it completes.
+type fetch struct {
+	done   chan struct{}
+	admins map[int64]Rights
+	err    error
+}
+
+// NewChecker creates a new Checker.
+func NewChecker(api AdminsGetter, ttl time.Duration) *Checker {
+	return &Checker{
+		api:      api,
+		ttl:      ttl,
+		now:      time.Now,
+		mu:       sync.Mutex{},
+		cache:    make(map[int64]cacheEntry),
+		inflight: make(map[int64]*fetch),
+	}
+}
+
+// Rights returns the user rights in the chat.
+func (c *Checker) Rights(ctx context.Context, chatID, userID int64) (Rights, error) {
+	admins, err := c.admins(ctx, chatID)
+	if err != nil {
+		return Rights{}, err
+	}
+
+	return admins[userID], nil
+}
+
+// Invalidate drops cached administrators of the chat, e.g. when chat membership changes.
+// A request in progress is not cached when it completes.
+func (c *Checker) Invalidate(chatID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.cache, chatID)
+	delete(c.inflight, chatID)
+}
+
+// admins returns cached administrators of the chat or fetches them.
+// The request is made without holding the lock and outlives the caller if ctx is done first,
+// so its result is still cached for others.
+func (c *Checker) admins(ctx context.Context, chatID int64) (map[int64]Rights, error) {
+	c.mu.Lock()
+
+	if e, ok := c.cache[chatID]; ok && c.now().Before(e.expiresAt) {
+		c.mu.Unlock()
+
+		return e.admins, nil
+	}
+
+	f, ok := c.inflight[chatID]
+	if !ok {
+		f = &fetch{done: make(chan struct{})}
+		c.inflight[chatID] = f
+
+		go c.fetch(context.WithoutCancel(ctx), chatID, f)
+	}
+
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("get chat administrators: %w", ctx.Err())
+	case <-f.done:
+		return f.admins, f.err
+	}
+}
+
+func (c *Checker) fetch(ctx context.Context, chatID int64, f *fetch) {
+	defer close(f.done)
+
+	list, err := c.api.GetChatAdministrators(&tgbotapi.GetChatAdministratorsParams{
+		ChatID: tu.ID(chatID),
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.inflight[chatID] == f
+	if current {
+		delete(c.inflight, chatID)
+	}
+
+	if err != nil {
+		f.err = fmt.Errorf("get chat administrators: %w", err)
+
+		return
+	}
+
+	admins := make(map[int64]Rights, len(list))
+
+	for _, m := range list {
+		switch m := m.(type) {
+		case *tgbotapi.ChatMemberOwner:
+			admins[m.User.ID] = Rights{Admin: true, CanPin: true}
+		case *tgbotapi.ChatMemberAdministrator:
+			admins[m.User.ID] = Rights{Admin: true, CanPin: m.CanPinMessages}
+		}
+	}
+
+	f.admins = admins
+
+	if current {
+		c.cache[chatID] = cacheEntry{
+			admins:    admins,
+			expiresAt: c.now().Add(c.ttl),
+		}
+	}
+
+	log.WithFields(ctx, log.Fields{
+		"target_chat_id": chatID,
+		"admins":         len(admins),
+	}).Debug("Chat administrators fetched")
+}
+
+// CanAnnounce reports whether a user with the rights may announce trips in a group with the policy.
+func CanAnnounce(policy Policy, rights Rights, leader bool) bool {
+	switch policy {
+	case PolicyAnyone:
+		return true
+	case PolicyAdmins:
+		return rights.Admin
+	case PolicyLeaders:
+		return rights.Admin || leader
+	default:
+		return false
+	}
+}
@@ -0,0 +1,199 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAdminsGetter struct {
+	calls int
+	err   error
+}
+
+func (f *fakeAdminsGetter) GetChatAdministrators(_ *tgbotapi.GetChatAdministratorsParams) ([]tgbotapi.ChatMember, error) {
+	f.calls++
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return []tgbotapi.ChatMember{
+		&tgbotapi.ChatMemberOwner{User: tgbotapi.User{ID: 1}},
+		&tgbotapi.ChatMemberAdministrator{User: tgbotapi.User{ID: 2}, CanPinMessages: true},
+		&tgbotapi.ChatMemberAdministrator{User: tgbotapi.User{ID: 3}},
+	}, nil
+}
+
+func TestChecker_Rights(t *testing.T) {
+	ctx := context.Background()
+
+	api := &fakeAdminsGetter{}
+	c := NewChecker(api, time.Minute)
+
+	now := time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	tests := []struct {
+		userID int64
+		want   Rights
+	}{
+		{userID: 1, want: Rights{Admin: true, CanPin: true}},
+		{userID: 2, want: Rights{Admin: true, CanPin: true}},
+		{userID: 3, want: Rights{Admin: true, CanPin: false}},
+		{userID: 4, want: Rights{}},
+	}
+
+	for _, tt := range tests {
+		got, err := c.Rights(ctx, -100, tt.userID)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "user %d", tt.userID)
+	}
+
+	assert.Equal(t, 1, api.calls, "administrators must be cached")
+
+	now = now.Add(time.Minute)
+
+	_, err := c.Rights(ctx, -100, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, api.calls, "cache must expire")
+
+	c.Invalidate(-100)
+
+	_, err = c.Rights(ctx, -100, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, api.calls, "cache must be invalidated")
+
+	_, err = c.Rights(ctx, -200, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, api.calls, "cache is per chat")
+}
+
+func TestChecker_RightsError(t *testing.T) {
+	api := &fakeAdminsGetter{err: errors.New("chat not found")}
+	c := NewChecker(api, time.Minute)
+
+	_, err := c.Rights(context.Background(), -100, 1)
+	require.Error(t, err)
+
+	_, err = c.Rights(context.Background(), -100, 1)
+	require.Error(t, err)
+	assert.Equal(t, 2, api.calls, "errors must not be cached")
+}
+
+// blockingAdminsGetter holds requests of the chat until it's released.
+type blockingAdminsGetter struct {
+	blocked int64
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (f *blockingAdminsGetter) GetChatAdministrators(
+	params *tgbotapi.GetChatAdministratorsParams,
+) ([]tgbotapi.ChatMember, error) {
+	f.calls.Add(1)
+
+	if params.ChatID.ID == f.blocked {
+		<-f.release
+	}
+
+	return []tgbotapi.ChatMember{
+		&tgbotapi.ChatMemberOwner{User: tgbotapi.User{ID: 1}},
+	}, nil
+}
+
+func TestChecker_RightsConcurrent(t *testing.T) {
+	api := &blockingAdminsGetter{blocked: -100, release: make(chan struct{})}
+	c := NewChecker(api, time.Minute)
+
+	const waiters = 5
+
+	var wg sync.WaitGroup
+
+	results := make(chan Rights, waiters)
+
+	for range waiters {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			got, err := c.Rights(context.Background(), -100, 1)
+			assert.NoError(t, err)
+
+			results <- got
+		}()
+	}
+
+	// Other chats are not blocked by the request in progress.
+	got, err := c.Rights(context.Background(), -200, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Rights{Admin: true, CanPin: true}, got)
+
+	// Callers give up when their context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Rights(ctx, -100, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(api.release)
+	wg.Wait()
+	close(results)
+
+	for got := range results {
+		assert.Equal(t, Rights{Admin: true, CanPin: true}, got)
+	}
+
+	assert.Equal(t, int32(2), api.calls.Load(), "concurrent requests of the chat must be shared")
+
+	_, err = c.Rights(context.Background(), -100, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), api.calls.Load(), "shared request must be cached")
+}
+
+func TestCanAnnounce(t *testing.T) {
+	admin := Rights{Admin: true}
+	member := Rights{}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		rights Rights
+		leader bool
+		want   bool
+	}{
+		{name: "anyone: member", policy: PolicyAnyone, rights: member, want: true},
+		{name: "admins: admin", policy: PolicyAdmins, rights: admin, want: true},
+		{name: "admins: member", policy: PolicyAdmins, rights: member, want: false},
+		{name: "admins: leader", policy: PolicyAdmins, rights: member, leader: true, want: false},
+		{name: "leaders: leader", policy: PolicyLeaders, rights: member, leader: true, want: true},
+		{name: "leaders: admin", policy: PolicyLeaders, rights: admin, want: true},
+		{name: "leaders: member", policy: PolicyLeaders, rights: member, want: false},
+		{name: "unknown policy", policy: Policy("unknown"), rights: admin, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CanAnnounce(tt.policy, tt.rights, tt.leader))
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, p := range Policies() {
+		got, err := ParsePolicy(string(p))
+		require.NoError(t, err)
+		assert.Equal(t, p, got)
+	}
+
+	_, err := ParsePolicy("everyone")
+	assert.ErrorIs(t, err, ErrUnknownPolicy)
+}
@@ -0,0 +1,249 @@
+// Package chats provides a repository for group chats the bot is added to.
+package chats
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a chat is not found.
+	ErrNotFound = errors.New("chat not found")
+	// ErrLeaderAlreadyExists is returned when a user is already a leader in a chat.
+	ErrLeaderAlreadyExists = errors.New("leader already exists")
+	// ErrLeaderNotFound is returned when a user is not a leader in a chat.
+	ErrLeaderNotFound = errors.New("leader not found")
+)
+
+// defaultPolicy is an announce policy of a new chat.
+const defaultPolicy = "admins"
+
+// Repository provides access to the chat registry.
+type Repository interface {
+	// SaveChat creates a chat or updates it if it already exists, creation time is preserved.
+	SaveChat(ctx context.Context, params SaveChatParams) (*Chat, error)
+	// GetChat returns a chat by ID.
+	GetChat(ctx context.Context, id int64) (*Chat, error)
+	// ListActiveChats returns chats the bot is a member of ordered by title.
+	ListActiveChats(ctx context.Context) ([]*Chat, error)
+	// SetPolicy sets the chat announce policy.
+	SetPolicy(ctx context.Context, id int64, policy string) error
+	// AddLeader approves a user as a ride leader in the chat.
+	AddLeader(ctx context.Context, chatID, userID, addedBy int64) (*Leader, error)
+	// GetLeader returns a ride leader of the chat.
+	GetLeader(ctx context.Context, chatID, userID int64) (*Leader, error)
+	// RemoveLeader revokes the ride leader approval.
+	RemoveLeader(ctx context.Context, chatID, userID int64) error
+	// ListLeaders returns ride leaders of the chat ordered by approval time.
+	ListLeaders(ctx context.Context, chatID int64) ([]*Leader, error)
+}
+
+// SaveChatParams contains the parameters for SaveChat.
+type SaveChatParams struct {
+	ID    int64
+	Title string
+	Type  string
+	// Active is true while the bot is a member of the chat.
+	Active bool
+	// AddedBy is an ID of the user that changed the bot membership last.
+	AddedBy int64
+}
+
+// Chat represents a group chat.
+type Chat struct {
+	ID        int64     `db:"id"`
+	Title     string    `db:"title"`
+	Type      string    `db:"type"`
+	Active    bool      `db:"active"`
+	AddedBy   int64     `db:"added_by"`
+	Policy    string    `db:"announce_policy"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Leader represents a user approved to announce trips in a chat.
+type Leader struct {
+	ChatID  int64     `db:"chat_id"`
+	UserID  int64     `db:"user_id"`
+	AddedBy int64     `db:"added_by"`
+	AddedAt time.Time `db:"added_at"`
+}
+
+type leaderKey struct {
+	chatID int64
+	userID int64
+}
+
+// inMemoryRepository is an in-memory repository for chats.
+type inMemoryRepository struct {
+	sync.RWMutex
+
+	chats   map[int64]Chat
+	leaders map[leaderKey]Leader
+}
+
+// NewInMemory creates a new in-memory repository for chats.
+func NewInMemory() Repository {
+	return &inMemoryRepository{
+		RWMutex: sync.RWMutex{},
+		chats:   make(map[int64]Chat),
+		leaders: make(map[leaderKey]Leader),
+	}
+}
+
+func (i *inMemoryRepository) SaveChat(_ context.Context, params SaveChatParams) (*Chat, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	now := time.Now().UTC()
+
+	c, ok := i.chats[params.ID]
+	if !ok {
+		c = Chat{
+			ID:        params.ID,
+			Policy:    defaultPolicy,
+			CreatedAt: now,
+		}
+	}
+
+	c.Title = params.Title
+	c.Type = params.Type
+	c.Active = params.Active
+	c.AddedBy = params.AddedBy
+	c.UpdatedAt = now
+
+	i.chats[c.ID] = c
+
+	return &c, nil
+}
+
+func (i *inMemoryRepository) GetChat(_ context.Context, id int64) (*Chat, error) {
+	i.RLock()
+	defer i.RUnlock()
+
+	c, ok := i.chats[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &c, nil
+}
+
+func (i *inMemoryRepository) ListActiveChats(_ context.Context) ([]*Chat, error) {
+	i.RLock()
+	defer i.RUnlock()
+
+	list := make([]*Chat, 0, len(i.chats))
+
+	for _, c := range i.chats {
+		if !c.Active {
+			continue
+		}
+
+		list = append(list, &c)
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		if list[a].Title != list[b].Title {
+			return list[a].Title < list[b].Title
+		}
+
+		return list[a].ID < list[b].ID
+	})
+
+	return list, nil
+}
+
+func (i *inMemoryRepository) SetPolicy(_ context.Context, id int64, policy string) error {
+	i.Lock()
+	defer i.Unlock()
+
+	c, ok := i.chats[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	c.Policy = policy
+	c.UpdatedAt = time.Now().UTC()
+
+	i.chats[id] = c
+
+	return nil
+}
+
+func (i *inMemoryRepository) AddLeader(_ context.Context, chatID, userID, addedBy int64) (*Leader, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	k := leaderKey{chatID: chatID, userID: userID}
+
+	if _, ok := i.leaders[k]; ok {
+		return nil, ErrLeaderAlreadyExists
+	}
+
+	l := Leader{
+		ChatID:  chatID,
+		UserID:  userID,
+		AddedBy: addedBy,
+		AddedAt: time.Now().UTC(),
+	}
+
+	i.leaders[k] = l
+
+	return &l, nil
+}
+
+func (i *inMemoryRepository) GetLeader(_ context.Context, chatID, userID int64) (*Leader, error) {
+	i.RLock()
+	defer i.RUnlock()
+
+	l, ok := i.leaders[leaderKey{chatID: chatID, userID: userID}]
+	if !ok {
+		return nil, ErrLeaderNotFound
+	}
+
+	return &l, nil
+}
+
+func (i *inMemoryRepository) RemoveLeader(_ context.Context, chatID, userID int64) error {
+	i.Lock()
+	defer i.Unlock()
+
+	k := leaderKey{chatID: chatID, userID: userID}
+
+	if _, ok := i.leaders[k]; !ok {
+		return ErrLeaderNotFound
+	}
+
+	delete(i.leaders, k)
+
+	return nil
+}
+
+func (i *inMemoryRepository) ListLeaders(_ context.Context, chatID int64) ([]*Leader, error) {
+	i.RLock()
+	defer i.RUnlock()
+
+	list := make([]*Leader, 0)
+
+	for _, l := range i.leaders {
+		if l.ChatID != chatID {
+			continue
+		}
+
+		list = append(list, &l)
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		if !list[a].AddedAt.Equal(list[b].AddedAt) {
+			return list[a].AddedAt.Before(list[b].AddedAt)
+		}
+
+		return list[a].UserID < list[b].UserID
+	})
+
+	return list, nil
+}
@@ -0,0 +1,20 @@
+package chats_test
+
+import (
+	"testing"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/repotest"
+)
+
+func TestInMemory(t *testing.T) {
+	repotest.RunChats(t, func(_ *testing.T) chats.Repository {
+		return chats.NewInMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunChats(t, func(t *testing.T) chats.Repository {
+		return chats.NewSQLite(repotest.NewSQLiteDB(t))
+	})
+}
@@ -0,0 +1,214 @@
+package chats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// sqliteRepository is a SQLite repository for chats.
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a new SQLite repository for chats.
+func NewSQLite(db *sql.DB) Repository {
+	return &sqliteRepository{
+		db: db,
+	}
+}
+
+const (
+	selectChats   = `SELECT id, title, type, active, added_by, announce_policy, created_at, updated_at FROM chats`
+	selectLeaders = `SELECT chat_id, user_id, added_by, added_at FROM chat_leaders`
+)
+
+func (s *sqliteRepository) SaveChat(ctx context.Context, params SaveChatParams) (*Chat, error) {
+	now := time.Now().UTC()
+
+	const query = `
+		INSERT INTO chats (id, title, type, active, added_by, announce_policy, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			title      = excluded.title,
+			type       = excluded.type,
+			active     = excluded.active,
+			added_by   = excluded.added_by,
+			updated_at = excluded.updated_at`
+
+	_, err := s.db.ExecContext(ctx, query,
+		params.ID, params.Title, params.Type, params.Active, params.AddedBy, defaultPolicy, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save chat: %w", err)
+	}
+
+	return s.GetChat(ctx, params.ID)
+}
+
+func (s *sqliteRepository) GetChat(ctx context.Context, id int64) (*Chat, error) {
+	c, err := scanChat(s.db.QueryRowContext(ctx, selectChats+` WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get chat: %w", err)
+	}
+
+	return c, nil
+}
+
+func (s *sqliteRepository) ListActiveChats(ctx context.Context) ([]*Chat, error) {
+	rows, err := s.db.QueryContext(ctx, selectChats+` WHERE active ORDER BY title, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chats: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	list := make([]*Chat, 0)
+
+	for rows.Next() {
+		c, err := scanChat(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chat: %w", err)
+		}
+
+		list = append(list, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chats: %w", err)
+	}
+
+	return list, nil
+}
+
+func (s *sqliteRepository) SetPolicy(ctx context.Context, id int64, policy string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE chats SET announce_policy = ?, updated_at = ? WHERE id = ?`,
+		policy, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update chat policy: %w", err)
+	}
+
+	return checkAffected(res, ErrNotFound)
+}
+
+func (s *sqliteRepository) AddLeader(ctx context.Context, chatID, userID, addedBy int64) (*Leader, error) {
+	l := Leader{
+		ChatID:  chatID,
+		UserID:  userID,
+		AddedBy: addedBy,
+		AddedAt: time.Now().UTC(),
+	}
+
+	const query = `
+		INSERT INTO chat_leaders (chat_id, user_id, added_by, added_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (chat_id, user_id) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query, l.ChatID, l.UserID, l.AddedBy, l.AddedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert leader: %w", err)
+	}
+
+	if err = checkAffected(res, ErrLeaderAlreadyExists); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (s *sqliteRepository) GetLeader(ctx context.Context, chatID, userID int64) (*Leader, error) {
+	row := s.db.QueryRowContext(ctx, selectLeaders+` WHERE chat_id = ? AND user_id = ?`, chatID, userID)
+
+	l, err := scanLeader(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLeaderNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get leader: %w", err)
+	}
+
+	return l, nil
+}
+
+func (s *sqliteRepository) RemoveLeader(ctx context.Context, chatID, userID int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM chat_leaders WHERE chat_id = ? AND user_id = ?`, chatID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete leader: %w", err)
+	}
+
+	return checkAffected(res, ErrLeaderNotFound)
+}
+
+func (s *sqliteRepository) ListLeaders(ctx context.Context, chatID int64) ([]*Leader, error) {
+	rows, err := s.db.QueryContext(ctx, selectLeaders+` WHERE chat_id = ? ORDER BY added_at, user_id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leaders: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	list := make([]*Leader, 0)
+
+	for rows.Next() {
+		l, err := scanLeader(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan leader: %w", err)
+		}
+
+		list = append(list, l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate leaders: %w", err)
+	}
+
+	return list, nil
+}
+
+// checkAffected returns errNone if no rows were affected.
+func checkAffected(res sql.Result, errNone error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return errNone
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanChat(row scanner) (*Chat, error) {
+	var c Chat
+
+	if err := row.Scan(&c.ID, &c.Title, &c.Type, &c.Active, &c.AddedBy, &c.Policy, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func scanLeader(row scanner) (*Leader, error) {
+	var l Leader
+
+	if err := row.Scan(&l.ChatID, &l.UserID, &l.AddedBy, &l.AddedAt); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
@@ -0,0 +1,48 @@
+// Package database provides access to the SQLite database used by repositories.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite" // register sqlite driver.
+)
+
+const driverName = "sqlite"
+
+// Open opens SQLite database file at given path.
+// Schema is managed by migrations package and is not applied here.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, errors.New("database path is empty")
+	}
+
+	db, err := sql.Open(driverName, dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows only one writer at a time, so serialize access to avoid SQLITE_BUSY errors.
+	db.SetMaxOpenConns(1)
+
+	if err = db.PingContext(ctx); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to ping database: %w", err), db.Close())
+	}
+
+	return db, nil
+}
+
+// dsn builds data source name with pragmas.
+func dsn(path string) string {
+	q := url.Values{}
+
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", "foreign_keys(ON)")
+	q.Set("_time_format", "sqlite")
+
+	return fmt.Sprintf("file:%s?%s", path, q.Encode())
+}
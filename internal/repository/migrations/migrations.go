@@ -0,0 +1,314 @@
+// Package migrations provides versioned schema migrations for the SQLite database.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/obalunenko/logger"
+)
+
+//go:embed sql/*.sql
+var migrationsFS embed.FS
+
+// ErrNoMigrations is returned when there is nothing to roll back.
+var ErrNoMigrations = errors.New("no applied migrations")
+
+const createVersionsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations
+	(
+	    version    INTEGER PRIMARY KEY,
+	    name       TEXT     NOT NULL,
+	    applied_at DATETIME NOT NULL
+	)`
+
+// Migration is a single schema change with up and down steps.
+type Migration struct {
+	Version uint
+	Name    string
+	up      string
+	down    string
+}
+
+// Status describes a migration and whether it is applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies migrations to the database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New creates a new Migrator with embedded migrations.
+func New(db *sql.DB) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	list, err := load(migrationsFS, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: list,
+	}, nil
+}
+
+// Up applies all pending migrations in order and returns applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+
+		err = m.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mg.up); err != nil {
+				return fmt.Errorf("failed to execute: %w", err)
+			}
+
+			const query = `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`
+
+			if _, err := tx.ExecContext(ctx, query, mg.Version, mg.Name, time.Now()); err != nil {
+				return fmt.Errorf("failed to record version: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s up: %w", mg.Version, mg.Name, err)
+		}
+
+		log.WithFields(ctx, log.Fields{
+			"version": mg.Version,
+			"name":    mg.Name,
+		}).Info("Migration applied")
+
+		done = append(done, mg)
+	}
+
+	return done, nil
+}
+
+// Down rolls back the latest applied migration and returns it.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+
+		if _, ok := applied[mg.Version]; !ok {
+			continue
+		}
+
+		err = m.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mg.down); err != nil {
+				return fmt.Errorf("failed to execute: %w", err)
+			}
+
+			if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, mg.Version); err != nil {
+				return fmt.Errorf("failed to delete version: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("migration %04d_%s down: %w", mg.Version, mg.Name, err)
+		}
+
+		log.WithFields(ctx, log.Fields{
+			"version": mg.Version,
+			"name":    mg.Name,
+		}).Info("Migration rolled back")
+
+		return &mg, nil
+	}
+
+	return nil, ErrNoMigrations
+}
+
+// Status returns all known migrations with their applied state.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, 0, len(m.migrations))
+
+	for _, mg := range m.migrations {
+		at, ok := applied[mg.Version]
+
+		list = append(list, Status{
+			Migration: mg,
+			Applied:   ok,
+			AppliedAt: at,
+		})
+	}
+
+	return list, nil
+}
+
+// Version returns the latest applied migration version, 0 if none applied.
+func (m *Migrator) Version(ctx context.Context) (uint, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var v uint
+
+	for version := range applied {
+		v = max(v, version)
+	}
+
+	return v, nil
+}
+
+// applied returns applied versions with their apply time.
+func (m *Migrator) applied(ctx context.Context) (map[uint]time.Time, error) {
+	if _, err := m.db.ExecContext(ctx, createVersionsTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	applied := make(map[uint]time.Time)
+
+	for rows.Next() {
+		var (
+			version uint
+			at      time.Time
+		)
+
+		if err = rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+
+		applied[version] = at
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+var fileNameRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// load reads migrations from the directory of given fs.
+// Files must be named as <version>_<name>.up.sql and <version>_<name>.down.sql.
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations dir: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		matches := fileNameRe.FindStringSubmatch(e.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", e.Name())
+		}
+
+		version, err := strconv.ParseUint(matches[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", e.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", e.Name(), err)
+		}
+
+		mg, ok := byVersion[uint(version)]
+		if !ok {
+			mg = &Migration{
+				Version: uint(version),
+				Name:    matches[2],
+			}
+
+			byVersion[uint(version)] = mg
+		}
+
+		if mg.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has different names: %q and %q", version, mg.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			mg.up = string(content)
+		} else {
+			mg.down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+
+	for _, mg := range byVersion {
+		if mg.up == "" || mg.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down steps", mg.Version, mg.Name)
+		}
+
+		list = append(list, *mg)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return list, nil
+}
@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fs      fstest.MapFS
+		want    []uint
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "sorted by version",
+			fs: fstest.MapFS{
+				"sql/0002_second.up.sql":   {Data: []byte("up2")},
+				"sql/0002_second.down.sql": {Data: []byte("down2")},
+				"sql/0001_first.up.sql":    {Data: []byte("up1")},
+				"sql/0001_first.down.sql":  {Data: []byte("down1")},
+			},
+			want:    []uint{1, 2},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "missing down step",
+			fs: fstest.MapFS{
+				"sql/0001_first.up.sql": {Data: []byte("up1")},
+			},
+			want:    nil,
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid file name",
+			fs: fstest.MapFS{
+				"sql/first.sql": {Data: []byte("up1")},
+			},
+			want:    nil,
+			wantErr: assert.Error,
+		},
+		{
+			name: "names mismatch",
+			fs: fstest.MapFS{
+				"sql/0001_first.up.sql":   {Data: []byte("up1")},
+				"sql/0001_other.down.sql": {Data: []byte("down1")},
+			},
+			want:    nil,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := load(tc.fs, "sql")
+			if !tc.wantErr(t, err) {
+				return
+			}
+
+			versions := make([]uint, 0, len(got))
+
+			for _, m := range got {
+				versions = append(versions, m.Version)
+			}
+
+			if tc.want == nil {
+				assert.Empty(t, versions)
+
+				return
+			}
+
+			assert.Equal(t, tc.want, versions)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := load(migrationsFS, "sql")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, list)
+}
@@ -0,0 +1,65 @@
+package migrations_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/database"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/migrations"
+)
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := database.Open(ctx, filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	m, err := migrations.New(db)
+	require.NoError(t, err)
+
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, status)
+
+	for _, s := range status {
+		assert.False(t, s.Applied, "migration %d", s.Version)
+	}
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(status))
+
+	version, err := m.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, status[len(status)-1].Version, version)
+
+	// Second run is a no-op.
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	for i := len(status) - 1; i >= 0; i-- {
+		mg, err := m.Down(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, status[i].Version, mg.Version)
+	}
+
+	_, err = m.Down(ctx)
+	require.ErrorIs(t, err, migrations.ErrNoMigrations)
+
+	version, err = m.Version(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, version)
+
+	// Schema can be recreated after full rollback.
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+}
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS trips_created_by_idx;
+
+DROP TABLE IF EXISTS trips;
+
+DROP TABLE IF EXISTS sessions;
+
+DROP TABLE IF EXISTS states;
+
+DROP TABLE IF EXISTS users;
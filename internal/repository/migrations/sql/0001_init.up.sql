@@ -0,0 +1,41 @@
+-- Tables are created only if missing, so databases created before migrations were introduced are adopted as is.
+
+CREATE TABLE IF NOT EXISTS users
+(
+    id        INTEGER PRIMARY KEY,
+    username  TEXT NOT NULL DEFAULT '',
+    firstname TEXT NOT NULL DEFAULT '',
+    lastname  TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS states
+(
+    id      TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE,
+    trip_id TEXT,
+    command TEXT    NOT NULL DEFAULT '',
+    step    INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id       TEXT PRIMARY KEY,
+    user_id  INTEGER NOT NULL UNIQUE,
+    chat_id  INTEGER NOT NULL,
+    state_id TEXT
+);
+
+CREATE TABLE IF NOT EXISTS trips
+(
+    id          TEXT PRIMARY KEY,
+    name        TEXT     NOT NULL DEFAULT '',
+    date        TEXT     NOT NULL DEFAULT '',
+    description TEXT     NOT NULL DEFAULT '',
+    created_at  DATETIME NOT NULL,
+    updated_at  DATETIME NOT NULL,
+    deleted_at  DATETIME,
+    created_by  INTEGER  NOT NULL,
+    completed   BOOLEAN  NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS trips_created_by_idx ON trips (created_by);
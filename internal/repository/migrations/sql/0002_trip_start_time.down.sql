@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS trips_starts_at_idx;
+
+ALTER TABLE trips ADD COLUMN date TEXT NOT NULL DEFAULT '';
+
+UPDATE trips SET date = COALESCE(strftime('%Y-%m-%d %H:%M', starts_at), '');
+
+ALTER TABLE trips DROP COLUMN timezone;
+
+ALTER TABLE trips DROP COLUMN starts_at;
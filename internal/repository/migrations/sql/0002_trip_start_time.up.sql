@@ -0,0 +1,9 @@
+-- Free-text trip date is replaced with a structured start time and timezone.
+-- Existing free-text values can't be converted reliably, so they are dropped.
+ALTER TABLE trips ADD COLUMN starts_at DATETIME;
+
+ALTER TABLE trips ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE trips DROP COLUMN date;
+
+CREATE INDEX IF NOT EXISTS trips_starts_at_idx ON trips (starts_at);
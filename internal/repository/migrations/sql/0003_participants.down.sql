@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS participants_user_id_idx;
+
+DROP TABLE IF EXISTS participants;
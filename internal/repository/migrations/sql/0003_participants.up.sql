@@ -0,0 +1,9 @@
+CREATE TABLE participants
+(
+    trip_id   TEXT     NOT NULL,
+    user_id   INTEGER  NOT NULL,
+    joined_at DATETIME NOT NULL,
+    PRIMARY KEY (trip_id, user_id)
+);
+
+CREATE INDEX participants_user_id_idx ON participants (user_id);
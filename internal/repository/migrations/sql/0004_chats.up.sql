@@ -0,0 +1,10 @@
+CREATE TABLE chats
+(
+    id         INTEGER PRIMARY KEY,
+    title      TEXT     NOT NULL,
+    type       TEXT     NOT NULL,
+    active     BOOLEAN  NOT NULL,
+    added_by   INTEGER  NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
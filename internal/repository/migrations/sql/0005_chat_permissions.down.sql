@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS chat_leaders;
+
+ALTER TABLE chats DROP COLUMN announce_policy;
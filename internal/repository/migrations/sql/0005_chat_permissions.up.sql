@@ -0,0 +1,10 @@
+ALTER TABLE chats ADD COLUMN announce_policy TEXT NOT NULL DEFAULT 'admins';
+
+CREATE TABLE chat_leaders
+(
+    chat_id  INTEGER  NOT NULL,
+    user_id  INTEGER  NOT NULL,
+    added_by INTEGER  NOT NULL,
+    added_at DATETIME NOT NULL,
+    PRIMARY KEY (chat_id, user_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE sessions DROP COLUMN updated_at;
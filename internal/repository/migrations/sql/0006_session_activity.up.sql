@@ -0,0 +1,5 @@
+-- Last activity time is used to expire idle sessions.
+-- Existing sessions are considered active at the time of migration.
+ALTER TABLE sessions ADD COLUMN updated_at DATETIME;
+
+UPDATE sessions SET updated_at = CURRENT_TIMESTAMP;
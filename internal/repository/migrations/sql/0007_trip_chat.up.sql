@@ -0,0 +1,2 @@
+-- Chat the trip is announced in, NULL until the trip is published.
+ALTER TABLE trips ADD COLUMN chat_id INTEGER;
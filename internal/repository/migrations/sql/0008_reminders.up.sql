@@ -0,0 +1,8 @@
+-- Sent trip reminders, the primary key guarantees a reminder is sent once.
+CREATE TABLE reminders
+(
+    trip_id        TEXT     NOT NULL,
+    offset_seconds INTEGER  NOT NULL,
+    sent_at        DATETIME NOT NULL,
+    PRIMARY KEY (trip_id, offset_seconds)
+);
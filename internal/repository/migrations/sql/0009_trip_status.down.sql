@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS trips_status_idx;
+
+ALTER TABLE trips ADD COLUMN completed BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE trips SET completed = status <> 'draft';
+
+ALTER TABLE trips DROP COLUMN archived_at;
+
+ALTER TABLE trips DROP COLUMN finished_at;
+
+ALTER TABLE trips DROP COLUMN started_at;
+
+ALTER TABLE trips DROP COLUMN cancelled_at;
+
+ALTER TABLE trips DROP COLUMN published_at;
+
+ALTER TABLE trips DROP COLUMN status;
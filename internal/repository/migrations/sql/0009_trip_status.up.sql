@@ -0,0 +1,19 @@
+-- Completed flag is replaced with a lifecycle status and the time the trip reached each status.
+-- Completed trips were published, their last update time is the best guess of the publishing time.
+ALTER TABLE trips ADD COLUMN status TEXT NOT NULL DEFAULT 'draft';
+
+ALTER TABLE trips ADD COLUMN published_at DATETIME;
+
+ALTER TABLE trips ADD COLUMN cancelled_at DATETIME;
+
+ALTER TABLE trips ADD COLUMN started_at DATETIME;
+
+ALTER TABLE trips ADD COLUMN finished_at DATETIME;
+
+ALTER TABLE trips ADD COLUMN archived_at DATETIME;
+
+UPDATE trips SET status = 'published', published_at = updated_at WHERE completed;
+
+ALTER TABLE trips DROP COLUMN completed;
+
+CREATE INDEX IF NOT EXISTS trips_status_idx ON trips (status);
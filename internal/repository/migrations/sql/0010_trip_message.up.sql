@@ -0,0 +1,2 @@
+-- Announcement message of the trip in its chat, NULL until the trip is published.
+ALTER TABLE trips ADD COLUMN message_id INTEGER;
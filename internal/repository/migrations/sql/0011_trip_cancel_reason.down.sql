@@ -0,0 +1 @@
+ALTER TABLE trips DROP COLUMN cancel_reason;
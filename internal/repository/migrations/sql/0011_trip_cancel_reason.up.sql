@@ -0,0 +1,2 @@
+-- Reason the trip was cancelled for, shown to participants.
+ALTER TABLE trips ADD COLUMN cancel_reason TEXT NOT NULL DEFAULT '';
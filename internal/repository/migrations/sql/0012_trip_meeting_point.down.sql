@@ -0,0 +1,4 @@
+ALTER TABLE trips DROP COLUMN meeting_message_id;
+ALTER TABLE trips DROP COLUMN meeting_longitude;
+ALTER TABLE trips DROP COLUMN meeting_latitude;
+ALTER TABLE trips DROP COLUMN meeting_label;
@@ -0,0 +1,6 @@
+-- Meeting point of the trip: a label, such as a venue or an address, and optional coordinates.
+ALTER TABLE trips ADD COLUMN meeting_label TEXT NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN meeting_latitude REAL;
+ALTER TABLE trips ADD COLUMN meeting_longitude REAL;
+-- Location message posted in reply to the announcement, NULL if there is none.
+ALTER TABLE trips ADD COLUMN meeting_message_id INTEGER;
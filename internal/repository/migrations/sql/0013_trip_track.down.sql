@@ -0,0 +1,11 @@
+ALTER TABLE trips DROP COLUMN track_max_longitude;
+ALTER TABLE trips DROP COLUMN track_max_latitude;
+ALTER TABLE trips DROP COLUMN track_min_longitude;
+ALTER TABLE trips DROP COLUMN track_min_latitude;
+ALTER TABLE trips DROP COLUMN track_max_gradient;
+ALTER TABLE trips DROP COLUMN track_elevation_loss;
+ALTER TABLE trips DROP COLUMN track_elevation_gain;
+ALTER TABLE trips DROP COLUMN track_has_elevation;
+ALTER TABLE trips DROP COLUMN track_distance;
+ALTER TABLE trips DROP COLUMN track_file_name;
+ALTER TABLE trips DROP COLUMN track_file_id;
@@ -0,0 +1,14 @@
+-- Uploaded GPX file of the trip route and stats computed from it, empty file ID if there is none.
+-- Distances and elevations are in meters, the gradient is in percent.
+ALTER TABLE trips ADD COLUMN track_file_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN track_file_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN track_distance REAL NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN track_has_elevation INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN track_elevation_gain REAL NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN track_elevation_loss REAL NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN track_max_gradient REAL NOT NULL DEFAULT 0;
+-- Bounding box of the route.
+ALTER TABLE trips ADD COLUMN track_min_latitude REAL NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN track_min_longitude REAL NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN track_max_latitude REAL NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN track_max_longitude REAL NOT NULL DEFAULT 0;
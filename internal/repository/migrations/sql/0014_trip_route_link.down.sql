@@ -0,0 +1,3 @@
+ALTER TABLE trips DROP COLUMN route_url;
+ALTER TABLE trips DROP COLUMN route_id;
+ALTER TABLE trips DROP COLUMN route_provider;
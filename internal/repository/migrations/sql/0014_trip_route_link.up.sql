@@ -0,0 +1,4 @@
+-- Link to the trip route on a route planner, such as Strava or Komoot, empty URL if there is none.
+ALTER TABLE trips ADD COLUMN route_provider TEXT NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN route_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN route_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,141 @@
+// Package participants provides a repository for trip participants.
+package participants
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	// ErrAlreadyExists is returned when a user already participates in a trip.
+	ErrAlreadyExists = errors.New("participant already exists")
+	// ErrNotFound is returned when a user doesn't participate in a trip.
+	ErrNotFound = errors.New("participant not found")
+)
+
+// Repository provides access to the participants storage.
+type Repository interface {
+	// AddParticipant adds a user to the trip participants.
+	AddParticipant(ctx context.Context, tripID uuid.UUID, userID int64) (*Participant, error)
+	// GetParticipant returns a participant of the trip.
+	GetParticipant(ctx context.Context, tripID uuid.UUID, userID int64) (*Participant, error)
+	// RemoveParticipant removes a user from the trip participants.
+	RemoveParticipant(ctx context.Context, tripID uuid.UUID, userID int64) error
+	// ListParticipantsByTrip returns all participants of the trip ordered by join time.
+	ListParticipantsByTrip(ctx context.Context, tripID uuid.UUID) ([]*Participant, error)
+	// ListParticipantsByUser returns all trips participations of the user ordered by join time.
+	ListParticipantsByUser(ctx context.Context, userID int64) ([]*Participant, error)
+}
+
+// Participant represents a user participating in a trip.
+type Participant struct {
+	TripID   uuid.UUID `db:"trip_id"`
+	UserID   int64     `db:"user_id"`
+	JoinedAt time.Time `db:"joined_at"`
+}
+
+type key struct {
+	tripID uuid.UUID
+	userID int64
+}
+
+// inMemoryRepository is an in-memory repository for participants.
+type inMemoryRepository struct {
+	sync.RWMutex
+
+	participants map[key]Participant
+}
+
+// NewInMemory creates a new in-memory repository.
+func NewInMemory() Repository {
+	return &inMemoryRepository{
+		RWMutex:      sync.RWMutex{},
+		participants: make(map[key]Participant),
+	}
+}
+
+func (i *inMemoryRepository) AddParticipant(_ context.Context, tripID uuid.UUID, userID int64) (*Participant, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	k := key{tripID: tripID, userID: userID}
+
+	if _, ok := i.participants[k]; ok {
+		return nil, ErrAlreadyExists
+	}
+
+	p := Participant{
+		TripID:   tripID,
+		UserID:   userID,
+		JoinedAt: time.Now(),
+	}
+
+	i.participants[k] = p
+
+	return &p, nil
+}
+
+func (i *inMemoryRepository) GetParticipant(_ context.Context, tripID uuid.UUID, userID int64) (*Participant, error) {
+	i.RLock()
+	defer i.RUnlock()
+
+	p, ok := i.participants[key{tripID: tripID, userID: userID}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &p, nil
+}
+
+func (i *inMemoryRepository) RemoveParticipant(_ context.Context, tripID uuid.UUID, userID int64) error {
+	i.Lock()
+	defer i.Unlock()
+
+	k := key{tripID: tripID, userID: userID}
+
+	if _, ok := i.participants[k]; !ok {
+		return ErrNotFound
+	}
+
+	delete(i.participants, k)
+
+	return nil
+}
+
+func (i *inMemoryRepository) ListParticipantsByTrip(_ context.Context, tripID uuid.UUID) ([]*Participant, error) {
+	return i.list(func(p Participant) bool {
+		return p.TripID == tripID
+	}), nil
+}
+
+func (i *inMemoryRepository) ListParticipantsByUser(_ context.Context, userID int64) ([]*Participant, error) {
+	return i.list(func(p Participant) bool {
+		return p.UserID == userID
+	}), nil
+}
+
+func (i *inMemoryRepository) list(filter func(p Participant) bool) []*Participant {
+	i.RLock()
+	defer i.RUnlock()
+
+	list := make([]*Participant, 0)
+
+	for _, p := range i.participants {
+		if filter(p) {
+			cp := p
+
+			list = append(list, &cp)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].JoinedAt.Before(list[j].JoinedAt)
+	})
+
+	return list
+}
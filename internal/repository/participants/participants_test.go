@@ -0,0 +1,20 @@
+package participants_test
+
+import (
+	"testing"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/repotest"
+)
+
+func TestInMemory(t *testing.T) {
+	repotest.RunParticipants(t, func(_ *testing.T) participants.Repository {
+		return participants.NewInMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunParticipants(t, func(t *testing.T) participants.Repository {
+		return participants.NewSQLite(repotest.NewSQLiteDB(t))
+	})
+}
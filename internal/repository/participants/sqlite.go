@@ -0,0 +1,125 @@
+package participants
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// sqliteRepository is a SQLite repository for participants.
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a new SQLite repository for participants.
+func NewSQLite(db *sql.DB) Repository {
+	return &sqliteRepository{
+		db: db,
+	}
+}
+
+const selectParticipants = `SELECT trip_id, user_id, joined_at FROM participants`
+
+func (s *sqliteRepository) AddParticipant(ctx context.Context, tripID uuid.UUID, userID int64) (*Participant, error) {
+	p := Participant{
+		TripID:   tripID,
+		UserID:   userID,
+		JoinedAt: time.Now().UTC(),
+	}
+
+	const query = `
+		INSERT INTO participants (trip_id, user_id, joined_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (trip_id, user_id) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query, p.TripID, p.UserID, p.JoinedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert participant: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return nil, ErrAlreadyExists
+	}
+
+	return &p, nil
+}
+
+func (s *sqliteRepository) GetParticipant(ctx context.Context, tripID uuid.UUID, userID int64) (*Participant, error) {
+	row := s.db.QueryRowContext(ctx, selectParticipants+` WHERE trip_id = ? AND user_id = ?`, tripID, userID)
+
+	var p Participant
+
+	if err := row.Scan(&p.TripID, &p.UserID, &p.JoinedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get participant: %w", err)
+	}
+
+	return &p, nil
+}
+
+func (s *sqliteRepository) RemoveParticipant(ctx context.Context, tripID uuid.UUID, userID int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM participants WHERE trip_id = ? AND user_id = ?`, tripID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete participant: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *sqliteRepository) ListParticipantsByTrip(ctx context.Context, tripID uuid.UUID) ([]*Participant, error) {
+	return s.list(ctx, selectParticipants+` WHERE trip_id = ? ORDER BY joined_at`, tripID)
+}
+
+func (s *sqliteRepository) ListParticipantsByUser(ctx context.Context, userID int64) ([]*Participant, error) {
+	return s.list(ctx, selectParticipants+` WHERE user_id = ? ORDER BY joined_at`, userID)
+}
+
+func (s *sqliteRepository) list(ctx context.Context, query string, args ...any) ([]*Participant, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	list := make([]*Participant, 0)
+
+	for rows.Next() {
+		var p Participant
+
+		if err = rows.Scan(&p.TripID, &p.UserID, &p.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan participant: %w", err)
+		}
+
+		list = append(list, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate participants: %w", err)
+	}
+
+	return list, nil
+}
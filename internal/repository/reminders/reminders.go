@@ -0,0 +1,112 @@
+// Package reminders provides a repository for sent trip reminders.
+package reminders
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// ErrAlreadyExists is returned when a reminder is already recorded.
+var ErrAlreadyExists = errors.New("reminder already exists")
+
+// Repository provides access to the sent reminders storage.
+type Repository interface {
+	// AddReminder records the reminder of the trip sent the offset before the trip start.
+	// ErrAlreadyExists is returned when it's recorded already, so only one sender claims a reminder.
+	AddReminder(ctx context.Context, tripID uuid.UUID, offset time.Duration) (*Reminder, error)
+	// ListRemindersByTrip returns reminders of the trip ordered by offset, the largest first.
+	ListRemindersByTrip(ctx context.Context, tripID uuid.UUID) ([]*Reminder, error)
+	// DeleteRemindersByTrip removes all reminders of the trip, so they are sent again.
+	DeleteRemindersByTrip(ctx context.Context, tripID uuid.UUID) error
+}
+
+// Reminder represents a sent trip reminder.
+type Reminder struct {
+	TripID uuid.UUID `db:"trip_id"`
+	// Offset is how long before the trip start the reminder is due.
+	Offset time.Duration `db:"offset_seconds"`
+	SentAt time.Time     `db:"sent_at"`
+}
+
+type key struct {
+	tripID uuid.UUID
+	offset time.Duration
+}
+
+// inMemoryRepository is an in-memory repository for reminders.
+type inMemoryRepository struct {
+	sync.RWMutex
+
+	reminders map[key]Reminder
+}
+
+// NewInMemory creates a new in-memory repository.
+func NewInMemory() Repository {
+	return &inMemoryRepository{
+		RWMutex:   sync.RWMutex{},
+		reminders: make(map[key]Reminder),
+	}
+}
+
+func (i *inMemoryRepository) AddReminder(_ context.Context, tripID uuid.UUID, offset time.Duration) (*Reminder, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	// Offsets are stored with seconds precision.
+	offset = offset.Truncate(time.Second)
+
+	k := key{tripID: tripID, offset: offset}
+
+	if _, ok := i.reminders[k]; ok {
+		return nil, ErrAlreadyExists
+	}
+
+	r := Reminder{
+		TripID: tripID,
+		Offset: offset,
+		SentAt: time.Now().UTC(),
+	}
+
+	i.reminders[k] = r
+
+	return &r, nil
+}
+
+func (i *inMemoryRepository) ListRemindersByTrip(_ context.Context, tripID uuid.UUID) ([]*Reminder, error) {
+	i.RLock()
+	defer i.RUnlock()
+
+	list := make([]*Reminder, 0)
+
+	for _, r := range i.reminders {
+		if r.TripID != tripID {
+			continue
+		}
+
+		list = append(list, &r)
+	}
+
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Offset > list[b].Offset
+	})
+
+	return list, nil
+}
+
+func (i *inMemoryRepository) DeleteRemindersByTrip(_ context.Context, tripID uuid.UUID) error {
+	i.Lock()
+	defer i.Unlock()
+
+	for k := range i.reminders {
+		if k.tripID == tripID {
+			delete(i.reminders, k)
+		}
+	}
+
+	return nil
+}
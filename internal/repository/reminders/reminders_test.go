@@ -0,0 +1,20 @@
+package reminders_test
+
+import (
+	"testing"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/repotest"
+)
+
+func TestInMemory(t *testing.T) {
+	repotest.RunReminders(t, func(_ *testing.T) reminders.Repository {
+		return reminders.NewInMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunReminders(t, func(t *testing.T) reminders.Repository {
+		return reminders.NewSQLite(repotest.NewSQLiteDB(t))
+	})
+}
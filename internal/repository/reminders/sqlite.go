@@ -0,0 +1,99 @@
+package reminders
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// sqliteRepository is a SQLite repository for reminders.
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a new SQLite repository for reminders.
+func NewSQLite(db *sql.DB) Repository {
+	return &sqliteRepository{
+		db: db,
+	}
+}
+
+func (s *sqliteRepository) AddReminder(ctx context.Context, tripID uuid.UUID, offset time.Duration) (*Reminder, error) {
+	r := Reminder{
+		TripID: tripID,
+		Offset: offset.Truncate(time.Second),
+		SentAt: time.Now().UTC(),
+	}
+
+	const query = `
+		INSERT INTO reminders (trip_id, offset_seconds, sent_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (trip_id, offset_seconds) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query, r.TripID, int64(r.Offset/time.Second), r.SentAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert reminder: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return nil, ErrAlreadyExists
+	}
+
+	return &r, nil
+}
+
+func (s *sqliteRepository) ListRemindersByTrip(ctx context.Context, tripID uuid.UUID) ([]*Reminder, error) {
+	const query = `
+		SELECT trip_id, offset_seconds, sent_at
+		FROM reminders
+		WHERE trip_id = ?
+		ORDER BY offset_seconds DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminders: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	list := make([]*Reminder, 0)
+
+	for rows.Next() {
+		var (
+			r       Reminder
+			seconds int64
+		)
+
+		if err = rows.Scan(&r.TripID, &seconds, &r.SentAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+
+		r.Offset = time.Duration(seconds) * time.Second
+
+		list = append(list, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reminders: %w", err)
+	}
+
+	return list, nil
+}
+
+func (s *sqliteRepository) DeleteRemindersByTrip(ctx context.Context, tripID uuid.UUID) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM reminders WHERE trip_id = ?`, tripID); err != nil {
+		return fmt.Errorf("failed to delete reminders: %w", err)
+	}
+
+	return nil
+}
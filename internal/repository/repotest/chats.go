@@ -0,0 +1,228 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+)
+
+// ChatsSuite is a conformance suite for chats.Repository.
+type ChatsSuite struct {
+	suite.Suite
+
+	newRepo func(t *testing.T) chats.Repository
+	repo    chats.Repository
+}
+
+// RunChats runs chats.Repository conformance suite against repositories created by newRepo.
+// newRepo is called for each test and must return an empty repository.
+func RunChats(t *testing.T, newRepo func(t *testing.T) chats.Repository) {
+	suite.Run(t, &ChatsSuite{newRepo: newRepo})
+}
+
+// SetupTest creates a fresh repository for each test.
+func (s *ChatsSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+	s.Require().NotNil(s.repo)
+}
+
+func (s *ChatsSuite) TestSaveAndGet() {
+	ctx := context.Background()
+
+	before := time.Now().Truncate(time.Second)
+
+	c, err := s.repo.SaveChat(ctx, chats.SaveChatParams{
+		ID:      -100,
+		Title:   "Club",
+		Type:    "supergroup",
+		Active:  true,
+		AddedBy: 1,
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(-100), c.ID)
+	s.Equal("Club", c.Title)
+	s.Equal("supergroup", c.Type)
+	s.True(c.Active)
+	s.Equal(int64(1), c.AddedBy)
+	s.Equal("admins", c.Policy)
+	s.False(c.CreatedAt.Before(before))
+	s.True(c.CreatedAt.Equal(c.UpdatedAt))
+
+	got, err := s.repo.GetChat(ctx, -100)
+	s.Require().NoError(err)
+	s.assertChat(c, got)
+}
+
+func (s *ChatsSuite) TestSaveUpdatesExisting() {
+	ctx := context.Background()
+
+	created, err := s.repo.SaveChat(ctx, chats.SaveChatParams{ID: -100, Title: "Club", Type: "group", Active: true, AddedBy: 1})
+	s.Require().NoError(err)
+
+	updated, err := s.repo.SaveChat(ctx, chats.SaveChatParams{ID: -100, Title: "Cycling club", Type: "supergroup", Active: false, AddedBy: 2})
+	s.Require().NoError(err)
+	s.Equal("Cycling club", updated.Title)
+	s.Equal("supergroup", updated.Type)
+	s.False(updated.Active)
+	s.Equal(int64(2), updated.AddedBy)
+	s.True(created.CreatedAt.Equal(updated.CreatedAt))
+	s.False(updated.UpdatedAt.Before(created.UpdatedAt))
+
+	got, err := s.repo.GetChat(ctx, -100)
+	s.Require().NoError(err)
+	s.assertChat(updated, got)
+}
+
+func (s *ChatsSuite) TestGetNotFound() {
+	_, err := s.repo.GetChat(context.Background(), -100)
+	s.ErrorIs(err, chats.ErrNotFound)
+}
+
+func (s *ChatsSuite) TestListActiveChats() {
+	ctx := context.Background()
+
+	list, err := s.repo.ListActiveChats(ctx)
+	s.Require().NoError(err)
+	s.NotNil(list)
+	s.Empty(list)
+
+	for _, p := range []chats.SaveChatParams{
+		{ID: -3, Title: "Zeta", Type: "group", Active: true},
+		{ID: -2, Title: "Alpha", Type: "group", Active: false},
+		{ID: -1, Title: "Beta", Type: "supergroup", Active: true},
+		{ID: -4, Title: "Beta", Type: "supergroup", Active: true},
+	} {
+		_, err = s.repo.SaveChat(ctx, p)
+		s.Require().NoError(err)
+	}
+
+	list, err = s.repo.ListActiveChats(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(list, 3)
+	s.Equal(int64(-4), list[0].ID)
+	s.Equal(int64(-1), list[1].ID)
+	s.Equal(int64(-3), list[2].ID)
+}
+
+func (s *ChatsSuite) TestReturnsCopies() {
+	ctx := context.Background()
+
+	c, err := s.repo.SaveChat(ctx, chats.SaveChatParams{ID: -100, Title: "Club", Type: "group", Active: true})
+	s.Require().NoError(err)
+
+	c.Title = "changed"
+
+	got, err := s.repo.GetChat(ctx, -100)
+	s.Require().NoError(err)
+	s.Equal("Club", got.Title)
+}
+
+func (s *ChatsSuite) TestConcurrentSave() {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+
+	for i := range concurrency {
+		wg.Add(1)
+
+		go func(id int64) {
+			defer wg.Done()
+
+			_, err := s.repo.SaveChat(ctx, chats.SaveChatParams{ID: -id, Title: "Club", Type: "group", Active: true})
+			s.NoError(err)
+		}(int64(i + 1))
+	}
+
+	wg.Wait()
+
+	list, err := s.repo.ListActiveChats(ctx)
+	s.Require().NoError(err)
+	s.Len(list, concurrency)
+}
+
+func (s *ChatsSuite) TestSetPolicy() {
+	ctx := context.Background()
+
+	_, err := s.repo.SaveChat(ctx, chats.SaveChatParams{ID: -100, Title: "Club", Type: "group", Active: true})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.SetPolicy(ctx, -100, "leaders"))
+
+	got, err := s.repo.GetChat(ctx, -100)
+	s.Require().NoError(err)
+	s.Equal("leaders", got.Policy)
+
+	// Saving the chat on membership change keeps the policy.
+	_, err = s.repo.SaveChat(ctx, chats.SaveChatParams{ID: -100, Title: "Club", Type: "supergroup", Active: true})
+	s.Require().NoError(err)
+
+	got, err = s.repo.GetChat(ctx, -100)
+	s.Require().NoError(err)
+	s.Equal("leaders", got.Policy)
+
+	s.ErrorIs(s.repo.SetPolicy(ctx, -200, "anyone"), chats.ErrNotFound)
+}
+
+func (s *ChatsSuite) TestLeaders() {
+	ctx := context.Background()
+
+	before := time.Now().Truncate(time.Second)
+
+	l, err := s.repo.AddLeader(ctx, -100, 1, 10)
+	s.Require().NoError(err)
+	s.Equal(int64(-100), l.ChatID)
+	s.Equal(int64(1), l.UserID)
+	s.Equal(int64(10), l.AddedBy)
+	s.False(l.AddedAt.Before(before))
+
+	_, err = s.repo.AddLeader(ctx, -100, 1, 10)
+	s.ErrorIs(err, chats.ErrLeaderAlreadyExists)
+
+	got, err := s.repo.GetLeader(ctx, -100, 1)
+	s.Require().NoError(err)
+	s.Equal(l.UserID, got.UserID)
+	s.Equal(l.AddedBy, got.AddedBy)
+	s.True(l.AddedAt.Equal(got.AddedAt))
+
+	_, err = s.repo.GetLeader(ctx, -200, 1)
+	s.ErrorIs(err, chats.ErrLeaderNotFound)
+
+	_, err = s.repo.AddLeader(ctx, -100, 2, 10)
+	s.Require().NoError(err)
+
+	_, err = s.repo.AddLeader(ctx, -200, 3, 10)
+	s.Require().NoError(err)
+
+	list, err := s.repo.ListLeaders(ctx, -100)
+	s.Require().NoError(err)
+	s.Require().Len(list, 2)
+	s.Equal(int64(1), list[0].UserID)
+	s.Equal(int64(2), list[1].UserID)
+
+	s.Require().NoError(s.repo.RemoveLeader(ctx, -100, 1))
+	s.ErrorIs(s.repo.RemoveLeader(ctx, -100, 1), chats.ErrLeaderNotFound)
+
+	_, err = s.repo.GetLeader(ctx, -100, 1)
+	s.ErrorIs(err, chats.ErrLeaderNotFound)
+
+	list, err = s.repo.ListLeaders(ctx, -300)
+	s.Require().NoError(err)
+	s.NotNil(list)
+	s.Empty(list)
+}
+
+func (s *ChatsSuite) assertChat(want, got *chats.Chat) {
+	s.Equal(want.ID, got.ID)
+	s.Equal(want.Title, got.Title)
+	s.Equal(want.Type, got.Type)
+	s.Equal(want.Active, got.Active)
+	s.Equal(want.AddedBy, got.AddedBy)
+	s.Equal(want.Policy, got.Policy)
+	s.True(want.CreatedAt.Equal(got.CreatedAt))
+	s.True(want.UpdatedAt.Equal(got.UpdatedAt))
+}
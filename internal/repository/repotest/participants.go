@@ -0,0 +1,179 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+)
+
+// ParticipantsSuite is a conformance suite for participants.Repository.
+type ParticipantsSuite struct {
+	suite.Suite
+
+	newRepo func(t *testing.T) participants.Repository
+	repo    participants.Repository
+}
+
+// RunParticipants runs participants.Repository conformance suite against repositories created by newRepo.
+// newRepo is called for each test and must return an empty repository.
+func RunParticipants(t *testing.T, newRepo func(t *testing.T) participants.Repository) {
+	suite.Run(t, &ParticipantsSuite{newRepo: newRepo})
+}
+
+// SetupTest creates a fresh repository for each test.
+func (s *ParticipantsSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+	s.Require().NotNil(s.repo)
+}
+
+func (s *ParticipantsSuite) TestAddAndGet() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+	before := time.Now().Truncate(time.Second)
+
+	p, err := s.repo.AddParticipant(ctx, tripID, 1)
+	s.Require().NoError(err)
+	s.Equal(tripID, p.TripID)
+	s.Equal(int64(1), p.UserID)
+	s.False(p.JoinedAt.Before(before))
+
+	got, err := s.repo.GetParticipant(ctx, tripID, 1)
+	s.Require().NoError(err)
+	s.Equal(p.TripID, got.TripID)
+	s.Equal(p.UserID, got.UserID)
+	s.True(p.JoinedAt.Equal(got.JoinedAt))
+}
+
+func (s *ParticipantsSuite) TestAddDuplicate() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	_, err := s.repo.AddParticipant(ctx, tripID, 1)
+	s.Require().NoError(err)
+
+	_, err = s.repo.AddParticipant(ctx, tripID, 1)
+	s.ErrorIs(err, participants.ErrAlreadyExists)
+
+	// The same user can join another trip.
+	_, err = s.repo.AddParticipant(ctx, uuid.Must(uuid.NewV4()), 1)
+	s.NoError(err)
+}
+
+func (s *ParticipantsSuite) TestGetNotFound() {
+	_, err := s.repo.GetParticipant(context.Background(), uuid.Must(uuid.NewV4()), 1)
+	s.ErrorIs(err, participants.ErrNotFound)
+}
+
+func (s *ParticipantsSuite) TestRemove() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	_, err := s.repo.AddParticipant(ctx, tripID, 1)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.RemoveParticipant(ctx, tripID, 1))
+
+	_, err = s.repo.GetParticipant(ctx, tripID, 1)
+	s.ErrorIs(err, participants.ErrNotFound)
+
+	s.ErrorIs(s.repo.RemoveParticipant(ctx, tripID, 1), participants.ErrNotFound)
+
+	// User can join again after leaving.
+	_, err = s.repo.AddParticipant(ctx, tripID, 1)
+	s.NoError(err)
+}
+
+func (s *ParticipantsSuite) TestList() {
+	ctx := context.Background()
+
+	trip1, trip2 := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	list, err := s.repo.ListParticipantsByTrip(ctx, trip1)
+	s.Require().NoError(err)
+	s.Empty(list)
+
+	for _, p := range []struct {
+		tripID uuid.UUID
+		userID int64
+	}{
+		{tripID: trip1, userID: 2},
+		{tripID: trip1, userID: 1},
+		{tripID: trip2, userID: 1},
+	} {
+		_, err = s.repo.AddParticipant(ctx, p.tripID, p.userID)
+		s.Require().NoError(err)
+
+		// Make join time distinguishable.
+		time.Sleep(time.Millisecond)
+	}
+
+	list, err = s.repo.ListParticipantsByTrip(ctx, trip1)
+	s.Require().NoError(err)
+	s.Require().Len(list, 2)
+	s.Equal(int64(2), list[0].UserID, "participants must be ordered by join time")
+	s.Equal(int64(1), list[1].UserID, "participants must be ordered by join time")
+
+	list, err = s.repo.ListParticipantsByUser(ctx, 1)
+	s.Require().NoError(err)
+	s.Require().Len(list, 2)
+	s.Equal(trip1, list[0].TripID)
+	s.Equal(trip2, list[1].TripID)
+
+	list, err = s.repo.ListParticipantsByUser(ctx, 42)
+	s.Require().NoError(err)
+	s.Empty(list)
+}
+
+func (s *ParticipantsSuite) TestConcurrentAdd() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	var (
+		wg    sync.WaitGroup
+		added atomic.Int32
+	)
+
+	for i := range concurrency {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := s.repo.AddParticipant(ctx, tripID, int64(i+1))
+			s.NoError(err)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			// Double taps of the same user must result in a single participation.
+			_, err := s.repo.AddParticipant(ctx, tripID, 1000)
+			if err == nil {
+				added.Add(1)
+
+				return
+			}
+
+			s.ErrorIs(err, participants.ErrAlreadyExists)
+		}()
+	}
+
+	wg.Wait()
+
+	s.Equal(int32(1), added.Load())
+
+	list, err := s.repo.ListParticipantsByTrip(ctx, tripID)
+	s.Require().NoError(err)
+	s.Len(list, concurrency+1)
+}
@@ -0,0 +1,142 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
+)
+
+// RemindersSuite is a conformance suite for reminders.Repository.
+type RemindersSuite struct {
+	suite.Suite
+
+	newRepo func(t *testing.T) reminders.Repository
+	repo    reminders.Repository
+}
+
+// RunReminders runs reminders.Repository conformance suite against repositories created by newRepo.
+// newRepo is called for each test and must return an empty repository.
+func RunReminders(t *testing.T, newRepo func(t *testing.T) reminders.Repository) {
+	suite.Run(t, &RemindersSuite{newRepo: newRepo})
+}
+
+// SetupTest creates a fresh repository for each test.
+func (s *RemindersSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+	s.Require().NotNil(s.repo)
+}
+
+func (s *RemindersSuite) TestAddAndList() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+	before := time.Now().Truncate(time.Second)
+
+	r, err := s.repo.AddReminder(ctx, tripID, time.Hour)
+	s.Require().NoError(err)
+	s.Equal(tripID, r.TripID)
+	s.Equal(time.Hour, r.Offset)
+	s.False(r.SentAt.Before(before))
+
+	_, err = s.repo.AddReminder(ctx, tripID, 24*time.Hour)
+	s.Require().NoError(err)
+
+	_, err = s.repo.AddReminder(ctx, uuid.Must(uuid.NewV4()), 30*time.Minute)
+	s.Require().NoError(err)
+
+	list, err := s.repo.ListRemindersByTrip(ctx, tripID)
+	s.Require().NoError(err)
+	s.Require().Len(list, 2)
+	s.Equal(24*time.Hour, list[0].Offset, "the largest offset goes first")
+	s.Equal(time.Hour, list[1].Offset)
+	s.True(r.SentAt.Equal(list[1].SentAt))
+}
+
+func (s *RemindersSuite) TestAddDuplicate() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	_, err := s.repo.AddReminder(ctx, tripID, time.Hour)
+	s.Require().NoError(err)
+
+	_, err = s.repo.AddReminder(ctx, tripID, time.Hour)
+	s.ErrorIs(err, reminders.ErrAlreadyExists)
+
+	// Offsets are stored with seconds precision.
+	_, err = s.repo.AddReminder(ctx, tripID, time.Hour+time.Millisecond)
+	s.ErrorIs(err, reminders.ErrAlreadyExists)
+}
+
+func (s *RemindersSuite) TestListEmpty() {
+	list, err := s.repo.ListRemindersByTrip(context.Background(), uuid.Must(uuid.NewV4()))
+	s.Require().NoError(err)
+	s.Empty(list)
+}
+
+func (s *RemindersSuite) TestDeleteByTrip() {
+	ctx := context.Background()
+
+	tripID, otherID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	for _, id := range []uuid.UUID{tripID, otherID} {
+		_, err := s.repo.AddReminder(ctx, id, time.Hour)
+		s.Require().NoError(err)
+	}
+
+	s.Require().NoError(s.repo.DeleteRemindersByTrip(ctx, tripID))
+	// Nothing to delete is not an error.
+	s.Require().NoError(s.repo.DeleteRemindersByTrip(ctx, tripID))
+
+	list, err := s.repo.ListRemindersByTrip(ctx, tripID)
+	s.Require().NoError(err)
+	s.Empty(list)
+
+	list, err = s.repo.ListRemindersByTrip(ctx, otherID)
+	s.Require().NoError(err)
+	s.Len(list, 1)
+
+	// Deleted reminder can be sent again.
+	_, err = s.repo.AddReminder(ctx, tripID, time.Hour)
+	s.NoError(err)
+}
+
+func (s *RemindersSuite) TestConcurrentAdd() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	var (
+		wg    sync.WaitGroup
+		added atomic.Int32
+	)
+
+	for range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// Only one of concurrent senders claims the reminder.
+			_, err := s.repo.AddReminder(ctx, tripID, time.Hour)
+			if err == nil {
+				added.Add(1)
+
+				return
+			}
+
+			s.ErrorIs(err, reminders.ErrAlreadyExists)
+		}()
+	}
+
+	wg.Wait()
+
+	s.Equal(int32(1), added.Load())
+}
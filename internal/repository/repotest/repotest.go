@@ -0,0 +1,47 @@
+// Package repotest provides conformance test suites for repository implementations.
+//
+// Every backend must pass the same suites, so in-memory, SQLite and any future
+// implementations expose identical semantics to the business logic:
+//   - errors are reported with package sentinel errors (ErrNotFound, ErrAlreadyExists, ErrNoChanges);
+//   - returned values are copies, mutating them does not change stored data;
+//   - soft-deleted trips are invisible for reads, updates and repeated deletes;
+//   - repositories are safe for concurrent use.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/database"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/migrations"
+)
+
+// concurrency is a number of goroutines used in concurrency tests.
+const concurrency = 20
+
+// NewSQLiteDB creates a new migrated SQLite database in a temporary directory.
+// Database is closed on test cleanup.
+func NewSQLiteDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := database.Open(ctx, filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	m, err := migrations.New(db)
+	require.NoError(t, err)
+
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	return db
+}
@@ -0,0 +1,249 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
+)
+
+// SessionsSuite is a conformance suite for sessions.Repository.
+type SessionsSuite struct {
+	suite.Suite
+
+	newRepo func(t *testing.T) sessions.Repository
+	repo    sessions.Repository
+}
+
+// RunSessions runs sessions.Repository conformance suite against repositories created by newRepo.
+// newRepo is called for each test and must return an empty repository.
+func RunSessions(t *testing.T, newRepo func(t *testing.T) sessions.Repository) {
+	suite.Run(t, &SessionsSuite{newRepo: newRepo})
+}
+
+// SetupTest creates a fresh repository for each test.
+func (s *SessionsSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+	s.Require().NotNil(s.repo)
+}
+
+func (s *SessionsSuite) TestCreateAndGet() {
+	ctx := context.Background()
+
+	stateID := uuid.Must(uuid.NewV4())
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, &stateID))
+
+	got, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.NotEqual(uuid.Nil, got.ID)
+	s.Equal(int64(1), got.UserID)
+	s.Equal(int64(10), got.ChatID)
+	s.Require().NotNil(got.StateID)
+	s.Equal(stateID, *got.StateID)
+}
+
+func (s *SessionsSuite) TestCreateWithoutState() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+
+	got, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.Nil(got.StateID)
+}
+
+func (s *SessionsSuite) TestCreateDuplicate() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+
+	err := s.repo.CreateSession(ctx, 1, 20, nil)
+	s.ErrorIs(err, sessions.ErrAlreadyExists)
+
+	got, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(int64(10), got.ChatID, "existing session must not be overwritten")
+}
+
+func (s *SessionsSuite) TestGetNotFound() {
+	_, err := s.repo.GetSessionByUserID(context.Background(), 42)
+	s.ErrorIs(err, sessions.ErrNotFound)
+}
+
+func (s *SessionsSuite) TestUpdate() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+
+	sess, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+
+	stateID := uuid.Must(uuid.NewV4())
+
+	sess.ChatID = 20
+	sess.StateID = &stateID
+
+	s.Require().NoError(s.repo.UpdateSession(ctx, sess))
+
+	got, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(sess.ID, got.ID)
+	s.Equal(sess.ChatID, got.ChatID)
+	s.Equal(sess.StateID, got.StateID)
+	s.False(got.UpdatedAt.Before(sess.UpdatedAt), "update marks session as active")
+
+	// Update with the same values is not an error.
+	s.NoError(s.repo.UpdateSession(ctx, got))
+}
+
+func (s *SessionsSuite) TestTouch() {
+	ctx := context.Background()
+
+	before := time.Now().UTC().Add(-time.Second)
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+
+	created, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.True(created.UpdatedAt.After(before), "new session is active")
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.Require().NoError(s.repo.TouchSession(ctx, 1))
+
+	got, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.True(got.UpdatedAt.After(created.UpdatedAt))
+	s.Equal(created.ChatID, got.ChatID)
+
+	s.ErrorIs(s.repo.TouchSession(ctx, 42), sessions.ErrNotFound)
+}
+
+func (s *SessionsSuite) TestUpdateErrors() {
+	ctx := context.Background()
+
+	s.Error(s.repo.UpdateSession(ctx, nil))
+
+	err := s.repo.UpdateSession(ctx, &sessions.Session{ID: uuid.Must(uuid.NewV4()), UserID: 42})
+	s.ErrorIs(err, sessions.ErrNotFound)
+}
+
+func (s *SessionsSuite) TestDelete() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+	s.Require().NoError(s.repo.DeleteSession(ctx, 1))
+
+	_, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.ErrorIs(err, sessions.ErrNotFound)
+
+	s.ErrorIs(s.repo.DeleteSession(ctx, 1), sessions.ErrNotFound)
+
+	// Session can be created again after deletion.
+	s.NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+}
+
+func (s *SessionsSuite) TestDeleteIdle() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+
+	created, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+
+	err = s.repo.DeleteIdleSession(ctx, 1, created.UpdatedAt.Add(-time.Minute))
+	s.ErrorIs(err, sessions.ErrNotFound, "active session must not be deleted")
+
+	_, err = s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.DeleteIdleSession(ctx, 1, created.UpdatedAt.Add(time.Minute)))
+
+	_, err = s.repo.GetSessionByUserID(ctx, 1)
+	s.ErrorIs(err, sessions.ErrNotFound)
+
+	s.ErrorIs(s.repo.DeleteIdleSession(ctx, 1, time.Now().Add(time.Hour)), sessions.ErrNotFound)
+}
+
+func (s *SessionsSuite) TestList() {
+	ctx := context.Background()
+
+	list, err := s.repo.ListSessions(ctx)
+	s.Require().NoError(err)
+	s.Empty(list)
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, nil))
+	s.Require().NoError(s.repo.CreateSession(ctx, 2, 20, nil))
+
+	list, err = s.repo.ListSessions(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(list, 2)
+
+	userIDs := []int64{list[0].UserID, list[1].UserID}
+	s.ElementsMatch([]int64{1, 2}, userIDs)
+}
+
+func (s *SessionsSuite) TestReturnsCopies() {
+	ctx := context.Background()
+
+	stateID := uuid.Must(uuid.NewV4())
+
+	s.Require().NoError(s.repo.CreateSession(ctx, 1, 10, &stateID))
+
+	sess, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+
+	sess.ChatID = 20
+	*sess.StateID = uuid.Must(uuid.NewV4())
+
+	got, err := s.repo.GetSessionByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(int64(10), got.ChatID)
+	s.Equal(stateID, *got.StateID)
+}
+
+func (s *SessionsSuite) TestConcurrentAccess() {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+
+	for i := range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			userID := int64(i + 1)
+
+			s.NoError(s.repo.CreateSession(ctx, userID, userID, nil))
+
+			sess, err := s.repo.GetSessionByUserID(ctx, userID)
+			if !s.NoError(err) {
+				return
+			}
+
+			sess.ChatID = userID * 10
+
+			s.NoError(s.repo.UpdateSession(ctx, sess))
+
+			_, err = s.repo.ListSessions(ctx)
+			s.NoError(err)
+		}()
+	}
+
+	wg.Wait()
+
+	list, err := s.repo.ListSessions(ctx)
+	s.Require().NoError(err)
+	s.Len(list, concurrency)
+
+	for _, sess := range list {
+		s.Equal(sess.UserID*10, sess.ChatID)
+	}
+}
@@ -0,0 +1,205 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
+)
+
+// StatesSuite is a conformance suite for states.Repository.
+type StatesSuite struct {
+	suite.Suite
+
+	newRepo func(t *testing.T) states.Repository
+	repo    states.Repository
+}
+
+// RunStates runs states.Repository conformance suite against repositories created by newRepo.
+// newRepo is called for each test and must return an empty repository.
+func RunStates(t *testing.T, newRepo func(t *testing.T) states.Repository) {
+	suite.Run(t, &StatesSuite{newRepo: newRepo})
+}
+
+// SetupTest creates a fresh repository for each test.
+func (s *StatesSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+	s.Require().NotNil(s.repo)
+}
+
+func (s *StatesSuite) TestCreateAndGet() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	st, err := s.repo.CreateState(ctx, states.CreateParams{
+		UserID:  1,
+		Command: "newtrip",
+		TripID:  &tripID,
+		Step:    3,
+	})
+	s.Require().NoError(err)
+	s.NotEqual(uuid.Nil, st.ID)
+
+	byID, err := s.repo.GetStateByID(ctx, st.ID)
+	s.Require().NoError(err)
+	s.Equal(st, byID)
+
+	byUser, err := s.repo.GetStateByUserID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(st, byUser)
+}
+
+func (s *StatesSuite) TestCreateDuplicateUser() {
+	ctx := context.Background()
+
+	first, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, Step: 1})
+	s.Require().NoError(err)
+
+	// Each user can only have one state, existing state is returned along with the error.
+	second, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, Step: 2})
+	s.ErrorIs(err, states.ErrAlreadyExists)
+	s.Require().NotNil(second)
+	s.Equal(first, second)
+}
+
+func (s *StatesSuite) TestGetNotFound() {
+	ctx := context.Background()
+
+	_, err := s.repo.GetStateByID(ctx, uuid.Must(uuid.NewV4()))
+	s.ErrorIs(err, states.ErrNotFound)
+
+	_, err = s.repo.GetStateByUserID(ctx, 42)
+	s.ErrorIs(err, states.ErrNotFound)
+}
+
+func (s *StatesSuite) TestUpdate() {
+	ctx := context.Background()
+
+	st, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, Step: 1})
+	s.Require().NoError(err)
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	st.TripID = &tripID
+	st.Command = "newtrip"
+	st.Step = 2
+
+	s.Require().NoError(s.repo.UpdateState(ctx, st))
+
+	got, err := s.repo.GetStateByID(ctx, st.ID)
+	s.Require().NoError(err)
+	s.Equal(st, got)
+
+	// Trip can be detached.
+	got.TripID = nil
+
+	s.Require().NoError(s.repo.UpdateState(ctx, got))
+
+	got, err = s.repo.GetStateByID(ctx, st.ID)
+	s.Require().NoError(err)
+	s.Nil(got.TripID)
+}
+
+func (s *StatesSuite) TestUpdateNoChanges() {
+	ctx := context.Background()
+
+	st, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, Step: 1})
+	s.Require().NoError(err)
+
+	// Changes are detected by value, not by identity.
+	same := *st
+
+	s.ErrorIs(s.repo.UpdateState(ctx, &same), states.ErrNoChanges)
+	s.ErrorIs(s.repo.UpdateState(ctx, st), states.ErrNoChanges)
+}
+
+func (s *StatesSuite) TestUpdateErrors() {
+	ctx := context.Background()
+
+	s.Error(s.repo.UpdateState(ctx, nil))
+
+	err := s.repo.UpdateState(ctx, &states.State{ID: uuid.Must(uuid.NewV4()), UserID: 1})
+	s.ErrorIs(err, states.ErrNotFound)
+
+	st, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, Step: 1})
+	s.Require().NoError(err)
+
+	st.UserID = 2
+	st.Step = 2
+
+	err = s.repo.UpdateState(ctx, st)
+	s.Require().Error(err, "state can't be moved to another user")
+	s.NotErrorIs(err, states.ErrNotFound)
+	s.NotErrorIs(err, states.ErrNoChanges)
+}
+
+func (s *StatesSuite) TestList() {
+	ctx := context.Background()
+
+	list, err := s.repo.ListStates(ctx)
+	s.Require().NoError(err)
+	s.Empty(list)
+
+	first, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, Step: 1})
+	s.Require().NoError(err)
+
+	second, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 2, Step: 1})
+	s.Require().NoError(err)
+
+	list, err = s.repo.ListStates(ctx)
+	s.Require().NoError(err)
+	s.ElementsMatch([]*states.State{first, second}, list)
+}
+
+func (s *StatesSuite) TestReturnsCopies() {
+	ctx := context.Background()
+
+	tripID := uuid.Must(uuid.NewV4())
+
+	st, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, TripID: &tripID, Step: 1})
+	s.Require().NoError(err)
+
+	st.Step = 5
+	*st.TripID = uuid.Must(uuid.NewV4())
+
+	got, err := s.repo.GetStateByID(ctx, st.ID)
+	s.Require().NoError(err)
+	s.Equal(uint(1), got.Step)
+	s.Equal(tripID, *got.TripID)
+}
+
+func (s *StatesSuite) TestConcurrentCreate() {
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := s.repo.CreateState(ctx, states.CreateParams{UserID: 1, Step: 1})
+			if err == nil {
+				created.Add(1)
+
+				return
+			}
+
+			s.ErrorIs(err, states.ErrAlreadyExists)
+		}()
+	}
+
+	wg.Wait()
+
+	s.Equal(int32(1), created.Load())
+}
@@ -0,0 +1,502 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+// TripsSuite is a conformance suite for trips.Repository.
+type TripsSuite struct {
+	suite.Suite
+
+	newRepo func(t *testing.T) trips.Repository
+	repo    trips.Repository
+}
+
+// RunTrips runs trips.Repository conformance suite against repositories created by newRepo.
+// newRepo is called for each test and must return an empty repository.
+func RunTrips(t *testing.T, newRepo func(t *testing.T) trips.Repository) {
+	suite.Run(t, &TripsSuite{newRepo: newRepo})
+}
+
+// SetupTest creates a fresh repository for each test.
+func (s *TripsSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+	s.Require().NotNil(s.repo)
+}
+
+// assertTrip compares trips, timestamps are compared as instants.
+func (s *TripsSuite) assertTrip(want, got *trips.Trip) {
+	s.T().Helper()
+
+	s.Require().NotNil(got)
+	s.Equal(want.ID, got.ID)
+	s.Equal(want.Name, got.Name)
+	s.Equal(want.Description, got.Description)
+	s.Equal(want.Timezone, got.Timezone)
+	s.True(want.StartsAt.Equal(got.StartsAt), "starts_at: want %s, got %s", want.StartsAt, got.StartsAt)
+	s.Equal(want.CreatedBy, got.CreatedBy)
+	s.Equal(want.ChatID, got.ChatID)
+	s.Equal(want.MessageID, got.MessageID)
+	s.Equal(want.CancelReason, got.CancelReason)
+	s.Equal(want.MeetingPoint, got.MeetingPoint)
+	s.Equal(want.MeetingMessageID, got.MeetingMessageID)
+	s.Equal(want.Track, got.Track)
+	s.Equal(want.RouteLink, got.RouteLink)
+	s.Equal(want.Status, got.Status)
+	s.True(want.PublishedAt.Equal(got.PublishedAt), "published_at: want %s, got %s", want.PublishedAt, got.PublishedAt)
+	s.True(want.CancelledAt.Equal(got.CancelledAt), "cancelled_at: want %s, got %s", want.CancelledAt, got.CancelledAt)
+	s.True(want.StartedAt.Equal(got.StartedAt), "started_at: want %s, got %s", want.StartedAt, got.StartedAt)
+	s.True(want.FinishedAt.Equal(got.FinishedAt), "finished_at: want %s, got %s", want.FinishedAt, got.FinishedAt)
+	s.True(want.ArchivedAt.Equal(got.ArchivedAt), "archived_at: want %s, got %s", want.ArchivedAt, got.ArchivedAt)
+	s.True(want.CreatedAt.Equal(got.CreatedAt), "created_at: want %s, got %s", want.CreatedAt, got.CreatedAt)
+	s.True(want.UpdatedAt.Equal(got.UpdatedAt), "updated_at: want %s, got %s", want.UpdatedAt, got.UpdatedAt)
+	s.True(want.DeletedAt.Equal(got.DeletedAt), "deleted_at: want %s, got %s", want.DeletedAt, got.DeletedAt)
+}
+
+func (s *TripsSuite) createTrip(name string, createdBy int64) *trips.Trip {
+	s.T().Helper()
+
+	t, err := s.repo.CreateTrip(context.Background(), trips.CreateTripParams{
+		Name:        name,
+		Description: "description",
+		StartsAt:    time.Time{},
+		Timezone:    "UTC",
+		CreatedBy:   createdBy,
+	})
+	s.Require().NoError(err)
+
+	return t
+}
+
+func (s *TripsSuite) TestCreateAndGet() {
+	ctx := context.Background()
+
+	before := time.Now()
+
+	startsAt := time.Date(2026, time.October, 20, 9, 30, 0, 0, time.UTC)
+
+	t, err := s.repo.CreateTrip(ctx, trips.CreateTripParams{
+		Name:        "name",
+		Description: "description",
+		StartsAt:    startsAt,
+		Timezone:    "Europe/Kyiv",
+		CreatedBy:   1,
+	})
+	s.Require().NoError(err)
+
+	s.NotEqual(uuid.Nil, t.ID)
+	s.Equal("name", t.Name)
+	s.Equal("description", t.Description)
+	s.True(startsAt.Equal(t.StartsAt))
+	s.Equal("Europe/Kyiv", t.Timezone)
+	s.Equal(int64(1), t.CreatedBy)
+	s.Equal(trips.StatusDraft, t.Status)
+	s.True(t.PublishedAt.IsZero())
+	s.Zero(t.ChatID)
+	s.Zero(t.MessageID)
+	s.True(t.DeletedAt.IsZero())
+	s.False(t.CreatedAt.Before(before.Truncate(time.Second)))
+	s.False(t.UpdatedAt.Before(t.CreatedAt))
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.assertTrip(t, got)
+}
+
+func (s *TripsSuite) TestGetNotFound() {
+	_, err := s.repo.GetTripByID(context.Background(), uuid.Must(uuid.NewV4()))
+	s.ErrorIs(err, trips.ErrNotFound)
+}
+
+func (s *TripsSuite) TestUpdatePartial() {
+	ctx := context.Background()
+
+	t := s.createTrip("name", 1)
+
+	name := "new name"
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{Name: &name}))
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(name, got.Name)
+	s.True(got.StartsAt.IsZero(), "not set fields must be kept")
+	s.Equal(t.Timezone, got.Timezone, "not set fields must be kept")
+	s.Equal(t.Description, got.Description, "not set fields must be kept")
+	s.False(got.UpdatedAt.Before(t.UpdatedAt))
+
+	startsAt := time.Date(2026, time.October, 20, 9, 30, 0, 0, time.FixedZone("EEST", 3*60*60))
+	timezone, description, chatID, messageID := "Europe/Kyiv", "new description", int64(-100), 42
+	reason := "rain"
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{
+		StartsAt:     &startsAt,
+		Timezone:     &timezone,
+		Description:  &description,
+		ChatID:       &chatID,
+		MessageID:    &messageID,
+		CancelReason: &reason,
+	}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(name, got.Name)
+	s.True(startsAt.Equal(got.StartsAt), "start time must be the same instant")
+	s.Equal(timezone, got.Timezone)
+	s.Equal(description, got.Description)
+	s.Equal(chatID, got.ChatID)
+	s.Equal(messageID, got.MessageID)
+	s.Equal(reason, got.CancelReason)
+
+	// Start time can be reset.
+	var zero time.Time
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{StartsAt: &zero}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.True(got.StartsAt.IsZero())
+}
+
+func (s *TripsSuite) TestUpdateMeetingPoint() {
+	ctx := context.Background()
+
+	t := s.createTrip("name", 1)
+	s.Zero(t.MeetingPoint)
+
+	venue := trips.MeetingPoint{Label: "Central square", HasLocation: true, Latitude: 50.4501, Longitude: 30.5234}
+	messageID := 43
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{
+		MeetingPoint:     &venue,
+		MeetingMessageID: &messageID,
+	}))
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(venue, got.MeetingPoint)
+	s.Equal(messageID, got.MeetingMessageID)
+
+	name := "new name"
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{Name: &name}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(venue, got.MeetingPoint, "not set meeting point must be kept")
+
+	// Address replaces the whole meeting point, the previous location is removed.
+	address := trips.MeetingPoint{Label: "1 Main street"}
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{MeetingPoint: &address}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(address, got.MeetingPoint)
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{MeetingPoint: &trips.MeetingPoint{}}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Zero(got.MeetingPoint)
+}
+
+func (s *TripsSuite) TestUpdateTrack() {
+	ctx := context.Background()
+
+	t := s.createTrip("name", 1)
+	s.Zero(t.Track)
+
+	track := trips.Track{
+		FileID:        "file-id",
+		FileName:      "ride.gpx",
+		Distance:      42195.5,
+		HasElevation:  true,
+		ElevationGain: 512.25,
+		ElevationLoss: 498,
+		MaxGradient:   9.8,
+		MinLatitude:   50.4,
+		MinLongitude:  30.4,
+		MaxLatitude:   50.6,
+		MaxLongitude:  30.7,
+	}
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{Track: &track}))
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(track, got.Track)
+
+	name := "new name"
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{Name: &name}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(track, got.Track, "not set track must be kept")
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{Track: &trips.Track{}}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Zero(got.Track)
+}
+
+func (s *TripsSuite) TestUpdateRouteLink() {
+	ctx := context.Background()
+
+	t := s.createTrip("name", 1)
+	s.Zero(t.RouteLink)
+
+	link := trips.RouteLink{Provider: "Strava", ID: "3344556677", URL: "https://www.strava.com/routes/3344556677"}
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{RouteLink: &link}))
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(link, got.RouteLink)
+
+	name := "new name"
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{Name: &name}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(link, got.RouteLink, "not set route link must be kept")
+
+	s.Require().NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{RouteLink: &trips.RouteLink{}}))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Zero(got.RouteLink)
+}
+
+func (s *TripsSuite) TestSetStatus() {
+	ctx := context.Background()
+
+	t := s.createTrip("name", 1)
+
+	before := time.Now().Truncate(time.Second)
+
+	s.Require().NoError(s.repo.SetStatus(ctx, t.ID, trips.StatusDraft, trips.StatusPublished))
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(trips.StatusPublished, got.Status)
+	s.False(got.PublishedAt.Before(before))
+	s.True(got.PublishedAt.Equal(got.UpdatedAt))
+	s.True(got.StartedAt.IsZero())
+
+	// Trip is not in the expected status anymore, e.g. another update won the race.
+	err = s.repo.SetStatus(ctx, t.ID, trips.StatusDraft, trips.StatusPublished)
+	s.ErrorIs(err, trips.ErrStatusConflict)
+
+	s.Require().NoError(s.repo.SetStatus(ctx, t.ID, trips.StatusPublished, trips.StatusCancelled))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(trips.StatusCancelled, got.Status)
+	s.False(got.CancelledAt.Before(got.PublishedAt))
+	s.False(got.PublishedAt.IsZero(), "time of the previous status is kept")
+
+	err = s.repo.SetStatus(ctx, t.ID, trips.StatusCancelled, "unknown")
+	s.ErrorIs(err, trips.ErrUnknownStatus)
+
+	err = s.repo.SetStatus(ctx, uuid.Must(uuid.NewV4()), trips.StatusDraft, trips.StatusPublished)
+	s.ErrorIs(err, trips.ErrNotFound)
+
+	s.Require().NoError(s.repo.DeleteTrip(ctx, t.ID))
+
+	err = s.repo.SetStatus(ctx, t.ID, trips.StatusCancelled, trips.StatusArchived)
+	s.ErrorIs(err, trips.ErrNotFound)
+}
+
+func (s *TripsSuite) TestCancelTrip() {
+	ctx := context.Background()
+
+	t := s.createTrip("name", 1)
+
+	err := s.repo.CancelTrip(ctx, t.ID, trips.StatusPublished, "rain")
+	s.ErrorIs(err, trips.ErrStatusConflict)
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(trips.StatusDraft, got.Status)
+	s.Empty(got.CancelReason, "reason is not stored when the status is not changed")
+
+	s.Require().NoError(s.repo.SetStatus(ctx, t.ID, trips.StatusDraft, trips.StatusPublished))
+	s.Require().NoError(s.repo.CancelTrip(ctx, t.ID, trips.StatusPublished, "rain"))
+
+	got, err = s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal(trips.StatusCancelled, got.Status)
+	s.Equal("rain", got.CancelReason)
+	s.False(got.CancelledAt.IsZero())
+
+	err = s.repo.CancelTrip(ctx, uuid.Must(uuid.NewV4()), trips.StatusPublished, "rain")
+	s.ErrorIs(err, trips.ErrNotFound)
+}
+
+func (s *TripsSuite) TestUpdateNotFound() {
+	name := "name"
+
+	err := s.repo.UpdateTrip(context.Background(), uuid.Must(uuid.NewV4()), trips.UpdateTripParams{Name: &name})
+	s.ErrorIs(err, trips.ErrNotFound)
+}
+
+func (s *TripsSuite) TestSoftDelete() {
+	ctx := context.Background()
+
+	deleted := s.createTrip("deleted", 1)
+	kept := s.createTrip("kept", 1)
+
+	s.Require().NoError(s.repo.DeleteTrip(ctx, deleted.ID))
+
+	_, err := s.repo.GetTripByID(ctx, deleted.ID)
+	s.ErrorIs(err, trips.ErrNotFound, "deleted trip must not be visible")
+
+	name := "name"
+
+	s.ErrorIs(s.repo.UpdateTrip(ctx, deleted.ID, trips.UpdateTripParams{Name: &name}), trips.ErrNotFound)
+	s.ErrorIs(s.repo.DeleteTrip(ctx, deleted.ID), trips.ErrNotFound, "trip can't be deleted twice")
+
+	list, err := s.repo.ListTrips(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(list, 1)
+	s.Equal(kept.ID, list[0].ID)
+
+	list, err = s.repo.ListTripsByUser(ctx, 1)
+	s.Require().NoError(err)
+	s.Require().Len(list, 1)
+	s.Equal(kept.ID, list[0].ID)
+}
+
+func (s *TripsSuite) TestDeleteNotFound() {
+	s.ErrorIs(s.repo.DeleteTrip(context.Background(), uuid.Must(uuid.NewV4())), trips.ErrNotFound)
+}
+
+func (s *TripsSuite) TestPurge() {
+	ctx := context.Background()
+
+	purged := s.createTrip("purged", 1)
+	deleted := s.createTrip("deleted", 1)
+	kept := s.createTrip("kept", 1)
+
+	s.Require().NoError(s.repo.PurgeTrip(ctx, purged.ID))
+
+	_, err := s.repo.GetTripByID(ctx, purged.ID)
+	s.ErrorIs(err, trips.ErrNotFound)
+	s.ErrorIs(s.repo.PurgeTrip(ctx, purged.ID), trips.ErrNotFound)
+	s.ErrorIs(s.repo.DeleteTrip(ctx, purged.ID), trips.ErrNotFound)
+
+	s.Require().NoError(s.repo.DeleteTrip(ctx, deleted.ID))
+	s.NoError(s.repo.PurgeTrip(ctx, deleted.ID), "soft-deleted trip can be purged")
+
+	list, err := s.repo.ListTrips(ctx)
+	s.Require().NoError(err)
+	s.Equal([]uuid.UUID{kept.ID}, tripIDs(list))
+}
+
+func (s *TripsSuite) TestList() {
+	ctx := context.Background()
+
+	list, err := s.repo.ListTrips(ctx)
+	s.Require().NoError(err)
+	s.Empty(list)
+
+	var want []uuid.UUID
+
+	for _, userID := range []int64{1, 2, 1} {
+		want = append(want, s.createTrip("trip", userID).ID)
+
+		// Make creation time distinguishable.
+		time.Sleep(time.Millisecond)
+	}
+
+	list, err = s.repo.ListTrips(ctx)
+	s.Require().NoError(err)
+	s.Equal(want, tripIDs(list), "trips must be ordered by creation time")
+
+	list, err = s.repo.ListTripsByUser(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal([]uuid.UUID{want[0], want[2]}, tripIDs(list))
+
+	list, err = s.repo.ListTripsByUser(ctx, 42)
+	s.Require().NoError(err)
+	s.Empty(list)
+}
+
+func (s *TripsSuite) TestReturnsCopies() {
+	ctx := context.Background()
+
+	t := s.createTrip("name", 1)
+	t.Name = "changed after create"
+
+	got, err := s.repo.GetTripByID(ctx, t.ID)
+	s.Require().NoError(err)
+	s.Equal("name", got.Name)
+
+	got.Name = "changed after get"
+
+	list, err := s.repo.ListTrips(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(list, 1)
+	s.Equal("name", list[0].Name)
+}
+
+func (s *TripsSuite) TestConcurrentAccess() {
+	ctx := context.Background()
+
+	shared := s.createTrip("shared", 1)
+
+	var wg sync.WaitGroup
+
+	for i := range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			t, err := s.repo.CreateTrip(ctx, trips.CreateTripParams{Name: "trip", CreatedBy: int64(i)})
+			if !s.NoError(err) {
+				return
+			}
+
+			name := "updated"
+
+			s.NoError(s.repo.UpdateTrip(ctx, t.ID, trips.UpdateTripParams{Name: &name}))
+			s.NoError(s.repo.UpdateTrip(ctx, shared.ID, trips.UpdateTripParams{Name: &name}))
+
+			_, err = s.repo.ListTrips(ctx)
+			s.NoError(err)
+		}()
+	}
+
+	wg.Wait()
+
+	list, err := s.repo.ListTrips(ctx)
+	s.Require().NoError(err)
+	s.Len(list, concurrency+1)
+
+	for _, t := range list {
+		s.Equal("updated", t.Name)
+	}
+}
+
+func tripIDs(list []*trips.Trip) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(list))
+
+	for _, t := range list {
+		ids = append(ids, t.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,148 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/users"
+)
+
+// UsersSuite is a conformance suite for users.Repository.
+type UsersSuite struct {
+	suite.Suite
+
+	newRepo func(t *testing.T) users.Repository
+	repo    users.Repository
+}
+
+// RunUsers runs users.Repository conformance suite against repositories created by newRepo.
+// newRepo is called for each test and must return an empty repository.
+func RunUsers(t *testing.T, newRepo func(t *testing.T) users.Repository) {
+	suite.Run(t, &UsersSuite{newRepo: newRepo})
+}
+
+// SetupTest creates a fresh repository for each test.
+func (s *UsersSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+	s.Require().NotNil(s.repo)
+}
+
+func (s *UsersSuite) TestCreateAndGet() {
+	ctx := context.Background()
+
+	u := &users.User{ID: 1, Username: "rider", Firstname: "John", Lastname: "Doe"}
+
+	s.Require().NoError(s.repo.Create(ctx, u))
+
+	got, err := s.repo.GetBuID(ctx, u.ID)
+	s.Require().NoError(err)
+	s.Equal(u, got)
+}
+
+func (s *UsersSuite) TestCreateNil() {
+	s.Error(s.repo.Create(context.Background(), nil))
+}
+
+func (s *UsersSuite) TestCreateDuplicate() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.repo.Create(ctx, &users.User{ID: 1, Username: "first"}))
+
+	err := s.repo.Create(ctx, &users.User{ID: 1, Username: "second"})
+	s.ErrorIs(err, users.ErrAlreadyExists)
+
+	got, err := s.repo.GetBuID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal("first", got.Username, "existing user must not be overwritten")
+}
+
+func (s *UsersSuite) TestGetNotFound() {
+	_, err := s.repo.GetBuID(context.Background(), 42)
+	s.ErrorIs(err, users.ErrNotFound)
+}
+
+func (s *UsersSuite) TestList() {
+	ctx := context.Background()
+
+	list, err := s.repo.List(ctx)
+	s.Require().NoError(err)
+	s.Empty(list)
+
+	want := []*users.User{
+		{ID: 1, Username: "one"},
+		{ID: 2, Username: "two"},
+	}
+
+	for _, u := range want {
+		s.Require().NoError(s.repo.Create(ctx, u))
+	}
+
+	list, err = s.repo.List(ctx)
+	s.Require().NoError(err)
+	s.ElementsMatch(want, list)
+}
+
+func (s *UsersSuite) TestReturnsCopies() {
+	ctx := context.Background()
+
+	u := &users.User{ID: 1, Username: "rider"}
+
+	s.Require().NoError(s.repo.Create(ctx, u))
+
+	u.Username = "changed after create"
+
+	got, err := s.repo.GetBuID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal("rider", got.Username)
+
+	got.Username = "changed after get"
+
+	got, err = s.repo.GetBuID(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal("rider", got.Username)
+}
+
+func (s *UsersSuite) TestConcurrentCreate() {
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := range concurrency {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			s.NoError(s.repo.Create(ctx, &users.User{ID: int64(i + 1)}))
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			// All goroutines race for the same ID, only one must win.
+			err := s.repo.Create(ctx, &users.User{ID: 1000})
+			if err == nil {
+				created.Add(1)
+
+				return
+			}
+
+			s.ErrorIs(err, users.ErrAlreadyExists)
+		}()
+	}
+
+	wg.Wait()
+
+	s.Equal(int32(1), created.Load())
+
+	list, err := s.repo.List(ctx)
+	s.Require().NoError(err)
+	s.Len(list, concurrency+1)
+}
@@ -5,6 +5,7 @@
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 )
@@ -24,10 +25,15 @@ type Repository interface {
 	ListSessions(ctx context.Context) ([]*Session, error)
 	// GetSessionByUserID returns a session by user ID.
 	GetSessionByUserID(ctx context.Context, userID int64) (*Session, error)
-	// UpdateSession updates a session.
+	// UpdateSession updates a session, the session is marked as active.
 	UpdateSession(ctx context.Context, sess *Session) error
+	// TouchSession marks a session as active.
+	TouchSession(ctx context.Context, userID int64) error
 	// DeleteSession deletes a session.
 	DeleteSession(ctx context.Context, userID int64) error
+	// DeleteIdleSession deletes a session without activity since idleSince,
+	// ErrNotFound is returned if there is no such session.
+	DeleteIdleSession(ctx context.Context, userID int64, idleSince time.Time) error
 }
 
 // Session represents a session.
@@ -36,17 +42,38 @@ type Session struct {
 	UserID  int64      `db:"user_id"`
 	ChatID  int64      `db:"chat_id"`
 	StateID *uuid.UUID `db:"state_id"`
+	// UpdatedAt is the time of the last user activity in the session.
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
 func newSession(userID, chatID int64, stateID *uuid.UUID) *Session {
 	return &Session{
-		ID:      uuid.Must(uuid.NewV4()),
-		UserID:  userID,
-		ChatID:  chatID,
-		StateID: stateID,
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		ChatID:    chatID,
+		StateID:   copyUUID(stateID),
+		UpdatedAt: time.Now().UTC(),
 	}
 }
 
+// clone returns a deep copy of the session, so callers can't modify stored data.
+func (s *Session) clone() *Session {
+	cp := *s
+	cp.StateID = copyUUID(s.StateID)
+
+	return &cp
+}
+
+func copyUUID(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+
+	cp := *id
+
+	return &cp
+}
+
 // NewInMemory creates a new in-memory repository.
 func NewInMemory() Repository {
 	return &inMemoryRepository{
@@ -69,7 +96,7 @@ func (i *inMemoryRepository) ListSessions(_ context.Context) ([]*Session, error)
 	sessions := make([]*Session, 0, len(i.sessions))
 
 	for _, s := range i.sessions {
-		sessions = append(sessions, s)
+		sessions = append(sessions, s.clone())
 	}
 
 	return sessions, nil
@@ -99,7 +126,7 @@ func (i *inMemoryRepository) GetSessionByUserID(_ context.Context, userID int64)
 		return nil, ErrNotFound
 	}
 
-	return s, nil
+	return s.clone(), nil
 }
 
 func (i *inMemoryRepository) UpdateSession(_ context.Context, sess *Session) error {
@@ -116,7 +143,24 @@ func (i *inMemoryRepository) UpdateSession(_ context.Context, sess *Session) err
 		return ErrNotFound
 	}
 
-	i.sessions[userID] = sess
+	cp := sess.clone()
+	cp.UpdatedAt = time.Now().UTC()
+
+	i.sessions[userID] = cp
+
+	return nil
+}
+
+func (i *inMemoryRepository) TouchSession(_ context.Context, userID int64) error {
+	i.Lock()
+	defer i.Unlock()
+
+	s, ok := i.sessions[userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	s.UpdatedAt = time.Now().UTC()
 
 	return nil
 }
@@ -133,3 +177,17 @@ func (i *inMemoryRepository) DeleteSession(_ context.Context, userID int64) erro
 
 	return nil
 }
+
+func (i *inMemoryRepository) DeleteIdleSession(_ context.Context, userID int64, idleSince time.Time) error {
+	i.Lock()
+	defer i.Unlock()
+
+	s, ok := i.sessions[userID]
+	if !ok || !s.UpdatedAt.Before(idleSince) {
+		return ErrNotFound
+	}
+
+	delete(i.sessions, userID)
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package sessions_test
+
+import (
+	"testing"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/repotest"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
+)
+
+func TestInMemory(t *testing.T) {
+	repotest.RunSessions(t, func(_ *testing.T) sessions.Repository {
+		return sessions.NewInMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunSessions(t, func(t *testing.T) sessions.Repository {
+		return sessions.NewSQLite(repotest.NewSQLiteDB(t))
+	})
+}
@@ -0,0 +1,188 @@
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// sqliteRepository is a SQLite repository for sessions.
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a new SQLite repository for sessions.
+func NewSQLite(db *sql.DB) Repository {
+	return &sqliteRepository{
+		db: db,
+	}
+}
+
+const selectSessions = `SELECT id, user_id, chat_id, state_id, updated_at FROM sessions`
+
+func (s *sqliteRepository) CreateSession(ctx context.Context, userID, chatID int64, stateID *uuid.UUID) error {
+	sess := newSession(userID, chatID, stateID)
+
+	const query = `
+		INSERT INTO sessions (id, user_id, chat_id, state_id, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query, sess.ID, sess.UserID, sess.ChatID, nullUUID(sess.StateID), sess.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return ErrAlreadyExists
+	}
+
+	return nil
+}
+
+func (s *sqliteRepository) ListSessions(ctx context.Context) ([]*Session, error) {
+	rows, err := s.db.QueryContext(ctx, selectSessions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	sessions := make([]*Session, 0)
+
+	for rows.Next() {
+		sess, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, sess)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (s *sqliteRepository) GetSessionByUserID(ctx context.Context, userID int64) (*Session, error) {
+	row := s.db.QueryRowContext(ctx, selectSessions+` WHERE user_id = ?`, userID)
+
+	sess, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+func (s *sqliteRepository) UpdateSession(ctx context.Context, sess *Session) error {
+	if sess == nil {
+		return errors.New("session is nil")
+	}
+
+	const query = `UPDATE sessions SET id = ?, chat_id = ?, state_id = ?, updated_at = ? WHERE user_id = ?`
+
+	res, err := s.db.ExecContext(ctx, query, sess.ID, sess.ChatID, nullUUID(sess.StateID), time.Now().UTC(), sess.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func (s *sqliteRepository) TouchSession(ctx context.Context, userID int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE sessions SET updated_at = ? WHERE user_id = ?`, time.Now().UTC(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func (s *sqliteRepository) DeleteSession(ctx context.Context, userID int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func (s *sqliteRepository) DeleteIdleSession(ctx context.Context, userID int64, idleSince time.Time) error {
+	const query = `DELETE FROM sessions WHERE user_id = ? AND (updated_at IS NULL OR updated_at < ?)`
+
+	res, err := s.db.ExecContext(ctx, query, userID, idleSince.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to delete idle session: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row scanner) (*Session, error) {
+	var (
+		sess      Session
+		stateID   uuid.NullUUID
+		updatedAt sql.NullTime
+	)
+
+	if err := row.Scan(&sess.ID, &sess.UserID, &sess.ChatID, &stateID, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("failed to scan session: %w", err)
+	}
+
+	if stateID.Valid {
+		sess.StateID = &stateID.UUID
+	}
+
+	if updatedAt.Valid {
+		sess.UpdatedAt = updatedAt.Time
+	}
+
+	return &sess, nil
+}
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
@@ -0,0 +1,186 @@
+package states
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// sqliteRepository is a SQLite repository for states.
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a new SQLite repository for states.
+func NewSQLite(db *sql.DB) Repository {
+	return &sqliteRepository{
+		db: db,
+	}
+}
+
+const selectStates = `SELECT id, user_id, trip_id, command, step FROM states`
+
+func (s *sqliteRepository) CreateState(ctx context.Context, params CreateParams) (*State, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate id: %w", err)
+	}
+
+	newState := &State{
+		ID:      id,
+		UserID:  params.UserID,
+		TripID:  copyUUID(params.TripID),
+		Command: params.Command,
+		Step:    params.Step,
+	}
+
+	const query = `
+		INSERT INTO states (id, user_id, trip_id, command, step)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query,
+		newState.ID, newState.UserID, nullUUID(newState.TripID), newState.Command, newState.Step)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert state: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		// Each user can only have one state at a time.
+		existing, err := s.GetStateByUserID(ctx, params.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		return existing, ErrAlreadyExists
+	}
+
+	return newState, nil
+}
+
+func (s *sqliteRepository) ListStates(ctx context.Context) ([]*State, error) {
+	rows, err := s.db.QueryContext(ctx, selectStates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	states := make([]*State, 0)
+
+	for rows.Next() {
+		st, err := scanState(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		states = append(states, st)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate states: %w", err)
+	}
+
+	return states, nil
+}
+
+func (s *sqliteRepository) GetStateByID(ctx context.Context, id uuid.UUID) (*State, error) {
+	return s.getState(ctx, selectStates+` WHERE id = ?`, id)
+}
+
+func (s *sqliteRepository) GetStateByUserID(ctx context.Context, userID int64) (*State, error) {
+	return s.getState(ctx, selectStates+` WHERE user_id = ?`, userID)
+}
+
+func (s *sqliteRepository) getState(ctx context.Context, query string, args ...any) (*State, error) {
+	st, err := scanState(s.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return st, nil
+}
+
+func (s *sqliteRepository) UpdateState(ctx context.Context, newState *State) error {
+	if newState == nil {
+		return errors.New("state is nil")
+	}
+
+	st, err := s.GetStateByID(ctx, newState.ID)
+	if err != nil {
+		return err
+	}
+
+	if st.UserID != newState.UserID {
+		return fmt.Errorf("state user id mismatch: %d != %d", st.UserID, newState.UserID)
+	}
+
+	if st.equal(newState) {
+		return ErrNoChanges
+	}
+
+	const query = `UPDATE states SET trip_id = ?, command = ?, step = ? WHERE id = ?`
+
+	res, err := s.db.ExecContext(ctx, query, nullUUID(newState.TripID), newState.Command, newState.Step, newState.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanState(row scanner) (*State, error) {
+	var (
+		st     State
+		tripID uuid.NullUUID
+	)
+
+	if err := row.Scan(&st.ID, &st.UserID, &tripID, &st.Command, &st.Step); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("failed to scan state: %w", err)
+	}
+
+	if tripID.Valid {
+		st.TripID = &tripID.UUID
+	}
+
+	return &st, nil
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
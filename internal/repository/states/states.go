@@ -30,6 +30,7 @@ type Repository interface {
 	// GetStateByUserID returns a state by user ID.
 	GetStateByUserID(ctx context.Context, userID int64) (*State, error)
 	// UpdateState updates a state.
+	// ErrNoChanges is returned when stored state already has the same values.
 	UpdateState(ctx context.Context, state *State) error
 }
 
@@ -42,6 +43,37 @@ type State struct {
 	Step    uint       `db:"step"`
 }
 
+// clone returns a deep copy of the state, so callers can't modify stored data.
+func (s *State) clone() *State {
+	cp := *s
+	cp.TripID = copyUUID(s.TripID)
+
+	return &cp
+}
+
+func copyUUID(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+
+	cp := *id
+
+	return &cp
+}
+
+// equal reports whether states have the same values.
+func (s *State) equal(other *State) bool {
+	if s.ID != other.ID || s.UserID != other.UserID || s.Command != other.Command || s.Step != other.Step {
+		return false
+	}
+
+	if s.TripID == nil || other.TripID == nil {
+		return s.TripID == other.TripID
+	}
+
+	return *s.TripID == *other.TripID
+}
+
 // CreateParams contains the parameters for CreateState.
 type CreateParams struct {
 	UserID  int64
@@ -76,7 +108,7 @@ func (i *inMemoryRepository) CreateState(_ context.Context, params CreateParams)
 	newState := &State{
 		ID:      id,
 		UserID:  params.UserID,
-		TripID:  params.TripID,
+		TripID:  copyUUID(params.TripID),
 		Command: params.Command,
 		Step:    params.Step,
 	}
@@ -84,13 +116,13 @@ func (i *inMemoryRepository) CreateState(_ context.Context, params CreateParams)
 	// Each user can only have one state at a time.
 	for _, state := range i.states {
 		if state.UserID == newState.UserID {
-			return state, ErrAlreadyExists
+			return state.clone(), ErrAlreadyExists
 		}
 	}
 
 	i.states[newState.ID] = newState
 
-	return newState, nil
+	return newState.clone(), nil
 }
 
 func (i *inMemoryRepository) ListStates(_ context.Context) ([]*State, error) {
@@ -100,7 +132,7 @@ func (i *inMemoryRepository) ListStates(_ context.Context) ([]*State, error) {
 	states := make([]*State, 0, len(i.states))
 
 	for _, state := range i.states {
-		states = append(states, state)
+		states = append(states, state.clone())
 	}
 
 	return states, nil
@@ -115,7 +147,7 @@ func (i *inMemoryRepository) GetStateByID(_ context.Context, id uuid.UUID) (*Sta
 		return nil, ErrNotFound
 	}
 
-	return state, nil
+	return state.clone(), nil
 }
 
 func (i *inMemoryRepository) GetStateByUserID(_ context.Context, userID int64) (*State, error) {
@@ -124,7 +156,7 @@ func (i *inMemoryRepository) GetStateByUserID(_ context.Context, userID int64) (
 
 	for _, state := range i.states {
 		if state.UserID == userID {
-			return state, nil
+			return state.clone(), nil
 		}
 	}
 
@@ -132,6 +164,10 @@ func (i *inMemoryRepository) GetStateByUserID(_ context.Context, userID int64) (
 }
 
 func (i *inMemoryRepository) UpdateState(_ context.Context, newState *State) error {
+	if newState == nil {
+		return errors.New("state is nil")
+	}
+
 	i.Lock()
 	defer i.Unlock()
 
@@ -148,11 +184,11 @@ func (i *inMemoryRepository) UpdateState(_ context.Context, newState *State) err
 		return fmt.Errorf("state user id mismatch: %d != %d", st.UserID, newState.UserID)
 	}
 
-	if st == newState {
+	if st.equal(newState) {
 		return ErrNoChanges
 	}
 
-	i.states[st.ID] = newState
+	i.states[st.ID] = newState.clone()
 
 	return nil
 }
@@ -0,0 +1,20 @@
+package states_test
+
+import (
+	"testing"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/repotest"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
+)
+
+func TestInMemory(t *testing.T) {
+	repotest.RunStates(t, func(_ *testing.T) states.Repository {
+		return states.NewInMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunStates(t, func(t *testing.T) states.Repository {
+		return states.NewSQLite(repotest.NewSQLiteDB(t))
+	})
+}
@@ -0,0 +1,353 @@
+package trips
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// sqliteRepository is a SQLite repository for trips.
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a new SQLite repository for trips.
+func NewSQLite(db *sql.DB) Repository {
+	return &sqliteRepository{
+		db: db,
+	}
+}
+
+const selectTrips = `
+	SELECT id, name, description, starts_at, timezone, created_at, updated_at, deleted_at, created_by, chat_id,
+	       message_id, status, published_at, cancelled_at, started_at, finished_at, archived_at, cancel_reason,
+	       meeting_label, meeting_latitude, meeting_longitude, meeting_message_id,
+	       track_file_id, track_file_name, track_distance, track_has_elevation, track_elevation_gain,
+	       track_elevation_loss, track_max_gradient, track_min_latitude, track_min_longitude,
+	       track_max_latitude, track_max_longitude, route_provider, route_id, route_url
+	FROM trips`
+
+// statusColumns are columns with the time a trip reached the status.
+var statusColumns = map[string]string{
+	StatusPublished: "published_at",
+	StatusCancelled: "cancelled_at",
+	StatusStarted:   "started_at",
+	StatusFinished:  "finished_at",
+	StatusArchived:  "archived_at",
+}
+
+func (s *sqliteRepository) CreateTrip(ctx context.Context, params CreateTripParams) (*Trip, error) {
+	now := time.Now().UTC()
+
+	trip := &Trip{
+		ID:          uuid.Must(uuid.NewV4()),
+		Name:        params.Name,
+		Description: params.Description,
+		StartsAt:    params.StartsAt,
+		Timezone:    params.Timezone,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DeletedAt:   time.Time{},
+		CreatedBy:   params.CreatedBy,
+		Status:      StatusDraft,
+	}
+
+	const query = `
+		INSERT INTO trips (id, name, description, starts_at, timezone, created_at, updated_at, created_by, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		trip.ID, trip.Name, trip.Description, nullTime(trip.StartsAt), trip.Timezone,
+		trip.CreatedAt, trip.UpdatedAt, trip.CreatedBy, trip.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert trip: %w", err)
+	}
+
+	return trip, nil
+}
+
+func (s *sqliteRepository) ListTrips(ctx context.Context) ([]*Trip, error) {
+	return s.listTrips(ctx, selectTrips+` WHERE deleted_at IS NULL ORDER BY created_at`)
+}
+
+func (s *sqliteRepository) ListTripsByUser(ctx context.Context, userID int64) ([]*Trip, error) {
+	return s.listTrips(ctx, selectTrips+` WHERE created_by = ? AND deleted_at IS NULL ORDER BY created_at`, userID)
+}
+
+func (s *sqliteRepository) listTrips(ctx context.Context, query string, args ...any) ([]*Trip, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trips: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	trips := make([]*Trip, 0)
+
+	for rows.Next() {
+		t, err := scanTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		trips = append(trips, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+func (s *sqliteRepository) GetTripByID(ctx context.Context, id uuid.UUID) (*Trip, error) {
+	t, err := scanTrip(s.db.QueryRowContext(ctx, selectTrips+` WHERE id = ? AND deleted_at IS NULL`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *sqliteRepository) UpdateTrip(ctx context.Context, id uuid.UUID, params UpdateTripParams) error {
+	const query = `
+		UPDATE trips
+		SET name                 = COALESCE(?, name),
+		    description          = COALESCE(?, description),
+		    starts_at            = CASE WHEN ? THEN ? ELSE starts_at END,
+		    timezone             = COALESCE(?, timezone),
+		    chat_id              = COALESCE(?, chat_id),
+		    message_id           = COALESCE(?, message_id),
+		    cancel_reason        = COALESCE(?, cancel_reason),
+		    meeting_label        = CASE WHEN ? THEN ? ELSE meeting_label END,
+		    meeting_latitude     = CASE WHEN ? THEN ? ELSE meeting_latitude END,
+		    meeting_longitude    = CASE WHEN ? THEN ? ELSE meeting_longitude END,
+		    meeting_message_id   = COALESCE(?, meeting_message_id),
+		    track_file_id        = CASE WHEN ? THEN ? ELSE track_file_id END,
+		    track_file_name      = CASE WHEN ? THEN ? ELSE track_file_name END,
+		    track_distance       = CASE WHEN ? THEN ? ELSE track_distance END,
+		    track_has_elevation  = CASE WHEN ? THEN ? ELSE track_has_elevation END,
+		    track_elevation_gain = CASE WHEN ? THEN ? ELSE track_elevation_gain END,
+		    track_elevation_loss = CASE WHEN ? THEN ? ELSE track_elevation_loss END,
+		    track_max_gradient   = CASE WHEN ? THEN ? ELSE track_max_gradient END,
+		    track_min_latitude   = CASE WHEN ? THEN ? ELSE track_min_latitude END,
+		    track_min_longitude  = CASE WHEN ? THEN ? ELSE track_min_longitude END,
+		    track_max_latitude   = CASE WHEN ? THEN ? ELSE track_max_latitude END,
+		    track_max_longitude  = CASE WHEN ? THEN ? ELSE track_max_longitude END,
+		    route_provider       = CASE WHEN ? THEN ? ELSE route_provider END,
+		    route_id             = CASE WHEN ? THEN ? ELSE route_id END,
+		    route_url            = CASE WHEN ? THEN ? ELSE route_url END,
+		    updated_at           = ?
+		WHERE id = ? AND deleted_at IS NULL`
+
+	var (
+		startsAt     sql.NullTime
+		meetingLabel string
+		meetingLat   sql.NullFloat64
+		meetingLon   sql.NullFloat64
+		setMeeting   = params.MeetingPoint != nil
+		track        Track
+		setTrack     = params.Track != nil
+		routeLink    RouteLink
+		setRoute     = params.RouteLink != nil
+	)
+
+	if params.StartsAt != nil {
+		startsAt = nullTime(*params.StartsAt)
+	}
+
+	if mp := params.MeetingPoint; mp != nil {
+		meetingLabel = mp.Label
+
+		if mp.HasLocation {
+			meetingLat = sql.NullFloat64{Float64: mp.Latitude, Valid: true}
+			meetingLon = sql.NullFloat64{Float64: mp.Longitude, Valid: true}
+		}
+	}
+
+	if params.Track != nil {
+		track = *params.Track
+	}
+
+	if params.RouteLink != nil {
+		routeLink = *params.RouteLink
+	}
+
+	res, err := s.db.ExecContext(ctx, query,
+		params.Name, params.Description, params.StartsAt != nil, startsAt, params.Timezone,
+		params.ChatID, params.MessageID, params.CancelReason,
+		setMeeting, meetingLabel, setMeeting, meetingLat, setMeeting, meetingLon, params.MeetingMessageID,
+		setTrack, track.FileID, setTrack, track.FileName, setTrack, track.Distance,
+		setTrack, track.HasElevation, setTrack, track.ElevationGain, setTrack, track.ElevationLoss,
+		setTrack, track.MaxGradient, setTrack, track.MinLatitude, setTrack, track.MinLongitude,
+		setTrack, track.MaxLatitude, setTrack, track.MaxLongitude,
+		setRoute, routeLink.Provider, setRoute, routeLink.ID, setRoute, routeLink.URL,
+		time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update trip: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func (s *sqliteRepository) SetStatus(ctx context.Context, id uuid.UUID, from, to string) error {
+	return s.setStatus(ctx, id, from, to, nil)
+}
+
+func (s *sqliteRepository) CancelTrip(ctx context.Context, id uuid.UUID, from, reason string) error {
+	return s.setStatus(ctx, id, from, StatusCancelled, map[string]any{
+		"cancel_reason": reason,
+	})
+}
+
+// setStatus changes the trip status, columns are updated in the same statement.
+func (s *sqliteRepository) setStatus(ctx context.Context, id uuid.UUID, from, to string, columns map[string]any) error {
+	column, ok := statusColumns[to]
+	if !ok && to != StatusDraft {
+		return ErrUnknownStatus
+	}
+
+	now := time.Now().UTC()
+
+	query := `UPDATE trips SET status = ?, updated_at = ?`
+	args := []any{to, now}
+
+	if column != "" {
+		query += `, ` + column + ` = ?`
+		args = append(args, now)
+	}
+
+	for name, value := range columns {
+		query += `, ` + name + ` = ?`
+		args = append(args, value)
+	}
+
+	query += ` WHERE id = ? AND status = ? AND deleted_at IS NULL`
+	args = append(args, id, from)
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to set trip status: %w", err)
+	}
+
+	if err = checkAffected(res); err == nil {
+		return nil
+	}
+
+	// Nothing is updated, either the trip doesn't exist or its status differs.
+	if _, err = s.GetTripByID(ctx, id); err != nil {
+		return err
+	}
+
+	return ErrStatusConflict
+}
+
+func (s *sqliteRepository) DeleteTrip(ctx context.Context, id uuid.UUID) error {
+	const query = `UPDATE trips SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	res, err := s.db.ExecContext(ctx, query, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete trip: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+func (s *sqliteRepository) PurgeTrip(ctx context.Context, id uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM trips WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to purge trip: %w", err)
+	}
+
+	return checkAffected(res)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTrip(row scanner) (*Trip, error) {
+	var (
+		t         Trip
+		startsAt  sql.NullTime
+		deletedAt sql.NullTime
+		chatID    sql.NullInt64
+		messageID sql.NullInt64
+		meetingAt [2]sql.NullFloat64
+		meetingID sql.NullInt64
+		reachedAt [5]sql.NullTime
+	)
+
+	err := row.Scan(&t.ID, &t.Name, &t.Description, &startsAt, &t.Timezone,
+		&t.CreatedAt, &t.UpdatedAt, &deletedAt, &t.CreatedBy, &chatID,
+		&messageID, &t.Status, &reachedAt[0], &reachedAt[1], &reachedAt[2], &reachedAt[3], &reachedAt[4], &t.CancelReason,
+		&t.MeetingPoint.Label, &meetingAt[0], &meetingAt[1], &meetingID,
+		&t.Track.FileID, &t.Track.FileName, &t.Track.Distance, &t.Track.HasElevation, &t.Track.ElevationGain,
+		&t.Track.ElevationLoss, &t.Track.MaxGradient, &t.Track.MinLatitude, &t.Track.MinLongitude,
+		&t.Track.MaxLatitude, &t.Track.MaxLongitude, &t.RouteLink.Provider, &t.RouteLink.ID, &t.RouteLink.URL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("failed to scan trip: %w", err)
+	}
+
+	if startsAt.Valid {
+		t.StartsAt = startsAt.Time
+	}
+
+	if deletedAt.Valid {
+		t.DeletedAt = deletedAt.Time
+	}
+
+	t.ChatID = chatID.Int64
+	t.MessageID = int(messageID.Int64)
+	t.MeetingMessageID = int(meetingID.Int64)
+
+	if meetingAt[0].Valid && meetingAt[1].Valid {
+		t.MeetingPoint.HasLocation = true
+		t.MeetingPoint.Latitude = meetingAt[0].Float64
+		t.MeetingPoint.Longitude = meetingAt[1].Float64
+	}
+
+	for i, dst := range []*time.Time{&t.PublishedAt, &t.CancelledAt, &t.StartedAt, &t.FinishedAt, &t.ArchivedAt} {
+		if reachedAt[i].Valid {
+			*dst = reachedAt[i].Time
+		}
+	}
+
+	return &t, nil
+}
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// nullTime converts zero time to NULL, non-zero time is stored in UTC.
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
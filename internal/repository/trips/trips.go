@@ -4,50 +4,180 @@
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
 )
 
-// ErrNotFound is returned when a trip is not found.
-var ErrNotFound = errors.New("trip not found")
+var (
+	// ErrNotFound is returned when a trip is not found.
+	ErrNotFound = errors.New("trip not found")
+	// ErrStatusConflict is returned when a trip is not in the expected status.
+	ErrStatusConflict = errors.New("trip status conflict")
+	// ErrUnknownStatus is returned when a status is not known.
+	ErrUnknownStatus = errors.New("unknown trip status")
+)
+
+// Trip statuses.
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusCancelled = "cancelled"
+	StatusStarted   = "started"
+	StatusFinished  = "finished"
+	StatusArchived  = "archived"
+)
 
 // Repository provides access to the trip storage.
 type Repository interface {
 	// CreateTrip creates a new trip.
-	CreateTrip(ctx context.Context, name, date, description string, createdBy int64) (*Trip, error)
-	// ListTrips returns all trips.
+	CreateTrip(ctx context.Context, params CreateTripParams) (*Trip, error)
+	// ListTrips returns all not deleted trips ordered by creation time.
 	ListTrips(ctx context.Context) ([]*Trip, error)
-	// ListTripsByUser returns all trips created by a user.
+	// ListTripsByUser returns all not deleted trips created by a user ordered by creation time.
 	ListTripsByUser(ctx context.Context, userID int64) ([]*Trip, error)
 	// GetTripByID returns a trip by ID.
 	GetTripByID(ctx context.Context, id uuid.UUID) (*Trip, error)
 	// UpdateTrip updates a trip.
 	UpdateTrip(ctx context.Context, id uuid.UUID, params UpdateTripParams) error
-	// DeleteTrip deletes a trip.
+	// DeleteTrip soft-deletes a trip, so it is not returned anymore.
 	DeleteTrip(ctx context.Context, id uuid.UUID) error
+	// PurgeTrip permanently removes a trip, soft-deleted trips are removed as well.
+	PurgeTrip(ctx context.Context, id uuid.UUID) error
+	// SetStatus changes the trip status and records the time the trip reached it.
+	// ErrStatusConflict is returned when the trip is not in the from status, e.g. it was changed concurrently.
+	// Allowed transitions are not checked, it's up to the caller.
+	SetStatus(ctx context.Context, id uuid.UUID, from, to string) error
+	// CancelTrip changes the trip status from the from status to cancelled and records the reason at once.
+	// ErrStatusConflict is returned as by SetStatus.
+	CancelTrip(ctx context.Context, id uuid.UUID, from, reason string) error
+}
+
+// CreateTripParams contains the parameters for CreateTrip.
+type CreateTripParams struct {
+	Name        string
+	Description string
+	// StartsAt is a trip start time, zero if not known yet.
+	StartsAt time.Time
+	// Timezone is an IANA name of the timezone the trip takes place in.
+	Timezone  string
+	CreatedBy int64
 }
 
 // UpdateTripParams contains the parameters for UpdateTrip.
 type UpdateTripParams struct {
 	Name        *string
-	Date        *string
 	Description *string
-	Completed   *bool
+	StartsAt    *time.Time
+	Timezone    *string
+	// ChatID is the chat the trip is announced in.
+	ChatID *int64
+	// MessageID is the announcement message in the chat.
+	MessageID *int
+	// CancelReason is the reason the trip was cancelled for.
+	CancelReason *string
+	// MeetingPoint replaces the trip meeting point, a zero meeting point removes it.
+	MeetingPoint *MeetingPoint
+	// MeetingMessageID is the meeting point location message in the chat.
+	MeetingMessageID *int
+	// Track replaces the trip track, a zero track removes it.
+	Track *Track
+	// RouteLink replaces the trip route link, a zero link removes it.
+	RouteLink *RouteLink
+}
+
+// MeetingPoint is a place riders gather at before the trip.
+type MeetingPoint struct {
+	// Label is a venue name or an address, empty when only coordinates are known.
+	Label string
+	// HasLocation is false when only the label is known, e.g. a typed address.
+	HasLocation bool
+	Latitude    float64
+	Longitude   float64
+}
+
+// Track is an uploaded GPX file of the trip route with stats computed from it.
+// Distances and elevations are in meters.
+type Track struct {
+	// FileID is the Telegram file ID, empty when there is no track.
+	FileID        string
+	FileName      string
+	Distance      float64
+	HasElevation  bool
+	ElevationGain float64
+	ElevationLoss float64
+	// MaxGradient is the steepest climb in percent.
+	MaxGradient float64
+	// Bounding box of the route.
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// RouteLink is a link to the trip route on a route planner.
+type RouteLink struct {
+	// Provider is the route planner name, e.g. Strava.
+	Provider string
+	// ID is the route ID at the provider.
+	ID  string
+	URL string
 }
 
 // Trip represents a trip.
 type Trip struct {
-	ID          uuid.UUID
-	Name        string
-	Date        string
-	Description string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	DeletedAt   time.Time
-	CreatedBy   int64
-	Completed   bool
+	ID          uuid.UUID `db:"id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	StartsAt    time.Time `db:"starts_at"`
+	Timezone    string    `db:"timezone"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+	DeletedAt   time.Time `db:"deleted_at"`
+	CreatedBy   int64     `db:"created_by"`
+	// ChatID is the chat the trip is announced in, zero until the trip is published.
+	ChatID int64 `db:"chat_id"`
+	// MessageID is the announcement message in the chat, zero until the trip is published.
+	MessageID int    `db:"message_id"`
+	Status    string `db:"status"`
+	// Times the trip reached the statuses, zero if it didn't.
+	PublishedAt time.Time `db:"published_at"`
+	CancelledAt time.Time `db:"cancelled_at"`
+	StartedAt   time.Time `db:"started_at"`
+	FinishedAt  time.Time `db:"finished_at"`
+	ArchivedAt  time.Time `db:"archived_at"`
+	// CancelReason is the reason the trip was cancelled for, empty if it's not cancelled.
+	CancelReason string `db:"cancel_reason"`
+	// MeetingPoint is zero when it's not set.
+	MeetingPoint MeetingPoint
+	// MeetingMessageID is the meeting point location message in the chat, zero if there is none.
+	MeetingMessageID int `db:"meeting_message_id"`
+	// Track is zero when it's not uploaded.
+	Track Track
+	// RouteLink is zero when it's not set.
+	RouteLink RouteLink
+}
+
+// statusTime returns the field with the time the trip reached the status, nil for the draft status.
+func (t *Trip) statusTime(status string) (*time.Time, error) {
+	switch status {
+	case StatusDraft:
+		return nil, nil
+	case StatusPublished:
+		return &t.PublishedAt, nil
+	case StatusCancelled:
+		return &t.CancelledAt, nil
+	case StatusStarted:
+		return &t.StartedAt, nil
+	case StatusFinished:
+		return &t.FinishedAt, nil
+	case StatusArchived:
+		return &t.ArchivedAt, nil
+	default:
+		return nil, ErrUnknownStatus
+	}
 }
 
 type inMemoryRepository struct {
@@ -84,6 +214,19 @@ func (i *inMemoryRepository) DeleteTrip(_ context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (i *inMemoryRepository) PurgeTrip(_ context.Context, id uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.trips[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(i.trips, id)
+
+	return nil
+}
+
 func (i *inMemoryRepository) UpdateTrip(_ context.Context, id uuid.UUID, params UpdateTripParams) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
@@ -101,16 +244,44 @@ func (i *inMemoryRepository) UpdateTrip(_ context.Context, id uuid.UUID, params
 		trip.Name = *params.Name
 	}
 
-	if params.Date != nil {
-		trip.Date = *params.Date
+	if params.StartsAt != nil {
+		trip.StartsAt = *params.StartsAt
+	}
+
+	if params.Timezone != nil {
+		trip.Timezone = *params.Timezone
 	}
 
 	if params.Description != nil {
 		trip.Description = *params.Description
 	}
 
-	if params.Completed != nil {
-		trip.Completed = *params.Completed
+	if params.ChatID != nil {
+		trip.ChatID = *params.ChatID
+	}
+
+	if params.MessageID != nil {
+		trip.MessageID = *params.MessageID
+	}
+
+	if params.CancelReason != nil {
+		trip.CancelReason = *params.CancelReason
+	}
+
+	if params.MeetingPoint != nil {
+		trip.MeetingPoint = *params.MeetingPoint
+	}
+
+	if params.MeetingMessageID != nil {
+		trip.MeetingMessageID = *params.MeetingMessageID
+	}
+
+	if params.Track != nil {
+		trip.Track = *params.Track
+	}
+
+	if params.RouteLink != nil {
+		trip.RouteLink = *params.RouteLink
 	}
 
 	trip.UpdatedAt = time.Now()
@@ -120,25 +291,71 @@ func (i *inMemoryRepository) UpdateTrip(_ context.Context, id uuid.UUID, params
 	return nil
 }
 
-func (i *inMemoryRepository) CreateTrip(_ context.Context, name, date, description string, createdBy int64) (*Trip, error) {
+func (i *inMemoryRepository) SetStatus(_ context.Context, id uuid.UUID, from, to string) error {
+	return i.setStatus(id, from, to, func(*Trip) {})
+}
+
+func (i *inMemoryRepository) CancelTrip(_ context.Context, id uuid.UUID, from, reason string) error {
+	return i.setStatus(id, from, StatusCancelled, func(trip *Trip) {
+		trip.CancelReason = reason
+	})
+}
+
+// setStatus changes the trip status, update changes other fields along with it.
+func (i *inMemoryRepository) setStatus(id uuid.UUID, from, to string, update func(trip *Trip)) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	trip, ok := i.trips[id]
+	if !ok || !trip.DeletedAt.IsZero() {
+		return ErrNotFound
+	}
+
+	reachedAt, err := trip.statusTime(to)
+	if err != nil {
+		return err
+	}
+
+	if trip.Status != from {
+		return ErrStatusConflict
+	}
+
+	now := time.Now()
+
+	trip.Status = to
+	trip.UpdatedAt = now
+
+	if reachedAt != nil {
+		*reachedAt = now
+	}
+
+	update(trip)
+
+	return nil
+}
+
+func (i *inMemoryRepository) CreateTrip(_ context.Context, params CreateTripParams) (*Trip, error) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
 	trip := &Trip{
 		ID:          uuid.Must(uuid.NewV4()),
-		Name:        name,
-		Date:        date,
-		Description: description,
+		Name:        params.Name,
+		Description: params.Description,
+		StartsAt:    params.StartsAt,
+		Timezone:    params.Timezone,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 		DeletedAt:   time.Time{},
-		CreatedBy:   createdBy,
-		Completed:   false,
+		CreatedBy:   params.CreatedBy,
+		Status:      StatusDraft,
 	}
 
 	i.trips[trip.ID] = trip
 
-	return trip, nil
+	cp := *trip
+
+	return &cp, nil
 }
 
 func (i *inMemoryRepository) ListTrips(_ context.Context) ([]*Trip, error) {
@@ -149,10 +366,14 @@ func (i *inMemoryRepository) ListTrips(_ context.Context) ([]*Trip, error) {
 
 	for _, t := range i.trips {
 		if t.DeletedAt.IsZero() {
-			trips = append(trips, t)
+			cp := *t
+
+			trips = append(trips, &cp)
 		}
 	}
 
+	sortByCreation(trips)
+
 	return trips, nil
 }
 
@@ -161,14 +382,18 @@ func (i *inMemoryRepository) ListTripsByUser(_ context.Context, userID int64) ([
 	i.mu.RLock()
 	defer i.mu.RUnlock()
 
-	var trips []*Trip
+	trips := make([]*Trip, 0)
 
 	for _, t := range i.trips {
 		if t.CreatedBy == userID && t.DeletedAt.IsZero() {
-			trips = append(trips, t)
+			cp := *t
+
+			trips = append(trips, &cp)
 		}
 	}
 
+	sortByCreation(trips)
+
 	return trips, nil
 }
 
@@ -185,5 +410,14 @@ func (i *inMemoryRepository) GetTripByID(_ context.Context, id uuid.UUID) (*Trip
 		return nil, ErrNotFound
 	}
 
-	return trip, nil
+	cp := *trip
+
+	return &cp, nil
+}
+
+// sortByCreation sorts trips by creation time, oldest first.
+func sortByCreation(trips []*Trip) {
+	sort.SliceStable(trips, func(i, j int) bool {
+		return trips[i].CreatedAt.Before(trips[j].CreatedAt)
+	})
 }
@@ -0,0 +1,20 @@
+package trips_test
+
+import (
+	"testing"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/repotest"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+func TestInMemory(t *testing.T) {
+	repotest.RunTrips(t, func(_ *testing.T) trips.Repository {
+		return trips.NewInMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunTrips(t, func(t *testing.T) trips.Repository {
+		return trips.NewSQLite(repotest.NewSQLiteDB(t))
+	})
+}
@@ -0,0 +1,95 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// sqliteRepository is a SQLite repository for users.
+type sqliteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLite creates a new SQLite repository for users.
+func NewSQLite(db *sql.DB) Repository {
+	return &sqliteRepository{
+		db: db,
+	}
+}
+
+func (s *sqliteRepository) Create(ctx context.Context, user *User) error {
+	if user == nil {
+		return errors.New("user is nil")
+	}
+
+	const query = `
+		INSERT INTO users (id, username, firstname, lastname)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query, user.ID, user.Username, user.Firstname, user.Lastname)
+	if err != nil {
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return ErrAlreadyExists
+	}
+
+	return nil
+}
+
+func (s *sqliteRepository) GetBuID(ctx context.Context, id int64) (*User, error) {
+	const query = `SELECT id, username, firstname, lastname FROM users WHERE id = ?`
+
+	var u User
+
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Username, &u.Firstname, &u.Lastname)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &u, nil
+}
+
+func (s *sqliteRepository) List(ctx context.Context) ([]*User, error) {
+	const query = `SELECT id, username, firstname, lastname FROM users ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	users := make([]*User, 0)
+
+	for rows.Next() {
+		var u User
+
+		if err = rows.Scan(&u.ID, &u.Username, &u.Firstname, &u.Lastname); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		users = append(users, &u)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, nil
+}
@@ -26,10 +26,10 @@ type Repository interface {
 
 // User represents a user.
 type User struct {
-	ID        int64
-	Username  string
-	Firstname string
-	Lastname  string
+	ID        int64  `db:"id"`
+	Username  string `db:"username"`
+	Firstname string `db:"firstname"`
+	Lastname  string `db:"lastname"`
 }
 
 // inMemoryRepository is an in-memory repository for users.
@@ -48,6 +48,10 @@ func NewInMemory() Repository {
 }
 
 func (i *inMemoryRepository) Create(_ context.Context, user *User) error {
+	if user == nil {
+		return errors.New("user is nil")
+	}
+
 	i.Lock()
 	defer i.Unlock()
 
@@ -55,7 +59,9 @@ func (i *inMemoryRepository) Create(_ context.Context, user *User) error {
 		return ErrAlreadyExists
 	}
 
-	i.users[user.ID] = user
+	u := *user
+
+	i.users[user.ID] = &u
 
 	return nil
 }
@@ -69,7 +75,9 @@ func (i *inMemoryRepository) GetBuID(_ context.Context, id int64) (*User, error)
 		return nil, ErrNotFound
 	}
 
-	return u, nil
+	cp := *u
+
+	return &cp, nil
 }
 
 func (i *inMemoryRepository) List(_ context.Context) ([]*User, error) {
@@ -79,7 +87,9 @@ func (i *inMemoryRepository) List(_ context.Context) ([]*User, error) {
 	users := make([]*User, 0, len(i.users))
 
 	for _, u := range i.users {
-		users = append(users, u)
+		cp := *u
+
+		users = append(users, &cp)
 	}
 
 	return users, nil
@@ -0,0 +1,20 @@
+package users_test
+
+import (
+	"testing"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/repotest"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/users"
+)
+
+func TestInMemory(t *testing.T) {
+	repotest.RunUsers(t, func(_ *testing.T) users.Repository {
+		return users.NewInMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	repotest.RunUsers(t, func(t *testing.T) users.Repository {
+		return users.NewSQLite(repotest.NewSQLiteDB(t))
+	})
+}
@@ -0,0 +1,197 @@
+// Package routelink recognises links to routes of popular route planners, such as Strava or Komoot.
+//
+// Links are recognised by their host and path only, without network calls, so short links,
+// e.g. strava.app.link, which need to be resolved first, are not supported.
+package routelink
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrInvalidLink is returned when the text is not a web link.
+	ErrInvalidLink = errors.New("invalid link")
+	// ErrUnknownProvider is returned when no registered provider recognises the link.
+	ErrUnknownProvider = errors.New("unknown route provider")
+)
+
+// maxLinkLen is a maximum length of the link in bytes.
+const maxLinkLen = 2048
+
+// Link is a recognised route link.
+type Link struct {
+	// Provider is the name of the provider, it's shown to users as the link label.
+	Provider string
+	// ID is the route ID at the provider.
+	ID string
+	// URL is the link with https scheme and without fragment.
+	URL string
+}
+
+// Provider recognises links of a single route planner.
+type Provider interface {
+	// Name is shown to users as the link label, e.g. "Strava".
+	Name() string
+	// RouteID returns the route ID of the link, false if the provider doesn't recognise the link.
+	// The host of the link is lowercase.
+	RouteID(u *url.URL) (string, bool)
+}
+
+// patternProvider recognises links by their host and path.
+type patternProvider struct {
+	name  string
+	hosts []string
+	paths []*regexp.Regexp
+}
+
+// NewProvider returns a provider recognising links to any of the hosts, which path matches any of the patterns.
+// The first submatch of the pattern is the route ID.
+func NewProvider(name string, hosts []string, paths ...*regexp.Regexp) Provider {
+	return &patternProvider{
+		name:  name,
+		hosts: hosts,
+		paths: paths,
+	}
+}
+
+func (p *patternProvider) Name() string {
+	return p.name
+}
+
+func (p *patternProvider) RouteID(u *url.URL) (string, bool) {
+	if !slices.Contains(p.hosts, u.Host) {
+		return "", false
+	}
+
+	for _, re := range p.paths {
+		if m := re.FindStringSubmatch(u.Path); len(m) > 1 && m[1] != "" {
+			return m[1], true
+		}
+	}
+
+	return "", false
+}
+
+// Strava recognises Strava routes and activities, e.g. https://www.strava.com/routes/3344556677.
+func Strava() Provider {
+	return NewProvider("Strava", []string{"strava.com", "www.strava.com"},
+		regexp.MustCompile(`^/routes/(\d+)/?$`),
+		regexp.MustCompile(`^/activities/(\d+)/?$`),
+	)
+}
+
+// Komoot recognises Komoot tours with an optional locale, e.g. https://www.komoot.com/de-de/tour/1234567890.
+func Komoot() Provider {
+	return NewProvider("Komoot", []string{"komoot.com", "www.komoot.com", "komoot.de", "www.komoot.de"},
+		regexp.MustCompile(`^(?:/[a-z]{2}-[a-z]{2})?/tour/(\d+)/?$`),
+	)
+}
+
+// RideWithGPS recognises RideWithGPS routes and trips, e.g. https://ridewithgps.com/routes/45678901.
+func RideWithGPS() Provider {
+	return NewProvider("RideWithGPS", []string{"ridewithgps.com", "www.ridewithgps.com"},
+		regexp.MustCompile(`^/routes/(\d+)/?$`),
+		regexp.MustCompile(`^/trips/(\d+)/?$`),
+	)
+}
+
+// GarminConnect recognises Garmin Connect courses and activities,
+// e.g. https://connect.garmin.com/modern/course/123456789.
+func GarminConnect() Provider {
+	return NewProvider("Garmin Connect", []string{"connect.garmin.com"},
+		regexp.MustCompile(`^/(?:modern|app)/course/(\d+)/?$`),
+		regexp.MustCompile(`^/(?:modern|app)/activity/(\d+)/?$`),
+	)
+}
+
+// Registry recognises links of registered providers. It's not safe to register providers
+// concurrently with parsing, so all providers are expected to be registered at startup.
+type Registry struct {
+	providers []Provider
+}
+
+// NewRegistry returns a registry with the providers.
+func NewRegistry(providers ...Provider) *Registry {
+	return &Registry{
+		providers: slices.Clone(providers),
+	}
+}
+
+// Default returns a registry with built-in providers: Strava, Komoot, RideWithGPS and Garmin Connect.
+func Default() *Registry {
+	return NewRegistry(Strava(), Komoot(), RideWithGPS(), GarminConnect())
+}
+
+// Register adds the providers, they are tried after the already registered ones.
+func (r *Registry) Register(providers ...Provider) {
+	r.providers = append(r.providers, providers...)
+}
+
+// Names returns names of registered providers in the registration order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+
+	for _, p := range r.providers {
+		names = append(names, p.Name())
+	}
+
+	return names
+}
+
+// Parse recognises the route link. Links without a scheme, e.g. "strava.com/routes/1", are accepted as well.
+func (r *Registry) Parse(text string) (*Link, error) {
+	u, err := parseURL(text)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range r.providers {
+		id, ok := p.RouteID(u)
+		if !ok {
+			continue
+		}
+
+		return &Link{
+			Provider: p.Name(),
+			ID:       id,
+			URL:      u.String(),
+		}, nil
+	}
+
+	return nil, ErrUnknownProvider
+}
+
+// parseURL parses the web link and normalizes it: the scheme is https, the host is lowercase,
+// user info and fragment are dropped. Links with ports are not accepted.
+func parseURL(text string) (*url.URL, error) {
+	text = strings.TrimSpace(text)
+
+	if text == "" || len(text) > maxLinkLen || strings.ContainsAny(text, " \t\r\n") {
+		return nil, ErrInvalidLink
+	}
+
+	if !strings.Contains(text, "://") {
+		text = "https://" + text
+	}
+
+	u, err := url.Parse(text)
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Port() != "" {
+		return nil, ErrInvalidLink
+	}
+
+	u.Scheme = "https"
+	u.Host = strings.ToLower(u.Host)
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	return u, nil
+}
@@ -0,0 +1,211 @@
+package routelink_test
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/routelink"
+)
+
+func TestRegistry_Parse(t *testing.T) {
+	type expected struct {
+		link *routelink.Link
+		err  error
+	}
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected expected
+	}{
+		{
+			name: "strava route",
+			text: "https://www.strava.com/routes/3344556677",
+			expected: expected{
+				link: &routelink.Link{
+					Provider: "Strava",
+					ID:       "3344556677",
+					URL:      "https://www.strava.com/routes/3344556677",
+				},
+			},
+		},
+		{
+			name: "strava activity without scheme",
+			text: "  strava.com/activities/12345/ ",
+			expected: expected{
+				link: &routelink.Link{
+					Provider: "Strava",
+					ID:       "12345",
+					URL:      "https://strava.com/activities/12345/",
+				},
+			},
+		},
+		{
+			name: "komoot tour with locale and share token",
+			text: "http://WWW.Komoot.com/de-de/tour/1234567890?share_token=abc&ref=wtd#map",
+			expected: expected{
+				link: &routelink.Link{
+					Provider: "Komoot",
+					ID:       "1234567890",
+					URL:      "https://www.komoot.com/de-de/tour/1234567890?share_token=abc&ref=wtd",
+				},
+			},
+		},
+		{
+			name: "ridewithgps route",
+			text: "https://ridewithgps.com/routes/45678901?privacy_code=xyz",
+			expected: expected{
+				link: &routelink.Link{
+					Provider: "RideWithGPS",
+					ID:       "45678901",
+					URL:      "https://ridewithgps.com/routes/45678901?privacy_code=xyz",
+				},
+			},
+		},
+		{
+			name: "garmin connect course",
+			text: "https://connect.garmin.com/modern/course/123456789",
+			expected: expected{
+				link: &routelink.Link{
+					Provider: "Garmin Connect",
+					ID:       "123456789",
+					URL:      "https://connect.garmin.com/modern/course/123456789",
+				},
+			},
+		},
+		{
+			name: "user info is dropped",
+			text: "https://someone@www.strava.com/routes/1",
+			expected: expected{
+				link: &routelink.Link{
+					Provider: "Strava",
+					ID:       "1",
+					URL:      "https://www.strava.com/routes/1",
+				},
+			},
+		},
+		{
+			name: "not a route",
+			text: "https://www.strava.com/athletes/12345",
+			expected: expected{
+				err: routelink.ErrUnknownProvider,
+			},
+		},
+		{
+			name: "route id is not a number",
+			text: "https://www.strava.com/routes/abc",
+			expected: expected{
+				err: routelink.ErrUnknownProvider,
+			},
+		},
+		{
+			name: "lookalike host",
+			text: "https://strava.com.example.org/routes/12345",
+			expected: expected{
+				err: routelink.ErrUnknownProvider,
+			},
+		},
+		{
+			name: "short link",
+			text: "https://strava.app.link/AbCdEf",
+			expected: expected{
+				err: routelink.ErrUnknownProvider,
+			},
+		},
+		{
+			name: "port",
+			text: "https://www.strava.com:8443/routes/12345",
+			expected: expected{
+				err: routelink.ErrInvalidLink,
+			},
+		},
+		{
+			name: "other scheme",
+			text: "ftp://www.strava.com/routes/12345",
+			expected: expected{
+				err: routelink.ErrInvalidLink,
+			},
+		},
+		{
+			name: "text",
+			text: "see the route on strava",
+			expected: expected{
+				err: routelink.ErrInvalidLink,
+			},
+		},
+		{
+			name: "empty",
+			text: " ",
+			expected: expected{
+				err: routelink.ErrInvalidLink,
+			},
+		},
+	}
+
+	r := routelink.Default()
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Parse(tt.text)
+			if tt.expected.err != nil {
+				require.ErrorIs(t, err, tt.expected.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.link, got)
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := routelink.NewRegistry(routelink.Strava())
+
+	const link = "https://www.bikemap.net/en/r/1234567/"
+
+	_, err := r.Parse(link)
+	require.ErrorIs(t, err, routelink.ErrUnknownProvider)
+
+	r.Register(routelink.NewProvider("Bikemap", []string{"www.bikemap.net"},
+		regexp.MustCompile(`^/[a-z]{2}/r/(\d+)/?$`)))
+
+	assert.Equal(t, []string{"Strava", "Bikemap"}, r.Names())
+
+	got, err := r.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, &routelink.Link{Provider: "Bikemap", ID: "1234567", URL: link}, got)
+}
+
+type customProvider struct{}
+
+func (customProvider) Name() string {
+	return "Custom"
+}
+
+func (customProvider) RouteID(u *url.URL) (string, bool) {
+	if u.Host != "routes.example.org" {
+		return "", false
+	}
+
+	id := u.Query().Get("id")
+
+	return id, id != ""
+}
+
+func TestRegistry_CustomProvider(t *testing.T) {
+	r := routelink.Default()
+	r.Register(customProvider{})
+
+	got, err := r.Parse("routes.example.org/view?id=42")
+	require.NoError(t, err)
+	assert.Equal(t, &routelink.Link{
+		Provider: "Custom",
+		ID:       "42",
+		URL:      "https://routes.example.org/view?id=42",
+	}, got)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/outbox"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
+)
+
+const (
+	callbackAnnouncePrefix = "announce:"
+	callbackAnnounceJoin   = callbackAnnouncePrefix + "join:"
+	callbackAnnounceLeave  = callbackAnnouncePrefix + "leave:"
+)
+
+// announcementText returns a text of the published trip announcement.
+func (s *Service) announcementText(trip *models.Trip) (string, error) {
+	tripfmt, err := s.renderTrip(trip)
+	if err != nil {
+		return "", fmt.Errorf("failed to render trip: %w", err)
+	}
+
+	return fmt.Sprintf("%s\n\n%s", html.EscapeString(announcementHeader(trip)), tripfmt), nil
+}
+
+// announcementHeader returns the beginning of the announcement, which reflects the trip status.
+func announcementHeader(trip *models.Trip) string {
+	switch trip.Status {
+	case models.TripCancelled:
+		if trip.CancelReason == "" {
+			return "❌ CANCELLED"
+		}
+
+		return fmt.Sprintf("❌ CANCELLED\nReason: %s", trip.CancelReason)
+	case models.TripStarted:
+		return "🚴 Trip has started!"
+	case models.TripFinished, models.TripArchived:
+		return "🏁 Trip is finished."
+	default:
+		return "Trip is published!"
+	}
+}
+
+// announcementKeyboard returns an inline keyboard attached to the trip announcement.
+// Only published trips can be joined, so other announcements have no keyboard.
+func announcementKeyboard(trip *models.Trip) *tgbotapi.InlineKeyboardMarkup {
+	if !trip.Status.Joinable() {
+		return nil
+	}
+
+	return tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(fmt.Sprintf("Join (%d)", len(trip.Participants))).
+			WithCallbackData(callbackAnnounceJoin+trip.ID.String()),
+		tu.InlineKeyboardButton("Leave").
+			WithCallbackData(callbackAnnounceLeave+trip.ID.String()),
+	))
+}
+
+func (s *Service) announcementCallbackHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "callback_handler", "announcement"))
+
+		query := update.CallbackQuery
+
+		log.WithField(ctx, "data", query.Data).Debug("Called announcement callback handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		var (
+			join bool
+			ref  string
+		)
+
+		switch data := query.Data; {
+		case strings.HasPrefix(data, callbackAnnounceJoin):
+			join, ref = true, strings.TrimPrefix(data, callbackAnnounceJoin)
+		case strings.HasPrefix(data, callbackAnnounceLeave):
+			join, ref = false, strings.TrimPrefix(data, callbackAnnounceLeave)
+		default:
+			log.Warn(ctx, "Unexpected announcement callback")
+
+			s.answerCallback(ctx, query.ID, "")
+
+			return
+		}
+
+		id, err := uuid.FromString(ref)
+		if err != nil {
+			log.WithError(ctx, err).Warn("Invalid trip ID")
+
+			s.answerCallback(ctx, query.ID, "")
+
+			return
+		}
+
+		trip, answer := s.toggleParticipation(ctx, id, sess.User.ID, join)
+
+		s.answerCallback(ctx, query.ID, answer)
+
+		if trip != nil {
+			s.refreshAnnouncement(ctx, query, trip)
+		}
+	}
+}
+
+// toggleParticipation joins or leaves the trip and returns the updated trip with a text for the user.
+// Trip is nil when the announcement doesn't need to be refreshed.
+func (s *Service) toggleParticipation(ctx context.Context, id uuid.UUID, userID int64, join bool) (*models.Trip, string) {
+	var (
+		trip *models.Trip
+		err  error
+	)
+
+	if join {
+		trip, err = ops.JoinTrip(ctx, s.backends, id, userID)
+	} else {
+		trip, err = ops.LeaveTrip(ctx, s.backends, id, userID)
+	}
+
+	switch {
+	case err == nil && join:
+		return trip, "You joined the trip!"
+	case err == nil:
+		return trip, "You left the trip."
+	case errors.Is(err, ops.ErrAlreadyJoined):
+		return nil, "You have already joined this trip."
+	case errors.Is(err, ops.ErrNotJoined):
+		return nil, "You haven't joined this trip."
+	case errors.Is(err, ops.ErrTripNotPublished):
+		// Announcement is outdated, so it's refreshed with the actual trip status.
+		trip, err = ops.GetTrip(ctx, s.backends, id)
+		if err != nil {
+			log.WithError(ctx, err).WithField("trip_id", id).Error("Failed to get trip")
+
+			return nil, notJoinableText(models.TripDraft)
+		}
+
+		return trip, notJoinableText(trip.Status)
+	case errors.Is(err, trips.ErrNotFound):
+		return nil, "This trip is not available."
+	default:
+		log.WithError(ctx, err).WithFields(log.Fields{
+			"trip_id": id,
+			"join":    join,
+		}).Error("Failed to change trip participation")
+
+		return nil, "Something went wrong, please try again later."
+	}
+}
+
+// notJoinableText explains why a trip in the status can't be joined or left.
+func notJoinableText(status models.TripStatus) string {
+	switch status {
+	case models.TripDraft:
+		return "This trip is not published yet."
+	case models.TripCancelled:
+		return "This trip is cancelled."
+	case models.TripStarted:
+		return "This trip has already started."
+	default:
+		return "This trip is over."
+	}
+}
+
+// refreshAnnouncement edits the announcement message the callback came from with the actual trip state.
+func (s *Service) refreshAnnouncement(ctx context.Context, query *tgbotapi.CallbackQuery, trip *models.Trip) {
+	if query.Message == nil || !query.Message.IsAccessible() {
+		log.Warn(ctx, "Announcement message is not accessible")
+
+		return
+	}
+
+	s.editAnnouncement(ctx, query.Message.GetChat().ID, query.Message.GetMessageID(), trip)
+}
+
+// updateAnnouncement edits the posted trip announcement with the actual trip state.
+func (s *Service) updateAnnouncement(ctx context.Context, trip *models.Trip) {
+	if trip.ChatID == 0 || trip.MessageID == 0 {
+		// Trips published before messages were tracked can't be updated.
+		log.WithField(ctx, "trip_id", trip.ID).Warn("Announcement message is unknown")
+
+		return
+	}
+
+	s.editAnnouncement(ctx, trip.ChatID, trip.MessageID, trip)
+}
+
+func (s *Service) editAnnouncement(ctx context.Context, chatID int64, messageID int, trip *models.Trip) {
+	text, err := s.announcementText(trip)
+	if err != nil {
+		log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to render announcement")
+
+		return
+	}
+
+	_, err = outbox.Do(ctx, s.outbox, chatID, func() (*tgbotapi.Message, error) {
+		return s.bot.Client().EditMessageText(&tgbotapi.EditMessageTextParams{
+			ChatID:      tu.ID(chatID),
+			MessageID:   messageID,
+			Text:        text,
+			ParseMode:   tgbotapi.ModeHTML,
+			ReplyMarkup: announcementKeyboard(trip),
+		})
+	})
+	if err != nil {
+		log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to edit announcement")
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/service/renderer"
+)
+
+func TestService_ToggleParticipation(t *testing.T) {
+	ctx := context.Background()
+
+	const (
+		creatorID int64 = 1
+		riderID   int64 = 2
+	)
+
+	b := opstest.NewBackends(t)
+	s := &Service{backends: b}
+
+	opstest.NewUser(t, b, creatorID)
+	opstest.NewUser(t, b, riderID)
+
+	newTrip := func(statuses ...models.TripStatus) uuid.UUID {
+		return opstest.NewTrip(t, b, creatorID, time.Now().Add(24*time.Hour), statuses...).ID
+	}
+
+	published := newTrip(models.TripPublished)
+
+	testCases := []struct {
+		name        string
+		id          uuid.UUID
+		join        bool
+		wantAnswer  string
+		wantRefresh bool
+		wantJoined  bool
+		wantStatus  models.TripStatus
+	}{
+		{
+			name:        "join",
+			id:          published,
+			join:        true,
+			wantAnswer:  "You joined the trip!",
+			wantRefresh: true,
+			wantJoined:  true,
+			wantStatus:  models.TripPublished,
+		},
+		{
+			name:       "already joined",
+			id:         published,
+			join:       true,
+			wantAnswer: "You have already joined this trip.",
+		},
+		{
+			name:        "leave",
+			id:          published,
+			join:        false,
+			wantAnswer:  "You left the trip.",
+			wantRefresh: true,
+			wantJoined:  false,
+			wantStatus:  models.TripPublished,
+		},
+		{
+			name:       "not joined",
+			id:         published,
+			join:       false,
+			wantAnswer: "You haven't joined this trip.",
+		},
+		{
+			name:        "cancelled trip needs refresh",
+			id:          newTrip(models.TripPublished, models.TripCancelled),
+			join:        true,
+			wantAnswer:  "This trip is cancelled.",
+			wantRefresh: true,
+			wantStatus:  models.TripCancelled,
+		},
+		{
+			name:        "started trip needs refresh",
+			id:          newTrip(models.TripPublished, models.TripStarted),
+			join:        false,
+			wantAnswer:  "This trip has already started.",
+			wantRefresh: true,
+			wantStatus:  models.TripStarted,
+		},
+		{
+			name:       "unknown trip",
+			id:         uuid.Must(uuid.NewV4()),
+			join:       true,
+			wantAnswer: "This trip is not available.",
+		},
+	}
+
+	// Cases run in order, each one continues from the state the previous left.
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			trip, answer := s.toggleParticipation(ctx, tt.id, riderID, tt.join)
+			assert.Equal(t, tt.wantAnswer, answer)
+
+			if !tt.wantRefresh {
+				assert.Nil(t, trip)
+
+				return
+			}
+
+			require.NotNil(t, trip)
+			assert.Equal(t, tt.id, trip.ID)
+			assert.Equal(t, tt.wantStatus, trip.Status)
+			assert.Equal(t, tt.wantJoined, trip.HasParticipant(riderID))
+		})
+	}
+}
+
+func TestService_AnnouncementText(t *testing.T) {
+	tpls, err := renderer.New()
+	require.NoError(t, err)
+
+	s := &Service{templates: tpls}
+
+	text, err := s.announcementText(&models.Trip{
+		ID:           uuid.Must(uuid.NewV4()),
+		Name:         "Tom & Jerry",
+		Status:       models.TripCancelled,
+		CancelReason: "<storm>",
+		CreatedBy:    &models.User{ID: 1, Username: "leader"},
+		RouteLink: &models.RouteLink{
+			Provider: "Strava",
+			ID:       "1",
+			URL:      "https://www.strava.com/routes/1",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, text, "Reason: &lt;storm&gt;\n")
+	assert.Contains(t, text, "Title: Tom &amp; Jerry\n")
+	assert.Contains(t, text, `Route link: <a href="https://www.strava.com/routes/1">Strava</a>`)
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
@@ -13,4 +16,7 @@ type backends interface {
 	SessionsRepository() sessions.Repository
 	TripsRepository() trips.Repository
 	StatesRepository() states.Repository
+	ParticipantsRepository() participants.Repository
+	ChatsRepository() chats.Repository
+	RemindersRepository() reminders.Repository
 }
@@ -4,6 +4,9 @@
 import (
 	"errors"
 
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/participants"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/reminders"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/states"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/trips"
@@ -12,10 +15,13 @@
 
 // Backends is a set of repositories.
 type Backends struct {
-	users    users.Repository
-	states   states.Repository
-	sessions sessions.Repository
-	trips    trips.Repository
+	users        users.Repository
+	states       states.Repository
+	sessions     sessions.Repository
+	trips        trips.Repository
+	participants participants.Repository
+	chats        chats.Repository
+	reminders    reminders.Repository
 }
 
 // UsersRepository returns users repository.
@@ -38,12 +44,30 @@ func (b *Backends) TripsRepository() trips.Repository {
 	return b.trips
 }
 
+// ParticipantsRepository returns participants repository.
+func (b *Backends) ParticipantsRepository() participants.Repository {
+	return b.participants
+}
+
+// ChatsRepository returns chats repository.
+func (b *Backends) ChatsRepository() chats.Repository {
+	return b.chats
+}
+
+// RemindersRepository returns reminders repository.
+func (b *Backends) RemindersRepository() reminders.Repository {
+	return b.reminders
+}
+
 // NewParams is a params for New function.
 type NewParams struct {
-	Users    users.Repository
-	States   states.Repository
-	Sessions sessions.Repository
-	Trips    trips.Repository
+	Users        users.Repository
+	States       states.Repository
+	Sessions     sessions.Repository
+	Trips        trips.Repository
+	Participants participants.Repository
+	Chats        chats.Repository
+	Reminders    reminders.Repository
 }
 
 // New creates a new Backends.
@@ -64,10 +88,25 @@ func New(p NewParams) (*Backends, error) {
 		return nil, errors.New("trips repository is required")
 	}
 
+	if p.Participants == nil {
+		return nil, errors.New("participants repository is required")
+	}
+
+	if p.Chats == nil {
+		return nil, errors.New("chats repository is required")
+	}
+
+	if p.Reminders == nil {
+		return nil, errors.New("reminders repository is required")
+	}
+
 	return &Backends{
-		users:    p.Users,
-		states:   p.States,
-		sessions: p.Sessions,
-		trips:    p.Trips,
+		users:        p.Users,
+		states:       p.States,
+		sessions:     p.Sessions,
+		trips:        p.Trips,
+		participants: p.Participants,
+		chats:        p.Chats,
+		reminders:    p.Reminders,
 	}, nil
 }
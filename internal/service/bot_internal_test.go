@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/outbox"
+	backendspkg "github.com/obalunenko/telegram-ride-announcer-bot/internal/service/backends"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/telegram"
+)
+
+// sentMessage is a message the bot sent or edited.
+type sentMessage struct {
+	Method      string                         `json:"-"`
+	ChatID      int64                          `json:"chat_id"`
+	MessageID   int                            `json:"message_id"`
+	Text        string                         `json:"text"`
+	ReplyMarkup *tgbotapi.InlineKeyboardMarkup `json:"reply_markup"`
+}
+
+// callbackData returns callback data of the inline keyboard buttons row by row.
+func (m sentMessage) callbackData() []string {
+	if m.ReplyMarkup == nil {
+		return nil
+	}
+
+	var data []string
+
+	for _, row := range m.ReplyMarkup.InlineKeyboard {
+		for _, button := range row {
+			data = append(data, button.CallbackData)
+		}
+	}
+
+	return data
+}
+
+// fakeAPI is a Telegram Bot API stub, which records messages the bot sends.
+type fakeAPI struct {
+	mu       sync.Mutex
+	messages []sentMessage
+	answers  []string
+}
+
+// Call implements telegoapi.Caller.
+func (f *fakeAPI) Call(url string, data *ta.RequestData) (*ta.Response, error) {
+	method := url[strings.LastIndex(url, "/")+1:]
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result any = true
+
+	switch method {
+	case "getMe":
+		result = tgbotapi.User{ID: 1, IsBot: true, Username: "ride_announcer_bot"}
+	case "getMyDescription":
+		result = tgbotapi.BotDescription{}
+	case "getMyCommands":
+		result = []tgbotapi.BotCommand{}
+	case "sendMessage", "editMessageText":
+		msg := sentMessage{Method: method}
+		if err := json.Unmarshal(data.Buffer.Bytes(), &msg); err != nil {
+			return nil, err
+		}
+
+		f.messages = append(f.messages, msg)
+
+		result = tgbotapi.Message{
+			MessageID: len(f.messages),
+			Chat:      tgbotapi.Chat{ID: msg.ChatID},
+			Text:      msg.Text,
+		}
+	case "answerCallbackQuery":
+		var params tgbotapi.AnswerCallbackQueryParams
+		if err := json.Unmarshal(data.Buffer.Bytes(), &params); err != nil {
+			return nil, err
+		}
+
+		f.answers = append(f.answers, params.Text)
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ta.Response{Ok: true, Result: raw}, nil
+}
+
+// sent returns messages sent or edited since the last call.
+func (f *fakeAPI) sent() []sentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	messages := f.messages
+	f.messages = nil
+
+	return messages
+}
+
+// answered returns texts of callback query answers since the last call.
+func (f *fakeAPI) answered() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	answers := f.answers
+	f.answers = nil
+
+	return answers
+}
+
+// newTestService creates a service, which talks to the fake Bot API.
+func newTestService(t *testing.T, b *backendspkg.Backends, opts ...Option) (*Service, *fakeAPI) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	api := &fakeAPI{}
+
+	bot, err := telegram.NewBot(ctx, "1:"+strings.Repeat("a", 35), telegram.WithClientOptions(
+		tgbotapi.WithAPICaller(api),
+		tgbotapi.WithDiscardLogger(),
+	))
+	require.NoError(t, err)
+
+	s, err := New(bot, b, opts...)
+	require.NoError(t, err)
+
+	// Tests send many messages to the same chat, rate limits would only slow them down.
+	s.outbox, err = outbox.New(outbox.Params{
+		Global:   outbox.Limit{Count: 1000, Period: time.Second},
+		Group:    outbox.Limit{Count: 1000, Period: time.Second},
+		Private:  outbox.Limit{Count: 1000, Period: time.Second},
+		Backoff:  sendBackoff,
+		MaxDelay: sendMaxDelay,
+	})
+	require.NoError(t, err)
+
+	return s, api
+}
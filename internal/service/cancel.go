@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+func (s *Service) cancelTripHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdCancelTrip))
+
+		log.Debug(ctx, "Called cancel_trip handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		s.runConversation(ctx, sess, func(ctx context.Context) error {
+			// Trip cancellation is in progress, ask the current step again.
+			if c, ok := s.conversations.Active(sess); ok && c.Command == CmdCancelTrip {
+				return s.conversations.Ask(ctx, sess)
+			}
+
+			list, err := s.cancellableTrips(ctx, sess.User)
+			if err != nil {
+				return err
+			}
+
+			if len(list) == 0 {
+				s.sendMessage(ctx, "You have no trips to cancel.")
+
+				return nil
+			}
+
+			return s.conversations.Start(ctx, sess, CmdCancelTrip)
+		})
+	}
+}
+
+// cancelTripConversation describes cancellation of a trip: the creator picks a trip, confirms
+// the cancellation and enters the reason, which is shown to participants.
+func (s *Service) cancelTripConversation() *conversation.Conversation {
+	return &conversation.Conversation{
+		Command: CmdCancelTrip,
+		Begin:   s.forgetTrip,
+		Cancel:  s.forgetTrip,
+		Steps: []conversation.Step{
+			{
+				State:    models.StateCancelTripSelect,
+				Prompt:   s.cancelTripSelectPrompt,
+				Validate: s.validateCancelTripSelect,
+				Store:    s.storeCancelTripSelect,
+				Next:     models.StateCancelTripConfirm,
+			},
+			{
+				State:    models.StateCancelTripConfirm,
+				Prompt:   s.cancelTripConfirmPrompt,
+				Validate: s.validateTripConfirm,
+				Store:    s.storeCancelTripConfirm,
+				Next:     models.StateCancelTripReason,
+				Back:     models.StateCancelTripSelect,
+			},
+			{
+				State:    models.StateCancelTripReason,
+				Prompt:   staticPrompt("Please enter the cancellation reason, participants will see it", nil),
+				Validate: s.validateCancelReason,
+				Store:    s.storeCancelReason,
+				Back:     models.StateCancelTripConfirm,
+			},
+		},
+	}
+}
+
+// cancellableTrips returns trips created by the user, which may be cancelled.
+func (s *Service) cancellableTrips(ctx context.Context, user *models.User) ([]*models.Trip, error) {
+	return s.userTrips(ctx, user, func(status models.TripStatus) bool {
+		return status.CanTransitionTo(models.TripCancelled)
+	})
+}
+
+func (s *Service) cancelTripSelectPrompt(ctx context.Context, sess *models.Session) (*conversation.Prompt, error) {
+	list, err := s.cancellableTrips(ctx, sess.User)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation.Prompt{
+		Text:     "Which trip do you want to cancel?",
+		Keyboard: tripSelectKeyboard(list),
+	}, nil
+}
+
+// findCancellableTrip finds the user's trip to cancel by the chosen button or the typed trip ID.
+func (s *Service) findCancellableTrip(ctx context.Context, sess *models.Session, text string) (*models.Trip, error) {
+	list, err := s.cancellableTrips(ctx, sess.User)
+	if err != nil {
+		return nil, err
+	}
+
+	trip, ok := matchTripRef(list, text)
+	if !ok {
+		return nil, conversation.Invalid("Please choose one of your upcoming trips")
+	}
+
+	return trip, nil
+}
+
+func (s *Service) validateCancelTripSelect(ctx context.Context, sess *models.Session, text string) error {
+	_, err := s.findCancellableTrip(ctx, sess, text)
+
+	return err
+}
+
+func (s *Service) storeCancelTripSelect(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	trip, err := s.findCancellableTrip(ctx, sess, text)
+	if err != nil {
+		return 0, err
+	}
+
+	sess.UserState.Trip = trip
+
+	return 0, nil
+}
+
+func (s *Service) cancelTripConfirmPrompt(_ context.Context, sess *models.Session) (*conversation.Prompt, error) {
+	trip := sess.UserState.Trip
+	if trip == nil {
+		return nil, errors.New("no trip is being cancelled")
+	}
+
+	return &conversation.Prompt{
+		Text: fmt.Sprintf("Do you really want to cancel %q on %s? Participants joined: %d.",
+			trip.Name, formatTripDate(trip.StartsAt), len(trip.Participants)),
+		Keyboard: confirmKeyboard(),
+	}, nil
+}
+
+func (s *Service) storeCancelTripConfirm(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	confirmed, err := parseConfirm(text)
+	if err != nil {
+		return 0, err
+	}
+
+	if !confirmed {
+		sess.UserState.Trip = nil
+
+		return models.StateStart, s.sendWithKeyboard(ctx, "The trip is not cancelled.", tu.ReplyKeyboardRemove())
+	}
+
+	return 0, nil
+}
+
+// storeCancelReason cancels the trip: the announcement gets a cancellation banner and is unpinned,
+// participants are notified in private chats. The conversation is finished afterwards.
+func (s *Service) storeCancelReason(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	if sess.UserState.Trip == nil {
+		return 0, errors.New("no trip is being cancelled")
+	}
+
+	selected := sess.UserState.Trip
+
+	trip, err := ops.CancelTrip(ctx, s.backends, selected.ID, strings.TrimSpace(text))
+	if err != nil {
+		if errors.Is(err, ops.ErrInvalidTransition) {
+			sess.UserState.Trip = nil
+
+			// Trip has finished or was cancelled while the creator was typing.
+			return models.StateStart, s.sendWithKeyboard(ctx,
+				fmt.Sprintf("Trip %q can't be cancelled anymore.", selected.Name), tu.ReplyKeyboardRemove())
+		}
+
+		// The trip is kept in the session, so the reason can be sent again.
+		return 0, fmt.Errorf("failed to cancel trip: %w", err)
+	}
+
+	sess.UserState.Trip = nil
+
+	s.updateAnnouncement(ctx, trip)
+
+	if err = s.unpinAnnouncement(ctx, trip); err != nil {
+		log.WithError(ctx, err).WithField("trip_id", trip.ID).Warn("Failed to unpin announcement")
+	}
+
+	notified := s.notifyParticipants(ctx, trip, sess.User.ID, cancelNoticeText(trip))
+
+	log.WithFields(ctx, log.Fields{
+		"trip_id":  trip.ID,
+		"notified": notified,
+	}).Info("Trip cancelled")
+
+	return models.StateStart, s.sendWithKeyboard(ctx,
+		fmt.Sprintf("Trip %q is cancelled. Participants notified: %d.", trip.Name, notified),
+		tu.ReplyKeyboardRemove())
+}
+
+// cancelNoticeText returns a message telling participants that the trip is cancelled.
+func cancelNoticeText(trip *models.Trip) string {
+	text := fmt.Sprintf("❌ Trip %q on %s is cancelled.", trip.Name, formatTripDate(trip.StartsAt))
+
+	if trip.CancelReason != "" {
+		text += fmt.Sprintf("\nReason: %s", trip.CancelReason)
+	}
+
+	return text
+}
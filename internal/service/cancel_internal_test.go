@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestCancelNoticeText(t *testing.T) {
+	trip := &models.Trip{
+		Name:     "Morning ride",
+		StartsAt: time.Date(2099, time.October, 20, 9, 30, 0, 0, time.UTC),
+		Status:   models.TripCancelled,
+	}
+
+	assert.Equal(t, `❌ Trip "Morning ride" on Tue, 20 Oct 2099 09:30 UTC is cancelled.`, cancelNoticeText(trip))
+
+	trip.CancelReason = "Storm warning"
+
+	assert.Equal(t, "❌ Trip \"Morning ride\" on Tue, 20 Oct 2099 09:30 UTC is cancelled.\nReason: Storm warning",
+		cancelNoticeText(trip))
+}
+
+func TestAnnouncementHeader(t *testing.T) {
+	testCases := []struct {
+		name string
+		trip *models.Trip
+		want string
+	}{
+		{
+			name: "published",
+			trip: &models.Trip{Status: models.TripPublished},
+			want: "Trip is published!",
+		},
+		{
+			name: "cancelled",
+			trip: &models.Trip{Status: models.TripCancelled},
+			want: "❌ CANCELLED",
+		},
+		{
+			name: "cancelled with reason",
+			trip: &models.Trip{Status: models.TripCancelled, CancelReason: "Storm warning"},
+			want: "❌ CANCELLED\nReason: Storm warning",
+		},
+		{
+			name: "finished",
+			trip: &models.Trip{Status: models.TripFinished},
+			want: "🏁 Trip is finished.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, announcementHeader(tt.trip))
+		})
+	}
+}
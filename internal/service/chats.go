@@ -0,0 +1,52 @@
+package service
+
+import (
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+// myChatMemberHandler keeps the chat registry in sync with groups the bot is added to or removed from.
+func (s *Service) myChatMemberHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		upd := update.MyChatMember
+
+		ctx = log.ContextWithLogger(ctx, log.WithFields(ctx, log.Fields{
+			"update_handler": "my_chat_member",
+			"target_chat_id": upd.Chat.ID,
+		}))
+
+		if upd.Chat.Type != tgbotapi.ChatTypeGroup && upd.Chat.Type != tgbotapi.ChatTypeSupergroup {
+			log.WithField(ctx, "chat_type", upd.Chat.Type).Debug("Not a group chat, skipping")
+
+			return
+		}
+
+		// Bot rights in the chat could change.
+		s.perms.Invalidate(upd.Chat.ID)
+
+		active := upd.NewChatMember.MemberIsMember()
+
+		_, err := ops.SaveChat(ctx, s.backends, ops.SaveChatParams{
+			ChatID:  upd.Chat.ID,
+			Title:   upd.Chat.Title,
+			Type:    upd.Chat.Type,
+			Active:  active,
+			AddedBy: upd.From.ID,
+		})
+		if err != nil {
+			log.WithError(ctx, err).Error("Failed to save chat")
+
+			return
+		}
+
+		log.WithFields(ctx, log.Fields{
+			"status": upd.NewChatMember.MemberStatus(),
+			"active": active,
+		}).Info("Bot membership in chat changed")
+	}
+}
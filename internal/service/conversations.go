@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+// newConversations registers all multi-step conversations of the bot.
+func (s *Service) newConversations() (*conversation.Engine, error) {
+	return conversation.NewEngine(s.sendPrompt,
+		s.newTripConversation(),
+		s.editTripConversation(),
+		s.cancelTripConversation(),
+	)
+}
+
+func (s *Service) cancelHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdCancel))
+
+		log.Debug(ctx, "Called cancel handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		if _, ok := s.conversations.Active(sess); !ok {
+			s.sendMessage(ctx, "There is nothing to cancel.")
+
+			return
+		}
+
+		s.runConversation(ctx, sess, func(ctx context.Context) error {
+			if err := s.conversations.Cancel(ctx, sess); err != nil {
+				return err
+			}
+
+			return s.sendWithKeyboard(ctx, "Canceled. What else can I do for you?", tu.ReplyKeyboardRemove())
+		})
+	}
+}
+
+// runConversation runs the conversation action and saves the session afterwards.
+func (s *Service) runConversation(ctx context.Context, sess *models.Session, fn func(ctx context.Context) error) {
+	log.WithFields(ctx, log.Fields{
+		"command":   sess.UserState.Command,
+		"UserState": sess.UserState.State.String(),
+	}).Debug("Current Session UserState")
+
+	if err := fn(ctx); err != nil {
+		log.WithError(ctx, err).WithField("command", sess.UserState.Command).Error("Conversation failed")
+	}
+
+	if err := s.saveSession(ctx, sess); err != nil {
+		log.WithError(ctx, err).Error("Failed to update session")
+	}
+}
+
+func (s *Service) sendPrompt(ctx context.Context, sess *models.Session, p conversation.Prompt) error {
+	msg := tu.Message(tu.ID(sess.ChatID), p.Text)
+
+	if p.Keyboard != nil {
+		msg.WithReplyMarkup(p.Keyboard)
+	}
+
+	if p.HTML {
+		msg.WithParseMode(tgbotapi.ModeHTML)
+	}
+
+	if _, err := s.send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/timeparse"
+)
+
+const (
+	tripDatePrompt = "Please select the trip date or type it, e.g. saturday, 20.10.2026 or 2026-10-20 09:30"
+	tripTimePrompt = "Please select the trip start time or type it, e.g. 9:30"
+)
+
+var (
+	errDateInPast = errors.New("date is in the past")
+	errTimeInPast = errors.New("time is in the past")
+)
+
+func tripDateKeyboard() *tgbotapi.ReplyKeyboardMarkup {
+	return tu.Keyboard(
+		tu.KeyboardRow(
+			tu.KeyboardButton("today"),
+			tu.KeyboardButton("tomorrow"),
+		),
+		tu.KeyboardRow(
+			tu.KeyboardButton("saturday"),
+			tu.KeyboardButton("sunday"),
+		),
+	).WithResizeKeyboard().WithInputFieldPlaceholder("Enter date").WithOneTimeKeyboard()
+}
+
+func tripTimeKeyboard() *tgbotapi.ReplyKeyboardMarkup {
+	return tu.Keyboard(
+		tu.KeyboardRow(
+			tu.KeyboardButton("07:00"),
+			tu.KeyboardButton("08:00"),
+			tu.KeyboardButton("09:00"),
+		),
+		tu.KeyboardRow(
+			tu.KeyboardButton("10:00"),
+			tu.KeyboardButton("11:00"),
+			tu.KeyboardButton("18:00"),
+		),
+	).WithResizeKeyboard().WithInputFieldPlaceholder("Enter time").WithOneTimeKeyboard()
+}
+
+// validateTripDate checks the trip date entered on StateNewTripDate step.
+func (s *Service) validateTripDate(_ context.Context, _ *models.Session, text string) error {
+	now := s.now()
+
+	startsAt, hasTime, err := timeparse.ParseDateTime(text, now)
+	if err == nil {
+		err = validateTripStart(startsAt, hasTime, now)
+	}
+
+	if err != nil {
+		return conversation.Invalid(dateErrorText(err))
+	}
+
+	return nil
+}
+
+// storeTripDate stores the trip date. When the input contains time as well, time step is skipped.
+func (s *Service) storeTripDate(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	startsAt, hasTime, err := timeparse.ParseDateTime(text, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	if err = s.updateDraftTrip(ctx, sess, ops.UpdateTripParams{
+		StartsAt: &startsAt,
+	}); err != nil {
+		return 0, err
+	}
+
+	if hasTime {
+		return models.StateNewTripDescription, nil
+	}
+
+	return models.StateNewTripTime, nil
+}
+
+// validateTripTime checks the trip start time entered on StateNewTripTime step.
+func (s *Service) validateTripTime(_ context.Context, sess *models.Session, text string) error {
+	if _, err := s.tripStartTime(sess, text); err != nil {
+		return conversation.Invalid(dateErrorText(err))
+	}
+
+	return nil
+}
+
+// storeTripTime stores the trip start time, combined with the date entered on the previous step.
+func (s *Service) storeTripTime(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	startsAt, err := s.tripStartTime(sess, text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse time: %w", err)
+	}
+
+	return 0, s.updateDraftTrip(ctx, sess, ops.UpdateTripParams{
+		StartsAt: &startsAt,
+	})
+}
+
+func (s *Service) tripStartTime(sess *models.Session, text string) (time.Time, error) {
+	hour, minute, err := timeparse.ParseTime(text)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	startsAt := timeparse.Combine(sess.UserState.Trip.StartsAt.In(s.location), hour, minute)
+
+	if err = validateTripStart(startsAt, true, s.now()); err != nil {
+		return time.Time{}, err
+	}
+
+	return startsAt, nil
+}
+
+// validateTripStart checks that trip starts in the future.
+// When time is not known yet, only the date is checked.
+func validateTripStart(startsAt time.Time, hasTime bool, now time.Time) error {
+	if !hasTime {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		if startsAt.Before(today) {
+			return errDateInPast
+		}
+
+		return nil
+	}
+
+	if !startsAt.After(now) {
+		return errTimeInPast
+	}
+
+	return nil
+}
+
+func dateErrorText(err error) string {
+	switch {
+	case errors.Is(err, timeparse.ErrInvalidDate):
+		return "I can't recognize this date"
+	case errors.Is(err, timeparse.ErrInvalidTime):
+		return "I can't recognize this time"
+	case errors.Is(err, errDateInPast):
+		return "This date is in the past"
+	case errors.Is(err, errTimeInPast):
+		return "This time is in the past"
+	default:
+		return "Invalid value"
+	}
+}
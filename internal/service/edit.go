@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/timeparse"
+)
+
+const (
+	editFieldName         = "Name"
+	editFieldDate         = "Date"
+	editFieldTime         = "Time"
+	editFieldDescription  = "Description"
+	editFieldMeetingPoint = "Meeting point"
+	editFieldRoute        = "Route"
+)
+
+// editFieldStates are steps asking for a new value of each trip field.
+var editFieldStates = map[string]models.State{
+	editFieldName:         models.StateEditTripName,
+	editFieldDate:         models.StateEditTripDate,
+	editFieldTime:         models.StateEditTripTime,
+	editFieldDescription:  models.StateEditTripDescription,
+	editFieldMeetingPoint: models.StateEditTripMeetingPoint,
+	editFieldRoute:        models.StateEditTripTrack,
+}
+
+func (s *Service) editTripHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdEditTrip))
+
+		log.Debug(ctx, "Called edit_trip handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		s.runConversation(ctx, sess, func(ctx context.Context) error {
+			// Trip editing is in progress, ask the current step again.
+			if c, ok := s.conversations.Active(sess); ok && c.Command == CmdEditTrip {
+				return s.conversations.Ask(ctx, sess)
+			}
+
+			list, err := s.editableTrips(ctx, sess.User)
+			if err != nil {
+				return err
+			}
+
+			if len(list) == 0 {
+				s.sendMessage(ctx, fmt.Sprintf("You have no published trips to edit. Use /%s command to create a new trip.",
+					CmdNewTrip))
+
+				return nil
+			}
+
+			return s.conversations.Start(ctx, sess, CmdEditTrip)
+		})
+	}
+}
+
+// editTripConversation describes editing of a published trip: the creator picks a trip and a field,
+// enters a new value, and the announcement is updated in place.
+func (s *Service) editTripConversation() *conversation.Conversation {
+	return &conversation.Conversation{
+		Command: CmdEditTrip,
+		Begin:   s.forgetTrip,
+		Cancel:  s.forgetTrip,
+		Steps: []conversation.Step{
+			{
+				State:    models.StateEditTripSelect,
+				Prompt:   s.editTripSelectPrompt,
+				Validate: s.validateEditTripSelect,
+				Store:    s.storeEditTripSelect,
+				Next:     models.StateEditTripField,
+			},
+			{
+				State:    models.StateEditTripField,
+				Prompt:   s.editTripFieldPrompt,
+				Validate: s.validateEditTripField,
+				Store:    s.storeEditTripField,
+				Back:     models.StateEditTripSelect,
+			},
+			{
+				State:    models.StateEditTripName,
+				Prompt:   staticPrompt("Please enter a new trip name", nil),
+				Validate: s.validateTripName,
+				Store:    s.storeEditTripName,
+				Back:     models.StateEditTripField,
+			},
+			{
+				State:    models.StateEditTripDate,
+				Prompt:   staticPrompt(tripDatePrompt, tripDateKeyboard()),
+				Validate: s.validateEditTripDate,
+				Store:    s.storeEditTripDate,
+				Back:     models.StateEditTripField,
+			},
+			{
+				State:    models.StateEditTripTime,
+				Prompt:   staticPrompt(tripTimePrompt, tripTimeKeyboard()),
+				Validate: s.validateTripTime,
+				Store:    s.storeEditTripTime,
+				Back:     models.StateEditTripField,
+			},
+			{
+				State:    models.StateEditTripDescription,
+				Prompt:   staticPrompt("Please enter a new trip description", nil),
+				Validate: s.validateTripDescription,
+				Store:    s.storeEditTripDescription,
+				Back:     models.StateEditTripField,
+			},
+			{
+				State:    models.StateEditTripMeetingPoint,
+				Prompt:   staticPrompt(meetingPointPrompt, meetingPointKeyboard()),
+				Validate: s.validateMeetingPoint,
+				Store:    s.storeEditTripMeetingPoint,
+				Back:     models.StateEditTripField,
+			},
+			{
+				// Route link is typed, GPX file is uploaded as a document, see documentHandler.
+				State:    models.StateEditTripTrack,
+				Prompt:   staticPrompt(s.routePrompt(), nil),
+				Validate: s.validateRouteLink,
+				Store:    s.storeEditTripRouteLink,
+				Back:     models.StateEditTripField,
+			},
+		},
+	}
+}
+
+// editableTrips returns trips created by the user, which may be edited.
+func (s *Service) editableTrips(ctx context.Context, user *models.User) ([]*models.Trip, error) {
+	return s.userTrips(ctx, user, models.TripStatus.Editable)
+}
+
+func (s *Service) editTripSelectPrompt(ctx context.Context, sess *models.Session) (*conversation.Prompt, error) {
+	list, err := s.editableTrips(ctx, sess.User)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation.Prompt{
+		Text:     "Which trip do you want to edit?",
+		Keyboard: tripSelectKeyboard(list),
+	}, nil
+}
+
+// findEditableTrip finds the user's editable trip by the chosen button or the typed trip ID.
+func (s *Service) findEditableTrip(ctx context.Context, sess *models.Session, text string) (*models.Trip, error) {
+	list, err := s.editableTrips(ctx, sess.User)
+	if err != nil {
+		return nil, err
+	}
+
+	trip, ok := matchTripRef(list, text)
+	if !ok {
+		return nil, conversation.Invalid("Please choose one of your published trips")
+	}
+
+	return trip, nil
+}
+
+func (s *Service) validateEditTripSelect(ctx context.Context, sess *models.Session, text string) error {
+	_, err := s.findEditableTrip(ctx, sess, text)
+
+	return err
+}
+
+func (s *Service) storeEditTripSelect(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	trip, err := s.findEditableTrip(ctx, sess, text)
+	if err != nil {
+		return 0, err
+	}
+
+	sess.UserState.Trip = trip
+
+	return 0, nil
+}
+
+func (s *Service) editTripFieldPrompt(_ context.Context, sess *models.Session) (*conversation.Prompt, error) {
+	if sess.UserState.Trip == nil {
+		return nil, errors.New("no trip is being edited")
+	}
+
+	keyboard := tu.Keyboard(
+		tu.KeyboardRow(
+			tu.KeyboardButton(editFieldName),
+			tu.KeyboardButton(editFieldDescription),
+		),
+		tu.KeyboardRow(
+			tu.KeyboardButton(editFieldDate),
+			tu.KeyboardButton(editFieldTime),
+		),
+		tu.KeyboardRow(
+			tu.KeyboardButton(editFieldMeetingPoint),
+			tu.KeyboardButton(editFieldRoute),
+		),
+	).WithResizeKeyboard().WithInputFieldPlaceholder("Field").WithOneTimeKeyboard()
+
+	return &conversation.Prompt{
+		Text:     fmt.Sprintf("What do you want to change in %q?", sess.UserState.Trip.Name),
+		Keyboard: keyboard,
+	}, nil
+}
+
+func (s *Service) validateEditTripField(_ context.Context, _ *models.Session, text string) error {
+	if _, ok := editFieldStates[strings.TrimSpace(text)]; !ok {
+		return conversation.Invalid("Please choose a field to change")
+	}
+
+	return nil
+}
+
+func (s *Service) storeEditTripField(_ context.Context, _ *models.Session, text string) (models.State, error) {
+	return editFieldStates[strings.TrimSpace(text)], nil
+}
+
+func (s *Service) storeEditTripName(ctx context.Context, sess *models.Session, name string) (models.State, error) {
+	name = strings.TrimSpace(name)
+
+	return s.applyTripEdit(ctx, sess, ops.UpdateTripParams{
+		Name: &name,
+	})
+}
+
+func (s *Service) storeEditTripDescription(ctx context.Context, sess *models.Session, description string) (models.State, error) {
+	description = strings.TrimSpace(description)
+
+	return s.applyTripEdit(ctx, sess, ops.UpdateTripParams{
+		Description: &description,
+	})
+}
+
+func (s *Service) storeEditTripMeetingPoint(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	mp, err := parseMeetingPoint(text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse meeting point: %w", err)
+	}
+
+	return s.applyTripEdit(ctx, sess, ops.UpdateTripParams{
+		MeetingPoint: mp,
+	})
+}
+
+// validateEditTripDate checks the new trip date entered on StateEditTripDate step.
+func (s *Service) validateEditTripDate(_ context.Context, sess *models.Session, text string) error {
+	if _, err := s.editedTripStart(sess, text); err != nil {
+		return conversation.Invalid(dateErrorText(err))
+	}
+
+	return nil
+}
+
+func (s *Service) storeEditTripDate(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	startsAt, err := s.editedTripStart(sess, text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	return s.applyTripEdit(ctx, sess, ops.UpdateTripParams{
+		StartsAt: &startsAt,
+	})
+}
+
+func (s *Service) storeEditTripTime(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	startsAt, err := s.tripStartTime(sess, text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse time: %w", err)
+	}
+
+	return s.applyTripEdit(ctx, sess, ops.UpdateTripParams{
+		StartsAt: &startsAt,
+	})
+}
+
+// editedTripStart returns the trip start moved to the entered date.
+// The start time is kept unless the input contains time as well.
+func (s *Service) editedTripStart(sess *models.Session, text string) (time.Time, error) {
+	now := s.now()
+
+	startsAt, hasTime, err := timeparse.ParseDateTime(text, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !hasTime {
+		current := sess.UserState.Trip.StartsAt.In(s.location)
+
+		startsAt = timeparse.Combine(startsAt, current.Hour(), current.Minute())
+	}
+
+	if err = validateTripStart(startsAt, true, now); err != nil {
+		return time.Time{}, err
+	}
+
+	return startsAt, nil
+}
+
+// applyTripEdit updates the trip being edited, refreshes its announcement and tells participants
+// about material changes. The conversation is finished afterwards.
+func (s *Service) applyTripEdit(ctx context.Context, sess *models.Session, params ops.UpdateTripParams) (models.State, error) {
+	if sess.UserState.Trip == nil {
+		return 0, errors.New("no trip is being edited")
+	}
+
+	// Trip could have started or been cancelled while the creator was typing.
+	before, err := ops.GetTrip(ctx, s.backends, sess.UserState.Trip.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get trip: %w", err)
+	}
+
+	if !before.Status.Editable() {
+		sess.UserState.Trip = nil
+
+		return models.StateStart, s.sendWithKeyboard(ctx,
+			fmt.Sprintf("Trip %q can't be edited anymore, it's %s.", before.Name,
+				strings.ToLower(tripStatusText(before.Status))),
+			tu.ReplyKeyboardRemove())
+	}
+
+	trip, err := ops.UpdateTrip(ctx, s.backends, before.ID, params)
+	if err != nil {
+		// The trip is kept in the session, so the step can be answered again.
+		return 0, fmt.Errorf("failed to update trip: %w", err)
+	}
+
+	sess.UserState.Trip = nil
+
+	if !trip.StartsAt.Equal(before.StartsAt) {
+		if err = ops.ResetReminders(ctx, s.backends, trip.ID); err != nil {
+			log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to reset reminders")
+		}
+	}
+
+	s.updateAnnouncement(ctx, trip)
+
+	if !sameMeetingPoint(before.MeetingPoint, trip.MeetingPoint) {
+		s.postMeetingPoint(ctx, trip)
+	}
+
+	if changes := tripChanges(before, trip); len(changes) > 0 {
+		s.notifyParticipants(ctx, trip, sess.User.ID,
+			fmt.Sprintf("📝 Trip %q you joined has changed:\n%s", trip.Name, strings.Join(changes, "\n")))
+	}
+
+	tripfmt, err := s.renderTrip(trip)
+	if err != nil {
+		return 0, fmt.Errorf("failed to render trip: %w", err)
+	}
+
+	return models.StateStart, s.sendHTMLWithKeyboard(ctx, fmt.Sprintf("Trip is updated!\n\n%s", tripfmt),
+		tu.ReplyKeyboardRemove())
+}
+
+// tripChanges describes changes of the trip participants should know about,
+// such as a new start time, meeting point or route.
+func tripChanges(before, after *models.Trip) []string {
+	var changes []string
+
+	if !after.StartsAt.Equal(before.StartsAt) {
+		changes = append(changes, fmt.Sprintf("Date: %s → %s",
+			formatTripDate(before.StartsAt), formatTripDate(after.StartsAt)))
+	}
+
+	if !sameMeetingPoint(before.MeetingPoint, after.MeetingPoint) {
+		changes = append(changes, fmt.Sprintf("Meeting point: %s → %s",
+			formatMeetingPoint(before.MeetingPoint), formatMeetingPoint(after.MeetingPoint)))
+	}
+
+	if !sameTrack(before.Track, after.Track) {
+		changes = append(changes, fmt.Sprintf("Track: %s → %s", formatTrack(before.Track), formatTrack(after.Track)))
+	}
+
+	if !sameRouteLink(before.RouteLink, after.RouteLink) {
+		changes = append(changes, fmt.Sprintf("Route link: %s → %s",
+			formatRouteLink(before.RouteLink), formatRouteLink(after.RouteLink)))
+	}
+
+	return changes
+}
+
+func sameMeetingPoint(a, b *models.MeetingPoint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// notifyParticipants sends the message to private chats of the trip participants except the given user.
+// Number of notified participants is returned.
+func (s *Service) notifyParticipants(ctx context.Context, trip *models.Trip, exceptID int64, text string) int {
+	var n int
+
+	for _, p := range trip.Participants {
+		if p.User == nil || p.User.ID == exceptID {
+			continue
+		}
+
+		// Private chat ID is the same as the user ID.
+		s.sendMessageTo(ctx, p.User.ID, text)
+
+		n++
+	}
+
+	return n
+}
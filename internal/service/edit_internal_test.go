@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestService_EditedTripStart(t *testing.T) {
+	s := &Service{location: time.UTC}
+
+	sess := &models.Session{
+		UserState: models.UserState{
+			Trip: &models.Trip{StartsAt: time.Date(2099, time.October, 20, 9, 30, 0, 0, time.UTC)},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		text    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "date keeps time",
+			text: "21.10.2099",
+			want: time.Date(2099, time.October, 21, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "date with time",
+			text: "2099-10-22 18:00",
+			want: time.Date(2099, time.October, 22, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "past date",
+			text:    "01.01.2000",
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			text:    "someday",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.editedTripStart(sess, tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestTripChanges(t *testing.T) {
+	before := &models.Trip{
+		Name:     "Morning ride",
+		StartsAt: time.Date(2099, time.October, 20, 9, 30, 0, 0, time.UTC),
+	}
+
+	renamed := *before
+	renamed.Name = "Evening ride"
+
+	assert.Empty(t, tripChanges(before, &renamed), "name is not a material change")
+
+	moved := *before
+	moved.StartsAt = before.StartsAt.Add(time.Hour)
+
+	assert.Equal(t, []string{"Date: Tue, 20 Oct 2099 09:30 UTC → Tue, 20 Oct 2099 10:30 UTC"}, tripChanges(before, &moved))
+
+	relocated := *before
+	relocated.MeetingPoint = &models.MeetingPoint{Label: "Central square"}
+
+	assert.Equal(t, []string{"Meeting point: not set → Central square"}, tripChanges(before, &relocated))
+
+	same := relocated
+	same.MeetingPoint = &models.MeetingPoint{Label: "Central square"}
+
+	assert.Empty(t, tripChanges(&relocated, &same))
+
+	rerouted := *before
+	rerouted.Track = &models.Track{FileID: "file-id", Distance: 42195}
+
+	assert.Equal(t, []string{"Track: not set → 42.2 km"}, tripChanges(before, &rerouted))
+
+	linked := *before
+	linked.RouteLink = &models.RouteLink{Provider: "Komoot", ID: "1", URL: "https://www.komoot.com/tour/1"}
+
+	assert.Equal(t, []string{"Route link: not set → Komoot – https://www.komoot.com/tour/1"},
+		tripChanges(before, &linked))
+}
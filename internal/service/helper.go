@@ -2,8 +2,12 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	tgbotapi "github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 	log "github.com/obalunenko/logger"
 
@@ -19,20 +23,81 @@ func (s *Service) sendMessage(ctx context.Context, text string) {
 		return
 	}
 
-	msg := tu.Message(tu.ID(sess.ChatID), text)
+	s.sendMessageTo(ctx, sess.ChatID, text)
+}
+
+// sendMessageTo sends a message to the chat, which may differ from the session chat.
+func (s *Service) sendMessageTo(ctx context.Context, chatID int64, text string) {
+	msg := tu.Message(tu.ID(chatID), text)
 
-	_, err := s.bot.Client().SendMessage(msg)
+	_, err := s.send(ctx, msg)
 	if err != nil {
 		log.WithError(ctx, err).Error("Failed to send message")
 	}
 }
 
+func (s *Service) sendWithKeyboard(ctx context.Context, text string, keyboard tgbotapi.ReplyMarkup) error {
+	sess := sessionFromContext(ctx)
+	if sess == nil {
+		return errors.New("session is nil")
+	}
+
+	msg := tu.Message(tu.ID(sess.ChatID), text).WithReplyMarkup(keyboard)
+
+	if _, err := s.send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// sendHTML sends a message formatted with Telegram HTML, such as a trip card, to the session chat.
+// User provided parts of the text must be escaped.
+func (s *Service) sendHTML(ctx context.Context, text string) {
+	if err := s.sendHTMLWithKeyboard(ctx, text, nil); err != nil {
+		log.WithError(ctx, err).Error("Failed to send message")
+	}
+}
+
+// sendHTMLWithKeyboard is like sendWithKeyboard, but the text is formatted with Telegram HTML.
+func (s *Service) sendHTMLWithKeyboard(ctx context.Context, text string, keyboard tgbotapi.ReplyMarkup) error {
+	sess := sessionFromContext(ctx)
+	if sess == nil {
+		return errors.New("session is nil")
+	}
+
+	msg := tu.Message(tu.ID(sess.ChatID), text).WithParseMode(tgbotapi.ModeHTML)
+
+	if keyboard != nil {
+		msg.WithReplyMarkup(keyboard)
+	}
+
+	if _, err := s.send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// renderTrip renders the trip card, it's formatted with Telegram HTML.
 func (s *Service) renderTrip(trip *models.Trip) (string, error) {
+	participants := make([]string, 0, len(trip.Participants))
+
+	for _, p := range trip.Participants {
+		participants = append(participants, displayName(p.User))
+	}
+
 	r, err := s.templates.Trip(renderer.TripParams{
-		Title:       trip.Name,
-		Description: trip.Description,
-		Date:        trip.Date,
-		CreatedBy:   fmt.Sprintf("@%s", trip.CreatedBy.Username),
+		ID:           trip.ShortID(),
+		Title:        trip.Name,
+		Description:  trip.Description,
+		Date:         formatTripDate(trip.StartsAt),
+		MeetingPoint: formatMeetingPoint(trip.MeetingPoint),
+		Track:        formatTrack(trip.Track),
+		RouteLink:    routeLinkParams(trip.RouteLink),
+		Status:       tripStatusText(trip.Status),
+		CreatedBy:    displayName(trip.CreatedBy),
+		Participants: participants,
 	})
 	if err != nil {
 		return "", err
@@ -40,3 +105,43 @@ func (s *Service) renderTrip(trip *models.Trip) (string, error) {
 
 	return r, nil
 }
+
+// tripDateLayout is a layout of trip start time shown to users.
+const tripDateLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+func formatTripDate(t time.Time) string {
+	if t.IsZero() {
+		return "not set"
+	}
+
+	return t.Format(tripDateLayout)
+}
+
+// tripStatusText returns a trip status shown to users.
+func tripStatusText(status models.TripStatus) string {
+	switch status {
+	case models.TripDraft:
+		return "Draft"
+	case models.TripPublished:
+		return "Published"
+	case models.TripCancelled:
+		return "Cancelled"
+	case models.TripStarted:
+		return "Started"
+	case models.TripFinished:
+		return "Finished"
+	case models.TripArchived:
+		return "Archived"
+	default:
+		return "Unknown"
+	}
+}
+
+// displayName returns a name of the user shown to other users.
+func displayName(u *models.User) string {
+	if u.Username != "" {
+		return fmt.Sprintf("@%s", u.Username)
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s %s", u.Firstname, u.Lastname))
+}
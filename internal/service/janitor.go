@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+// JanitorConfig configures cleanup of idle sessions and abandoned trip drafts.
+type JanitorConfig struct {
+	// SessionTTL is how long a session may stay idle before it's expired.
+	// Draft trips not updated for the same time are removed. Zero disables the janitor.
+	SessionTTL time.Duration
+	// Interval is how often the cleanup runs.
+	Interval time.Duration
+	// NotifyExpired tells users that their unfinished conversation has expired.
+	NotifyExpired bool
+}
+
+func (c JanitorConfig) validate() error {
+	if c.SessionTTL < 0 {
+		return errors.New("janitor: session ttl is negative")
+	}
+
+	if c.SessionTTL > 0 && c.Interval <= 0 {
+		return errors.New("janitor: interval must be positive")
+	}
+
+	return nil
+}
+
+// WithJanitor enables periodic cleanup of idle sessions and abandoned trip drafts.
+func WithJanitor(cfg JanitorConfig) Option {
+	return func(o *options) {
+		o.janitor = cfg
+	}
+}
+
+// startJanitor runs the cleanup periodically until the returned function is called.
+func (s *Service) startJanitor(ctx context.Context) stopFunc {
+	if s.janitor.SessionTTL == 0 {
+		log.Info(ctx, "Janitor is disabled")
+
+		return func(context.Context) {}
+	}
+
+	return startWorker(ctx, "janitor", s.janitor.Interval, false, s.cleanup)
+}
+
+// cleanup expires idle sessions and removes abandoned trip drafts.
+func (s *Service) cleanup(ctx context.Context) {
+	cutoff := time.Now().Add(-s.janitor.SessionTTL)
+
+	expired, err := ops.ExpireSessions(ctx, s.backends, cutoff)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to expire sessions")
+	}
+
+	for _, sess := range expired {
+		if s.janitor.NotifyExpired {
+			s.notifyExpired(ctx, sess)
+		}
+	}
+
+	purged, err := ops.PurgeDraftTrips(ctx, s.backends, cutoff)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to purge draft trips")
+	}
+
+	if len(expired) > 0 || purged > 0 {
+		log.WithFields(ctx, log.Fields{
+			"expired_sessions": len(expired),
+			"purged_drafts":    purged,
+		}).Info("Cleanup finished")
+	}
+}
+
+// notifyExpired tells the user in the private chat that the conversation in progress has expired.
+func (s *Service) notifyExpired(ctx context.Context, sess *models.Session) {
+	if sess.UserState.Command == "" && sess.UserState.Trip == nil {
+		return
+	}
+
+	msg := "Your unfinished conversation has expired."
+
+	if trip := sess.UserState.Trip; trip != nil && trip.Status == models.TripDraft {
+		msg = "Your trip draft has expired and was removed."
+
+		if trip.Name != "" {
+			msg = fmt.Sprintf("Your trip draft %q has expired and was removed.", trip.Name)
+		}
+	}
+
+	msg += fmt.Sprintf(" Use /%s to create a new trip.", CmdNewTrip)
+
+	// Session chat may be a group the user talked in last, the notice is for the user only.
+	s.sendMessageTo(ctx, sess.User.ID, msg)
+}
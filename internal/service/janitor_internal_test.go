@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+)
+
+func TestJanitorConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     JanitorConfig
+		wantErr bool
+	}{
+		{
+			name: "disabled",
+			cfg:  JanitorConfig{},
+		},
+		{
+			name: "enabled",
+			cfg:  JanitorConfig{SessionTTL: time.Hour, Interval: time.Minute, NotifyExpired: true},
+		},
+		{
+			name:    "negative ttl",
+			cfg:     JanitorConfig{SessionTTL: -time.Hour, Interval: time.Minute},
+			wantErr: true,
+		},
+		{
+			name:    "no interval",
+			cfg:     JanitorConfig{SessionTTL: time.Hour},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_Cleanup_NotifyExpiredInPrivateChat(t *testing.T) {
+	ctx := context.Background()
+
+	b := opstest.NewBackends(t)
+
+	s, api := newTestService(t, b, WithJanitor(JanitorConfig{
+		SessionTTL:    time.Millisecond,
+		Interval:      time.Minute,
+		NotifyExpired: true,
+	}))
+
+	from := &tgbotapi.User{ID: 42, FirstName: "Rider"}
+
+	const groupID int64 = -100123
+
+	// User started a conversation and talked to the bot in a group last.
+	sess, err := s.resolveSession(ctx, from, groupID)
+	require.NoError(t, err)
+
+	sess.UserState.Command = CmdNewTrip
+	require.NoError(t, ops.UpdateSession(ctx, b, sess))
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.cleanup(ctx)
+
+	sent := api.sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, from.ID, sent[0].ChatID)
+	assert.Equal(t, "Your unfinished conversation has expired. Use /newtrip to create a new trip.", sent[0].Text)
+}
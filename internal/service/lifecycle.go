@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+// LifecycleConfig configures how trips move through their lifecycle as time goes.
+type LifecycleConfig struct {
+	// FinishAfter is how long after the start a trip is considered finished.
+	FinishAfter time.Duration
+	// ArchiveAfter is how long finished and cancelled trips stay visible before they are archived.
+	ArchiveAfter time.Duration
+}
+
+const (
+	defaultFinishAfter  = 6 * time.Hour
+	defaultArchiveAfter = 7 * 24 * time.Hour
+)
+
+// lifecycleInterval is how often trip statuses are advanced.
+const lifecycleInterval = time.Minute
+
+func (c LifecycleConfig) validate() error {
+	if c.FinishAfter <= 0 {
+		return errors.New("lifecycle: finish after must be positive")
+	}
+
+	if c.ArchiveAfter <= 0 {
+		return errors.New("lifecycle: archive after must be positive")
+	}
+
+	return nil
+}
+
+// WithLifecycle configures how trips move through their lifecycle.
+func WithLifecycle(cfg LifecycleConfig) Option {
+	return func(o *options) {
+		o.lifecycle = cfg
+	}
+}
+
+// startLifecycle advances trip statuses periodically until the returned function is called.
+func (s *Service) startLifecycle(ctx context.Context) stopFunc {
+	return startWorker(ctx, "lifecycle", lifecycleInterval, true, s.advanceTrips)
+}
+
+// advanceTrips starts, finishes and archives trips whose time has come.
+func (s *Service) advanceTrips(ctx context.Context) {
+	changed, err := ops.AdvanceTrips(ctx, s.backends, ops.AdvanceTripsParams{
+		Now:          s.now(),
+		FinishAfter:  s.lifecycle.FinishAfter,
+		ArchiveAfter: s.lifecycle.ArchiveAfter,
+	})
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to advance trips")
+	}
+
+	for _, t := range changed {
+		log.WithFields(ctx, log.Fields{
+			"trip_id": t.ID,
+			"status":  t.Status,
+		}).Info("Trip status advanced")
+
+		// Archiving only hides the trip from lists, its announcement already shows it's finished or cancelled.
+		if t.Status == models.TripArchived {
+			continue
+		}
+
+		s.updateAnnouncement(ctx, t)
+	}
+}
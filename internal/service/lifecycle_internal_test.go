@@ -0,0 +1,14 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleConfig_Validate(t *testing.T) {
+	assert.NoError(t, LifecycleConfig{FinishAfter: time.Hour, ArchiveAfter: time.Hour}.validate())
+	assert.Error(t, LifecycleConfig{ArchiveAfter: time.Hour}.validate())
+	assert.Error(t, LifecycleConfig{FinishAfter: time.Hour}.validate())
+}
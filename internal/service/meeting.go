@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/outbox"
+)
+
+const meetingPointPrompt = "Please share the meeting point location or venue, " +
+	"or type its coordinates or address, e.g. 50.4501, 30.5234 Central square"
+
+// maxMeetingLabelLen is a maximum length of the meeting point label in characters.
+const maxMeetingLabelLen = 200
+
+var errInvalidCoordinates = errors.New("coordinates are out of range")
+
+// coordinatesPattern matches decimal coordinates at the beginning of the text, e.g. "50.4501, 30.5234",
+// the rest of the text is a label.
+var coordinatesPattern = regexp.MustCompile(`^([-+]?\d{1,2}\.\d+)\s*,\s*([-+]?\d{1,3}\.\d+)(?:\s+(.*))?$`)
+
+func meetingPointKeyboard() *tgbotapi.ReplyKeyboardMarkup {
+	return tu.Keyboard(
+		tu.KeyboardRow(
+			tu.KeyboardButton("📍 Send my location").WithRequestLocation(),
+		),
+	).WithResizeKeyboard().WithInputFieldPlaceholder("Coordinates or address").WithOneTimeKeyboard()
+}
+
+// parseMeetingPoint parses typed coordinates optionally followed by a label, any other text is an address.
+// Shared locations and venues are converted to the same text, see meetingPointInput.
+func parseMeetingPoint(text string) (*models.MeetingPoint, error) {
+	text = strings.TrimSpace(text)
+
+	m := coordinatesPattern.FindStringSubmatch(text)
+	if m == nil {
+		return &models.MeetingPoint{Label: text}, nil
+	}
+
+	lat, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse latitude: %w", err)
+	}
+
+	lon, err := strconv.ParseFloat(m[2], 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse longitude: %w", err)
+	}
+
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil, errInvalidCoordinates
+	}
+
+	return &models.MeetingPoint{
+		Label:       strings.TrimSpace(m[3]),
+		HasLocation: true,
+		Latitude:    lat,
+		Longitude:   lon,
+	}, nil
+}
+
+// meetingPointInput converts a shared location or venue to the text accepted by parseMeetingPoint.
+func meetingPointInput(msg *tgbotapi.Message) string {
+	if v := msg.Venue; v != nil {
+		label := v.Title
+
+		if v.Address != "" && v.Address != v.Title {
+			label = fmt.Sprintf("%s, %s", v.Title, v.Address)
+		}
+
+		return fmt.Sprintf("%s %s", formatCoordinates(v.Location.Latitude, v.Location.Longitude), label)
+	}
+
+	return formatCoordinates(msg.Location.Latitude, msg.Location.Longitude)
+}
+
+func formatCoordinates(lat, lon float64) string {
+	return fmt.Sprintf("%.6f, %.6f", lat, lon)
+}
+
+// formatMeetingPoint returns a meeting point shown to users.
+func formatMeetingPoint(mp *models.MeetingPoint) string {
+	switch {
+	case mp == nil:
+		return "not set"
+	case mp.Label != "":
+		return mp.Label
+	default:
+		return formatCoordinates(mp.Latitude, mp.Longitude)
+	}
+}
+
+// validateMeetingPoint checks the meeting point entered on StateNewTripMeetingPoint and StateEditTripMeetingPoint steps.
+func (s *Service) validateMeetingPoint(_ context.Context, _ *models.Session, text string) error {
+	mp, err := parseMeetingPoint(text)
+	if err != nil {
+		return conversation.Invalid("These coordinates are not valid")
+	}
+
+	if mp.HasLocation && mp.Label == "" {
+		return nil
+	}
+
+	return validateText("Meeting point", mp.Label, maxMeetingLabelLen)
+}
+
+func (s *Service) storeTripMeetingPoint(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	mp, err := parseMeetingPoint(text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse meeting point: %w", err)
+	}
+
+	return 0, s.updateDraftTrip(ctx, sess, ops.UpdateTripParams{
+		MeetingPoint: mp,
+	})
+}
+
+// locationHandler handles shared locations and venues, which are accepted as the meeting point.
+func (s *Service) locationHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", "location"))
+
+		log.Debug(ctx, "Called location handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		_, ok := s.conversations.Active(sess)
+		if !ok || !sess.UserState.State.IsAny(models.StateNewTripMeetingPoint, models.StateEditTripMeetingPoint) {
+			s.sendMessage(ctx, "I don't need a location right now.")
+
+			return
+		}
+
+		s.runConversation(ctx, sess, func(ctx context.Context) error {
+			return s.conversations.Handle(ctx, sess, meetingPointInput(update.Message))
+		})
+	}
+}
+
+// anyLocation matches messages with a shared location or venue.
+func anyLocation() th.Predicate {
+	return func(update tgbotapi.Update) bool {
+		return update.Message != nil && (update.Message.Location != nil || update.Message.Venue != nil)
+	}
+}
+
+// postMeetingPoint posts the meeting point location in reply to the trip announcement,
+// so riders can open it in their maps app. The previously posted location is removed.
+func (s *Service) postMeetingPoint(ctx context.Context, trip *models.Trip) {
+	if trip.ChatID == 0 || trip.MessageID == 0 {
+		return
+	}
+
+	if trip.MeetingMessageID != 0 {
+		err := s.outbox.Send(ctx, trip.ChatID, func() error {
+			return s.bot.Client().DeleteMessage(tu.Delete(tu.ID(trip.ChatID), trip.MeetingMessageID))
+		})
+		if err != nil {
+			log.WithError(ctx, err).WithField("trip_id", trip.ID).Warn("Failed to delete meeting point message")
+		}
+	}
+
+	var messageID int
+
+	if mp := trip.MeetingPoint; mp != nil && mp.HasLocation {
+		reply := &tgbotapi.ReplyParameters{MessageID: trip.MessageID, AllowSendingWithoutReply: true}
+
+		msg, err := outbox.Do(ctx, s.outbox, trip.ChatID, func() (*tgbotapi.Message, error) {
+			if mp.Label == "" {
+				return s.bot.Client().SendLocation(
+					tu.Location(tu.ID(trip.ChatID), mp.Latitude, mp.Longitude).WithReplyParameters(reply))
+			}
+
+			return s.bot.Client().SendVenue(
+				tu.Venue(tu.ID(trip.ChatID), mp.Latitude, mp.Longitude, "Meeting point", mp.Label).
+					WithReplyParameters(reply))
+		})
+		if err != nil {
+			log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to send meeting point")
+		} else {
+			messageID = msg.MessageID
+		}
+	}
+
+	if messageID == trip.MeetingMessageID {
+		return
+	}
+
+	if _, err := ops.UpdateTrip(ctx, s.backends, trip.ID, ops.UpdateTripParams{
+		MeetingMessageID: &messageID,
+	}); err != nil {
+		log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to store meeting point message")
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestParseMeetingPoint(t *testing.T) {
+	testCases := []struct {
+		name    string
+		text    string
+		want    *models.MeetingPoint
+		wantErr error
+	}{
+		{
+			name: "coordinates",
+			text: "50.4501, 30.5234",
+			want: &models.MeetingPoint{HasLocation: true, Latitude: 50.4501, Longitude: 30.5234},
+		},
+		{
+			name: "coordinates with label",
+			text: " -33.8568,151.2153  Opera House, Sydney ",
+			want: &models.MeetingPoint{
+				Label: "Opera House, Sydney", HasLocation: true, Latitude: -33.8568, Longitude: 151.2153,
+			},
+		},
+		{
+			name: "address",
+			text: "12 Main street, 5th floor",
+			want: &models.MeetingPoint{Label: "12 Main street, 5th floor"},
+		},
+		{
+			name: "integer numbers are an address",
+			text: "5, 10 Main street",
+			want: &models.MeetingPoint{Label: "5, 10 Main street"},
+		},
+		{
+			name:    "latitude out of range",
+			text:    "95.1, 30.5",
+			wantErr: errInvalidCoordinates,
+		},
+		{
+			name:    "longitude out of range",
+			text:    "50.1, -180.5",
+			wantErr: errInvalidCoordinates,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMeetingPoint(tt.text)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMeetingPointInput(t *testing.T) {
+	location := tgbotapi.Location{Latitude: 50.4501, Longitude: 30.5234}
+
+	testCases := []struct {
+		name string
+		msg  *tgbotapi.Message
+		want *models.MeetingPoint
+	}{
+		{
+			name: "location",
+			msg:  &tgbotapi.Message{Location: &location},
+			want: &models.MeetingPoint{HasLocation: true, Latitude: 50.4501, Longitude: 30.5234},
+		},
+		{
+			name: "venue",
+			msg: &tgbotapi.Message{
+				Location: &location,
+				Venue:    &tgbotapi.Venue{Location: location, Title: "Central square", Address: "Khreshchatyk St"},
+			},
+			want: &models.MeetingPoint{
+				Label: "Central square, Khreshchatyk St", HasLocation: true, Latitude: 50.4501, Longitude: 30.5234,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMeetingPoint(meetingPointInput(tt.msg))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatMeetingPoint(t *testing.T) {
+	assert.Equal(t, "not set", formatMeetingPoint(nil))
+	assert.Equal(t, "Central square", formatMeetingPoint(&models.MeetingPoint{
+		Label: "Central square", HasLocation: true, Latitude: 50.4501, Longitude: 30.5234,
+	}))
+	assert.Equal(t, "50.450100, 30.523400", formatMeetingPoint(&models.MeetingPoint{
+		HasLocation: true, Latitude: 50.4501, Longitude: 30.5234,
+	}))
+}
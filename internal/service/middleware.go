@@ -10,6 +10,7 @@
 	th "github.com/mymmrac/telego/telegohandler"
 	log "github.com/obalunenko/logger"
 
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/sessions"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/users"
@@ -27,10 +28,9 @@ func (s *Service) loggerMiddleware() th.Middleware {
 	return func(bot *tgbotapi.Bot, update tgbotapi.Update, next th.Handler) {
 		ctx := update.Context()
 
-		sess := sessionFromContext(ctx)
-		if sess == nil {
-			log.Error(ctx, "Session is nil")
-		} else {
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "update_id", update.UpdateID))
+
+		if sess := sessionFromContext(ctx); sess != nil {
 			ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "user_id", sess.User.ID))
 			ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "chat_id", sess.ChatID))
 		}
@@ -45,8 +45,17 @@ func (s *Service) setSessionMiddleware() th.Middleware {
 	return func(bot *tgbotapi.Bot, update tgbotapi.Update, next th.Handler) {
 		ctx := update.Context()
 
-		uid := update.Message.From.ID
-		cid := update.Message.Chat.ID
+		from, cid, ok := updateSender(update)
+		if !ok {
+			// Updates like channel posts or polls have no user, so they are handled without session.
+			log.Debug(ctx, "Update has no sender, session is not set")
+
+			next(bot, update)
+
+			return
+		}
+
+		uid := from.ID
 
 		if uid == s.bot.ID() {
 			// Don't create a session for bot.
@@ -55,42 +64,15 @@ func (s *Service) setSessionMiddleware() th.Middleware {
 			return
 		}
 
-		user, err := ops.GetUser(ctx, s.backends, uid)
+		session, err := s.resolveSession(ctx, from, cid)
 		if err != nil {
-			if errors.Is(err, users.ErrNotFound) {
-				p := ops.CreateUserParams{
-					UserID:    uid,
-					Username:  update.Message.From.Username,
-					Firstname: update.Message.From.FirstName,
-					Lastname:  update.Message.From.LastName,
-				}
-
-				user, err = ops.CreateUser(ctx, s.backends, p)
-				if err != nil {
-					log.WithError(ctx, err).Error("Failed to create user")
-
-					return
-				}
-			}
-		}
-
-		session, err := ops.GetSession(ctx, s.backends, user)
-		if err != nil && !errors.Is(err, sessions.ErrNotFound) {
-			log.WithError(ctx, err).Error("Failed to get session")
+			log.WithError(ctx, err).WithField("user_id", uid).Error("Failed to resolve session")
 
 			return
 		}
 
-		if session == nil {
-			session, err = ops.CreateSession(ctx, s.backends, ops.CreateSessionParams{
-				User:   user,
-				ChatID: cid,
-			})
-			if err != nil {
-				log.WithError(ctx, err).Error("Failed to create session")
-
-				return
-			}
+		if err = ops.TouchSession(ctx, s.backends, session); err != nil {
+			log.WithError(ctx, err).WithField("user_id", uid).Warn("Failed to mark session as active")
 		}
 
 		ctx = contextWithSession(ctx, session)
@@ -101,6 +83,60 @@ func (s *Service) setSessionMiddleware() th.Middleware {
 	}
 }
 
+// resolveSession returns the user session, the user and the session are created if they don't exist yet.
+// The session chat is the chat of the current update, so replies go where the user talks to the bot.
+func (s *Service) resolveSession(ctx context.Context, from *tgbotapi.User, chatID int64) (*models.Session, error) {
+	user, err := s.resolveUser(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := ops.GetSession(ctx, s.backends, user)
+	if err == nil {
+		session.ChatID = chatID
+
+		return session, nil
+	}
+
+	if !errors.Is(err, sessions.ErrNotFound) {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+
+	session, err = ops.CreateSession(ctx, s.backends, ops.CreateSessionParams{
+		User:   user,
+		ChatID: chatID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// resolveUser returns the user, it is created if it doesn't exist yet.
+func (s *Service) resolveUser(ctx context.Context, from *tgbotapi.User) (*models.User, error) {
+	user, err := ops.GetUser(ctx, s.backends, from.ID)
+	if err == nil {
+		return user, nil
+	}
+
+	if !errors.Is(err, users.ErrNotFound) {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	user, err = ops.CreateUser(ctx, s.backends, ops.CreateUserParams{
+		UserID:    from.ID,
+		Username:  from.Username,
+		Firstname: from.FirstName,
+		Lastname:  from.LastName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+
+	return user, nil
+}
+
 // PanicRecovery is a middleware that will recover handler from panic
 func (s *Service) panicRecovery() th.Middleware {
 	return func(bot *tgbotapi.Bot, update tgbotapi.Update, next th.Handler) {
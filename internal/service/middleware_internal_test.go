@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+)
+
+func TestService_ResolveSession_ChatFollowsUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	s := &Service{backends: opstest.NewBackends(t)}
+
+	from := &tgbotapi.User{ID: 42, Username: "rider", FirstName: "Rider"}
+
+	const groupID int64 = -100123
+
+	sess, err := s.resolveSession(ctx, from, groupID)
+	require.NoError(t, err)
+	assert.Equal(t, groupID, sess.ChatID)
+
+	sess, err = s.resolveSession(ctx, from, from.ID)
+	require.NoError(t, err)
+	assert.Equal(t, from.ID, sess.ChatID)
+
+	sess, err = s.resolveSession(ctx, from, groupID)
+	require.NoError(t, err)
+	assert.Equal(t, groupID, sess.ChatID)
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/permissions"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/repository/chats"
+)
+
+var (
+	// errNotAllowedToAnnounce is returned when the chat policy doesn't allow the user to announce trips.
+	errNotAllowedToAnnounce = errors.New("not allowed to announce trips in the chat")
+	// errBotCannotPin is returned when the bot has no right to pin messages in the chat.
+	errBotCannotPin = errors.New("bot has no right to pin messages in the chat")
+)
+
+// chatPolicy returns the chat announce policy, unknown policies fall back to the default one.
+func chatPolicy(chat *models.Chat) permissions.Policy {
+	p, err := permissions.ParsePolicy(chat.Policy)
+	if err != nil {
+		return permissions.DefaultPolicy
+	}
+
+	return p
+}
+
+// policyDescription returns a human-readable description of the policy.
+func policyDescription(p permissions.Policy) string {
+	switch p {
+	case permissions.PolicyAnyone:
+		return "any member may announce trips"
+	case permissions.PolicyAdmins:
+		return "only administrators may announce trips"
+	case permissions.PolicyLeaders:
+		return "only approved ride leaders and administrators may announce trips"
+	default:
+		return "nobody may announce trips"
+	}
+}
+
+// checkAnnounce checks if the user may announce trips in the group chat.
+func (s *Service) checkAnnounce(ctx context.Context, chat *models.Chat, userID int64) error {
+	policy := chatPolicy(chat)
+
+	if policy == permissions.PolicyAnyone {
+		return nil
+	}
+
+	rights, err := s.perms.Rights(ctx, chat.ID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check user rights: %w", err)
+	}
+
+	var leader bool
+
+	if policy == permissions.PolicyLeaders && !rights.Admin {
+		leader, err = ops.IsLeader(ctx, s.backends, chat.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check leader: %w", err)
+		}
+	}
+
+	if !permissions.CanAnnounce(policy, rights, leader) {
+		return fmt.Errorf("%w: %s", errNotAllowedToAnnounce, policyDescription(policy))
+	}
+
+	return nil
+}
+
+// pinAnnouncement pins the announcement message, in groups the bot rights are checked first.
+func (s *Service) pinAnnouncement(ctx context.Context, msg *tgbotapi.Message) error {
+	if isGroup(msg.Chat) {
+		rights, err := s.perms.Rights(ctx, msg.Chat.ID, s.bot.ID())
+		if err != nil {
+			return fmt.Errorf("failed to check bot rights: %w", err)
+		}
+
+		if !rights.CanPin {
+			return errBotCannotPin
+		}
+	}
+
+	err := s.outbox.Send(ctx, msg.Chat.ID, func() error {
+		return s.bot.Client().PinChatMessage(&tgbotapi.PinChatMessageParams{
+			ChatID:              tu.ID(msg.Chat.ID),
+			MessageID:           msg.MessageID,
+			DisableNotification: false,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+
+	return nil
+}
+
+// unpinAnnouncement unpins the posted trip announcement.
+func (s *Service) unpinAnnouncement(ctx context.Context, trip *models.Trip) error {
+	if trip.ChatID == 0 || trip.MessageID == 0 {
+		return errors.New("announcement message is unknown")
+	}
+
+	err := s.outbox.Send(ctx, trip.ChatID, func() error {
+		return s.bot.Client().UnpinChatMessage(&tgbotapi.UnpinChatMessageParams{
+			ChatID:    tu.ID(trip.ChatID),
+			MessageID: trip.MessageID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unpin message: %w", err)
+	}
+
+	return nil
+}
+
+func isGroup(chat tgbotapi.Chat) bool {
+	return chat.Type == tgbotapi.ChatTypeGroup || chat.Type == tgbotapi.ChatTypeSupergroup
+}
+
+func (s *Service) policyHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdPolicy))
+
+		log.Debug(ctx, "Called policy handler")
+
+		chat, ok := s.groupCommandChat(ctx, update)
+		if !ok {
+			return
+		}
+
+		arg := commandArg(update)
+		if arg == "" {
+			names := make([]string, 0, len(permissions.Policies()))
+
+			for _, p := range permissions.Policies() {
+				names = append(names, string(p))
+			}
+
+			msg := fmt.Sprintf("Announce policy is %q: %s.\nAdministrators can change it with /%s <%s>.",
+				chatPolicy(chat), policyDescription(chatPolicy(chat)), CmdPolicy, strings.Join(names, "|"))
+
+			s.sendMessageTo(ctx, chat.ID, msg)
+
+			return
+		}
+
+		if !s.requireAdmin(ctx, update, chat) {
+			return
+		}
+
+		policy, err := permissions.ParsePolicy(strings.ToLower(arg))
+		if err != nil {
+			s.sendMessageTo(ctx, chat.ID, fmt.Sprintf("Unknown policy %q.", arg))
+
+			return
+		}
+
+		if err = ops.SetChatPolicy(ctx, s.backends, chat.ID, string(policy)); err != nil {
+			log.WithError(ctx, err).Error("Failed to set chat policy")
+
+			return
+		}
+
+		s.sendMessageTo(ctx, chat.ID, fmt.Sprintf("Announce policy is set to %q: %s.", policy, policyDescription(policy)))
+	}
+}
+
+func (s *Service) leaderHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdLeader))
+
+		log.Debug(ctx, "Called leader handler")
+
+		chat, ok := s.groupCommandChat(ctx, update)
+		if !ok {
+			return
+		}
+
+		action, ref, _ := strings.Cut(commandArg(update), " ")
+
+		switch strings.ToLower(action) {
+		case "":
+			s.listLeaders(ctx, chat)
+		case "add", "remove":
+			if !s.requireAdmin(ctx, update, chat) {
+				return
+			}
+
+			user, err := s.commandTargetUser(ctx, update, ref)
+			if err != nil {
+				if errors.Is(err, ops.ErrUserNotFound) {
+					s.sendMessageTo(ctx, chat.ID, "I don't know this user yet. "+
+						"Reply to their message with the command or ask them to send me /start first.")
+
+					return
+				}
+
+				log.WithError(ctx, err).Error("Failed to find user")
+
+				return
+			}
+
+			s.changeLeader(ctx, chat, user, strings.EqualFold(action, "add"))
+		default:
+			s.sendMessageTo(ctx, chat.ID, fmt.Sprintf("Usage: /%s [add|remove @username], or reply to a user message.", CmdLeader))
+		}
+	}
+}
+
+func (s *Service) listLeaders(ctx context.Context, chat *models.Chat) {
+	list, err := ops.ListLeaders(ctx, s.backends, chat.ID)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to list leaders")
+
+		return
+	}
+
+	if len(list) == 0 {
+		s.sendMessageTo(ctx, chat.ID, "There are no approved ride leaders in this chat.")
+
+		return
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("Approved ride leaders:\n")
+
+	for _, u := range list {
+		sb.WriteString(fmt.Sprintf("  - %s\n", displayName(u)))
+	}
+
+	s.sendMessageTo(ctx, chat.ID, sb.String())
+}
+
+func (s *Service) changeLeader(ctx context.Context, chat *models.Chat, user *models.User, add bool) {
+	sess := sessionFromContext(ctx)
+	if sess == nil {
+		log.Error(ctx, "Session is nil")
+
+		return
+	}
+
+	var err error
+
+	if add {
+		err = ops.AddLeader(ctx, s.backends, chat.ID, user.ID, sess.User.ID)
+	} else {
+		err = ops.RemoveLeader(ctx, s.backends, chat.ID, user.ID)
+	}
+
+	switch {
+	case err == nil && add:
+		s.sendMessageTo(ctx, chat.ID, fmt.Sprintf("%s is now an approved ride leader.", displayName(user)))
+	case err == nil:
+		s.sendMessageTo(ctx, chat.ID, fmt.Sprintf("%s is not a ride leader anymore.", displayName(user)))
+	case errors.Is(err, ops.ErrAlreadyLeader):
+		s.sendMessageTo(ctx, chat.ID, fmt.Sprintf("%s is already a ride leader.", displayName(user)))
+	case errors.Is(err, ops.ErrNotLeader):
+		s.sendMessageTo(ctx, chat.ID, fmt.Sprintf("%s is not a ride leader.", displayName(user)))
+	default:
+		log.WithError(ctx, err).WithField("leader_id", user.ID).Error("Failed to change leader")
+	}
+}
+
+// commandTargetUser returns a user the command is applied to: the author of the replied message or the user by username.
+func (s *Service) commandTargetUser(ctx context.Context, update tgbotapi.Update, ref string) (*models.User, error) {
+	if reply := update.Message.ReplyToMessage; reply != nil && reply.From != nil && !reply.From.IsBot {
+		return s.resolveUser(ctx, reply.From)
+	}
+
+	return ops.FindUserByUsername(ctx, s.backends, strings.TrimSpace(ref))
+}
+
+// groupCommandChat returns the registered group chat the command was sent to.
+// Chats the bot was added to before the registry existed are registered on the fly.
+func (s *Service) groupCommandChat(ctx context.Context, update tgbotapi.Update) (*models.Chat, bool) {
+	msg := update.Message
+
+	if !isGroup(msg.Chat) {
+		s.sendMessageTo(ctx, msg.Chat.ID, "Use this command in a group chat.")
+
+		return nil, false
+	}
+
+	chat, err := ops.GetChat(ctx, s.backends, msg.Chat.ID)
+	if err == nil && chat.Active {
+		return chat, true
+	}
+
+	if err != nil && !errors.Is(err, chats.ErrNotFound) {
+		log.WithError(ctx, err).Error("Failed to get chat")
+
+		return nil, false
+	}
+
+	var addedBy int64
+
+	if msg.From != nil {
+		addedBy = msg.From.ID
+	}
+
+	chat, err = ops.SaveChat(ctx, s.backends, ops.SaveChatParams{
+		ChatID:  msg.Chat.ID,
+		Title:   msg.Chat.Title,
+		Type:    msg.Chat.Type,
+		Active:  true,
+		AddedBy: addedBy,
+	})
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to save chat")
+
+		return nil, false
+	}
+
+	return chat, true
+}
+
+// requireAdmin checks the command sender is the chat administrator and reports if not.
+func (s *Service) requireAdmin(ctx context.Context, update tgbotapi.Update, chat *models.Chat) bool {
+	if update.Message.From == nil {
+		return false
+	}
+
+	rights, err := s.perms.Rights(ctx, chat.ID, update.Message.From.ID)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to check user rights")
+
+		s.sendMessageTo(ctx, chat.ID, "I can't check your rights in this chat, please try again later.")
+
+		return false
+	}
+
+	if !rights.Admin {
+		s.sendMessageTo(ctx, chat.ID, "Only chat administrators can do this.")
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/outbox"
+)
+
+const (
+	callbackPublishPrefix = "publish:"
+	callbackPublishBack   = callbackPublishPrefix + "back"
+)
+
+// askPublishChat asks the trip creator where to publish the trip.
+// If the creator talks to the bot privately and there are no groups they may announce in,
+// the trip is published into the private chat.
+func (s *Service) askPublishChat(ctx context.Context, sess *models.Session) error {
+	groups, err := s.announceableChats(ctx, sess.User.ID)
+	if err != nil {
+		return err
+	}
+
+	private := isPrivateChat(sess.User, sess.ChatID)
+
+	if len(groups) == 0 && private {
+		return s.publishTrip(ctx, sess, sess.ChatID)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(groups)+2)
+
+	if private {
+		rows = append(rows, tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton("This chat").
+				WithCallbackData(callbackPublishPrefix+strconv.FormatInt(sess.ChatID, 10)),
+		))
+	}
+
+	for _, g := range groups {
+		rows = append(rows, tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(g.Title).
+				WithCallbackData(callbackPublishPrefix+strconv.FormatInt(g.ID, 10)),
+		))
+	}
+
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(conversation.BackButton).WithCallbackData(callbackPublishBack),
+	))
+
+	text := "Where should I publish the trip?"
+	if len(groups) == 0 {
+		text = "There is no chat you may announce trips in. Go back or message me privately to publish the trip there."
+	}
+
+	return s.sendWithKeyboard(ctx, text, tu.InlineKeyboard(rows...))
+}
+
+// isPrivateChat reports whether the chat is the private chat with the user, its ID is the same as the user ID.
+func isPrivateChat(user *models.User, chatID int64) bool {
+	return chatID == user.ID
+}
+
+func (s *Service) publishCallbackHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "callback_handler", "publish"))
+
+		query := update.CallbackQuery
+
+		log.WithField(ctx, "data", query.Data).Debug("Called publish callback handler")
+
+		defer s.answerCallback(ctx, query.ID, "")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		if sess.UserState.State != models.StateNewTripPublish || sess.UserState.Trip == nil {
+			log.WithField(ctx, "UserState", sess.UserState.State.String()).Warn("No trip is waiting for publishing")
+
+			return
+		}
+
+		if query.Data == callbackPublishBack {
+			s.removeInlineKeyboard(ctx, query)
+
+			s.runConversation(ctx, sess, func(ctx context.Context) error {
+				return s.conversations.Back(ctx, sess)
+			})
+
+			return
+		}
+
+		chatID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, callbackPublishPrefix), 10, 64)
+		if err != nil {
+			log.WithError(ctx, err).Warn("Invalid publish chat ID")
+
+			return
+		}
+
+		// Group chats are checked even when the trip is created there: the bot may have left the chat
+		// or the user may have lost the right to announce since the keyboard was sent.
+		if !isPrivateChat(sess.User, chatID) {
+			chat, err := ops.GetChat(ctx, s.backends, chatID)
+			if err != nil || !chat.Active {
+				log.WithError(ctx, err).WithField("target_chat_id", chatID).Warn("Publish chat is not available")
+
+				s.sendMessage(ctx, "I'm not a member of this chat anymore, please choose another one.")
+
+				return
+			}
+
+			if err = s.checkAnnounce(ctx, chat, sess.User.ID); err != nil {
+				log.WithError(ctx, err).WithField("target_chat_id", chatID).Warn("User may not announce in chat")
+
+				s.sendMessage(ctx, announceErrorText(chat, err))
+
+				return
+			}
+		}
+
+		s.removeInlineKeyboard(ctx, query)
+
+		if err = s.publishTrip(ctx, sess, chatID); err != nil {
+			log.WithError(ctx, err).Error("Failed to publish trip")
+		}
+
+		if err = s.saveSession(ctx, sess); err != nil {
+			log.WithError(ctx, err).Error("Failed to update session")
+		}
+	}
+}
+
+// publishTrip posts the trip announcement into the chat and pins it.
+// The trip is marked as published only once the announcement is sent, so a failed send leaves it a draft.
+func (s *Service) publishTrip(ctx context.Context, sess *models.Session, chatID int64) error {
+	if sess.UserState.Trip == nil {
+		return errors.New("no trip to publish")
+	}
+
+	draft, err := ops.UpdateTrip(ctx, s.backends, sess.UserState.Trip.ID, ops.UpdateTripParams{
+		ChatID: &chatID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update trip: %w", err)
+	}
+
+	if !draft.Status.CanTransitionTo(models.TripPublished) {
+		return fmt.Errorf("%w: %s -> %s", ops.ErrInvalidTransition, draft.Status, models.TripPublished)
+	}
+
+	// The announcement shows the trip as it will be once published.
+	announced := *draft
+	announced.Status = models.TripPublished
+
+	msgtxt, err := s.announcementText(&announced)
+	if err != nil {
+		return err
+	}
+
+	msg := tu.Message(tu.ID(chatID), msgtxt).
+		WithParseMode(tgbotapi.ModeHTML).
+		WithReplyMarkup(announcementKeyboard(&announced))
+
+	resp, err := s.send(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	trip, err := ops.SetTripStatus(ctx, s.backends, draft.ID, models.TripPublished)
+	if err != nil {
+		s.deleteMessage(ctx, chatID, resp.MessageID)
+
+		return fmt.Errorf("failed to publish trip: %w", err)
+	}
+
+	sess.UserState.Trip = trip
+
+	// Announcement is tracked, so it's updated in place when the trip changes.
+	if _, err = ops.UpdateTrip(ctx, s.backends, trip.ID, ops.UpdateTripParams{
+		MessageID: &resp.MessageID,
+	}); err != nil {
+		log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to store announcement message")
+	}
+
+	trip.MessageID = resp.MessageID
+
+	s.postMeetingPoint(ctx, trip)
+
+	if chatID != sess.ChatID {
+		s.sendMessage(ctx, fmt.Sprintf("Trip is published in %q!", resp.Chat.Title))
+	}
+
+	if err = s.pinAnnouncement(ctx, resp); err != nil {
+		log.WithError(ctx, err).WithField("target_chat_id", chatID).Warn("Failed to pin announcement")
+
+		s.sendMessage(ctx, pinErrorText(resp.Chat, err))
+	}
+
+	sess.UserState.State = models.StateStart
+	sess.UserState.Command = ""
+	sess.UserState.Trip = nil
+
+	return nil
+}
+
+// announceableChats returns active group chats the user may announce trips in.
+func (s *Service) announceableChats(ctx context.Context, userID int64) ([]*models.Chat, error) {
+	groups, err := ops.ListActiveChats(ctx, s.backends)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chats: %w", err)
+	}
+
+	allowed := make([]*models.Chat, 0, len(groups))
+
+	for _, g := range groups {
+		if err = s.checkAnnounce(ctx, g, userID); err != nil {
+			if !errors.Is(err, errNotAllowedToAnnounce) {
+				log.WithError(ctx, err).WithField("target_chat_id", g.ID).Warn("Failed to check announce permission")
+			}
+
+			continue
+		}
+
+		allowed = append(allowed, g)
+	}
+
+	return allowed, nil
+}
+
+func announceErrorText(chat *models.Chat, err error) string {
+	if errors.Is(err, errNotAllowedToAnnounce) {
+		return fmt.Sprintf("You can't announce trips in %q: %s.", chat.Title, policyDescription(chatPolicy(chat)))
+	}
+
+	return fmt.Sprintf("I can't check your rights in %q, please try again later.", chat.Title)
+}
+
+func pinErrorText(chat tgbotapi.Chat, err error) string {
+	if errors.Is(err, errBotCannotPin) {
+		return fmt.Sprintf("I couldn't pin the announcement in %q: "+
+			"make me an administrator with the right to pin messages there.", chat.Title)
+	}
+
+	return "I couldn't pin the announcement, please pin it manually."
+}
+
+// deleteMessage deletes the message sent by the bot, failures are only logged.
+func (s *Service) deleteMessage(ctx context.Context, chatID int64, messageID int) {
+	err := s.outbox.Send(ctx, chatID, func() error {
+		return s.bot.Client().DeleteMessage(tu.Delete(tu.ID(chatID), messageID))
+	})
+	if err != nil {
+		log.WithError(ctx, err).WithField("target_chat_id", chatID).Warn("Failed to delete message")
+	}
+}
+
+// removeInlineKeyboard removes the inline keyboard from the message the callback came from.
+func (s *Service) removeInlineKeyboard(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil || !query.Message.IsAccessible() {
+		return
+	}
+
+	chatID := query.Message.GetChat().ID
+
+	_, err := outbox.Do(ctx, s.outbox, chatID, func() (*tgbotapi.Message, error) {
+		return s.bot.Client().EditMessageReplyMarkup(&tgbotapi.EditMessageReplyMarkupParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: query.Message.GetMessageID(),
+		})
+	})
+	if err != nil {
+		log.WithError(ctx, err).Warn("Failed to remove inline keyboard")
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+// ReminderConfig configures reminders about upcoming trips.
+type ReminderConfig struct {
+	// Offsets are how long before the trip start reminders are sent, e.g. 24h and 1h.
+	// Empty offsets disable reminders.
+	Offsets []time.Duration
+	// Interval is how often due reminders are checked.
+	Interval time.Duration
+	// Participants sends reminders to private chats of trip participants.
+	Participants bool
+	// Chat sends reminders to the chat the trip is announced in.
+	Chat bool
+}
+
+func (c ReminderConfig) validate() error {
+	if len(c.Offsets) == 0 {
+		return nil
+	}
+
+	for _, o := range c.Offsets {
+		if o < time.Minute {
+			return fmt.Errorf("reminders: offset %s is less than a minute", o)
+		}
+	}
+
+	if c.Interval <= 0 {
+		return errors.New("reminders: interval must be positive")
+	}
+
+	if !c.Participants && !c.Chat {
+		return errors.New("reminders: no recipients, enable participants or chat")
+	}
+
+	return nil
+}
+
+// WithReminders enables reminders about upcoming trips.
+func WithReminders(cfg ReminderConfig) Option {
+	return func(o *options) {
+		o.reminders = cfg
+	}
+}
+
+// startReminders sends due reminders periodically until the returned function is called.
+// Reminders missed while the bot was down are sent right away.
+func (s *Service) startReminders(ctx context.Context) stopFunc {
+	if len(s.reminders.Offsets) == 0 {
+		log.Info(ctx, "Reminders are disabled")
+
+		return func(context.Context) {}
+	}
+
+	return startWorker(ctx, "reminders", s.reminders.Interval, true, s.sendReminders)
+}
+
+// sendReminders sends all due reminders.
+func (s *Service) sendReminders(ctx context.Context) {
+	now := s.now()
+
+	due, err := ops.ListDueReminders(ctx, s.backends, now, s.reminders.Offsets)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to list due reminders")
+
+		return
+	}
+
+	for _, r := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		rctx := log.ContextWithLogger(ctx, log.WithFields(ctx, log.Fields{
+			"trip_id": r.Trip.ID,
+			"offset":  r.Offset.String(),
+		}))
+
+		// Reminder is claimed before sending, so a failure in the middle never leads to duplicates.
+		if err = ops.ClaimReminder(rctx, s.backends, r.Trip.ID, r.Offset); err != nil {
+			if !errors.Is(err, ops.ErrReminderSent) {
+				log.WithError(rctx, err).Error("Failed to claim reminder")
+			}
+
+			continue
+		}
+
+		s.remind(rctx, r.Trip, now)
+	}
+}
+
+// remind sends the trip reminder to its recipients.
+func (s *Service) remind(ctx context.Context, trip *models.Trip, now time.Time) {
+	text := reminderText(trip, now)
+
+	if s.reminders.Chat && trip.ChatID != 0 {
+		s.sendMessageTo(ctx, trip.ChatID, text)
+	}
+
+	if s.reminders.Participants {
+		for _, p := range trip.Participants {
+			// Private chat ID is the same as the user ID.
+			s.sendMessageTo(ctx, p.User.ID, text)
+		}
+	}
+
+	log.WithField(ctx, "participants", len(trip.Participants)).Info("Reminder sent")
+}
+
+func reminderText(trip *models.Trip, now time.Time) string {
+	return fmt.Sprintf("⏰ Reminder: %q starts in %s, on %s.",
+		trip.Name, humanDuration(trip.StartsAt.Sub(now)), formatTripDate(trip.StartsAt))
+}
+
+// humanDuration formats the duration rounded to minutes with up to two largest units, e.g. "1 day 2 hours".
+func humanDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+
+	if d < time.Minute {
+		return "less than a minute"
+	}
+
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{size: 24 * time.Hour, name: "day"},
+		{size: time.Hour, name: "hour"},
+		{size: time.Minute, name: "minute"},
+	}
+
+	parts := make([]string, 0, 2)
+
+	for _, u := range units {
+		n := d / u.size
+		d -= n * u.size
+
+		if n == 0 {
+			if len(parts) > 0 {
+				break
+			}
+
+			continue
+		}
+
+		name := u.name
+		if n > 1 {
+			name += "s"
+		}
+
+		parts = append(parts, fmt.Sprintf("%d %s", n, name))
+
+		if len(parts) == cap(parts) {
+			break
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestReminderConfig_Validate(t *testing.T) {
+	offsets := []time.Duration{24 * time.Hour, time.Hour}
+
+	testCases := []struct {
+		name    string
+		cfg     ReminderConfig
+		wantErr bool
+	}{
+		{
+			name: "disabled",
+			cfg:  ReminderConfig{},
+		},
+		{
+			name: "participants",
+			cfg:  ReminderConfig{Offsets: offsets, Interval: time.Minute, Participants: true},
+		},
+		{
+			name: "chat",
+			cfg:  ReminderConfig{Offsets: offsets, Interval: time.Minute, Chat: true},
+		},
+		{
+			name:    "no interval",
+			cfg:     ReminderConfig{Offsets: offsets, Participants: true},
+			wantErr: true,
+		},
+		{
+			name:    "no recipients",
+			cfg:     ReminderConfig{Offsets: offsets, Interval: time.Minute},
+			wantErr: true,
+		},
+		{
+			name:    "too small offset",
+			cfg:     ReminderConfig{Offsets: []time.Duration{time.Second}, Interval: time.Minute, Participants: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHumanDuration(t *testing.T) {
+	testCases := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 20 * time.Second, want: "less than a minute"},
+		{d: time.Minute, want: "1 minute"},
+		{d: 59*time.Minute + 40*time.Second, want: "1 hour"},
+		{d: 90 * time.Minute, want: "1 hour 30 minutes"},
+		{d: 24 * time.Hour, want: "1 day"},
+		{d: 24*time.Hour + 5*time.Minute, want: "1 day"},
+		{d: 50*time.Hour + 5*time.Minute, want: "2 days 2 hours"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, humanDuration(tt.d))
+		})
+	}
+}
+
+func TestReminderText(t *testing.T) {
+	startsAt := time.Date(2026, time.October, 20, 9, 30, 0, 0, time.UTC)
+
+	got := reminderText(&models.Trip{Name: "Morning ride", StartsAt: startsAt}, startsAt.Add(-time.Hour))
+
+	assert.Equal(t, `⏰ Reminder: "Morning ride" starts in 1 hour, on Tue, 20 Oct 2026 09:30 UTC.`, got)
+}
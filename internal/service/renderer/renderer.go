@@ -6,6 +6,8 @@
 	"embed"
 	"errors"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
 	"text/template"
 )
 
@@ -33,7 +35,7 @@ func New() (Renderer, error) {
 		errs = errors.Join(errs, err)
 	}
 
-	tripTpl, err := parseTemplate("trip", "templates/trip.gotmpl")
+	tripTpl, err := parseHTMLTemplate("trip", "templates/trip.gotmpl")
 	if err != nil {
 		errs = errors.Join(errs, err)
 	}
@@ -55,7 +57,7 @@ func New() (Renderer, error) {
 type templates struct {
 	help    *template.Template
 	welcome *template.Template
-	trip    *template.Template
+	trip    *htmltemplate.Template
 }
 
 // HelpParams is a set of parameters for Help template.
@@ -84,19 +86,36 @@ func (t *templates) Welcome(params WelcomeParams) (string, error) {
 
 // TripParams is a set of parameters for Trip template.
 type TripParams struct {
-	Title       string
-	Description string
-	Date        string
-	CreatedBy   string
+	ID           string
+	Title        string
+	Description  string
+	Date         string
+	MeetingPoint string
+	Track        string
+	RouteLink    *Link
+	Status       string
+	CreatedBy    string
+	Participants []string
 }
 
-// Trip renders a trip message.
+// Link is a labelled web link, the label is shown as the link text.
+type Link struct {
+	Label string
+	URL   string
+}
+
+// Trip renders a trip message formatted with Telegram HTML, params are escaped.
 func (t *templates) Trip(params TripParams) (string, error) {
 	return renderTemplate(t.trip, params)
 }
 
+// executor is a parsed text or HTML template.
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
 // renderTemplate renders a template.
-func renderTemplate(tmpl *template.Template, data interface{}) (string, error) {
+func renderTemplate(tmpl executor, data interface{}) (string, error) {
 	var buf bytes.Buffer
 
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -120,3 +139,18 @@ func parseTemplate(name, path string) (*template.Template, error) {
 
 	return tmpl, nil
 }
+
+// parseHTMLTemplate parses an HTML template from the templatesFS, values are escaped when it's executed.
+func parseHTMLTemplate(name, path string) (*htmltemplate.Template, error) {
+	tmplBytes, err := templatesFS.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q template file: %w", name, err)
+	}
+
+	tmpl, err := htmltemplate.New(name).Parse(string(tmplBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q template: %w", name, err)
+	}
+
+	return tmpl, nil
+}
@@ -64,10 +64,16 @@ func (s *TemplatesSuite) TestTemplates_Welcome() {
 
 func (s *TemplatesSuite) TestTemplates_Trip() {
 	params := renderer.TripParams{
-		Title:       "Title",
-		Description: "Description",
-		Date:        "Date",
-		CreatedBy:   "CreatedBy",
+		ID:           "ID",
+		Title:        "Title",
+		Description:  "Description",
+		Date:         "Date",
+		MeetingPoint: "MeetingPoint",
+		Track:        "Track",
+		RouteLink:    &renderer.Link{Label: "Label", URL: "https://example.org/route"},
+		Status:       "Status",
+		CreatedBy:    "CreatedBy",
+		Participants: []string{"@first", "Second"},
 	}
 
 	res, err := s.tpls.Trip(params)
@@ -75,3 +81,23 @@ func (s *TemplatesSuite) TestTemplates_Trip() {
 
 	s.Assert().Equal(s.loadGoldenFile("trip.golden"), res)
 }
+
+func (s *TemplatesSuite) TestTemplates_TripWithoutRouteLink() {
+	res, err := s.tpls.Trip(renderer.TripParams{})
+	s.Assert().NoError(err)
+
+	s.Assert().Contains(res, "\nRoute link: not set\n")
+}
+
+func (s *TemplatesSuite) TestTemplates_TripEscaped() {
+	res, err := s.tpls.Trip(renderer.TripParams{
+		Title:       "Tom & Jerry <ride>",
+		Description: `"Fast" group`,
+		RouteLink:   &renderer.Link{Label: "Strava", URL: "https://www.strava.com/routes/1?a=1&b=2"},
+	})
+	s.Assert().NoError(err)
+
+	s.Assert().Contains(res, "\nTitle: Tom &amp; Jerry &lt;ride&gt;\n")
+	s.Assert().Contains(res, "\nDescription: &#34;Fast&#34; group\n")
+	s.Assert().Contains(res, `<a href="https://www.strava.com/routes/1?a=1&amp;b=2">Strava</a>`)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/routelink"
+	templates "github.com/obalunenko/telegram-ride-announcer-bot/internal/service/renderer"
+)
+
+// WithRouteProviders adds route link providers to the built-in ones: Strava, Komoot, RideWithGPS and Garmin Connect.
+func WithRouteProviders(providers ...routelink.Provider) Option {
+	return func(o *options) {
+		o.routeProviders = append(o.routeProviders, providers...)
+	}
+}
+
+// newRouteLinks returns the registry of built-in route link providers and the given ones.
+func newRouteLinks(providers []routelink.Provider) *routelink.Registry {
+	r := routelink.Default()
+	r.Register(providers...)
+
+	return r
+}
+
+// routePrompt asks for a route link or a GPX file, which are both accepted on the track steps.
+func (s *Service) routePrompt() string {
+	return fmt.Sprintf("Please paste a link to the route on %s, or upload the route GPX file",
+		joinOr(s.routeLinks.Names()))
+}
+
+// joinOr joins the words into a list, e.g. "a, b or c".
+func joinOr(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+
+	return fmt.Sprintf("%s or %s", strings.Join(words[:len(words)-1], ", "), words[len(words)-1])
+}
+
+// parseRouteLink recognises the route link typed on StateNewTripTrack and StateEditTripTrack steps.
+func (s *Service) parseRouteLink(text string) (*models.RouteLink, error) {
+	l, err := s.routeLinks.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RouteLink{
+		Provider: l.Provider,
+		ID:       l.ID,
+		URL:      l.URL,
+	}, nil
+}
+
+func (s *Service) validateRouteLink(_ context.Context, _ *models.Session, text string) error {
+	_, err := s.parseRouteLink(text)
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, routelink.ErrUnknownProvider):
+		return conversation.Invalid(fmt.Sprintf("I don't recognise this route link, %s links are supported",
+			joinOr(s.routeLinks.Names())))
+	default:
+		return conversation.Invalid("This is not a link")
+	}
+}
+
+func (s *Service) storeTripRouteLink(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	link, err := s.parseRouteLink(text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse route link: %w", err)
+	}
+
+	return 0, s.updateDraftTrip(ctx, sess, ops.UpdateTripParams{
+		RouteLink: link,
+	})
+}
+
+func (s *Service) storeEditTripRouteLink(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	link, err := s.parseRouteLink(text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse route link: %w", err)
+	}
+
+	return s.applyTripEdit(ctx, sess, ops.UpdateTripParams{
+		RouteLink: link,
+	})
+}
+
+// routeLinkParams returns the route link rendered in the trip info, nil if it's not set.
+func routeLinkParams(link *models.RouteLink) *templates.Link {
+	if link == nil {
+		return nil
+	}
+
+	return &templates.Link{
+		Label: link.Provider,
+		URL:   link.URL,
+	}
+}
+
+// formatRouteLink returns the route link shown to users in plain text.
+func formatRouteLink(link *models.RouteLink) string {
+	if link == nil {
+		return "not set"
+	}
+
+	return fmt.Sprintf("%s – %s", link.Provider, link.URL)
+}
+
+func sameRouteLink(a, b *models.RouteLink) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/routelink"
+)
+
+func TestJoinOr(t *testing.T) {
+	assert.Empty(t, joinOr(nil))
+	assert.Equal(t, "Strava", joinOr([]string{"Strava"}))
+	assert.Equal(t, "Strava or Komoot", joinOr([]string{"Strava", "Komoot"}))
+	assert.Equal(t, "Strava, Komoot or RideWithGPS", joinOr([]string{"Strava", "Komoot", "RideWithGPS"}))
+}
+
+func TestService_ValidateRouteLink(t *testing.T) {
+	s := &Service{routeLinks: newRouteLinks(nil)}
+
+	testCases := []struct {
+		name       string
+		text       string
+		wantReason string
+	}{
+		{
+			name: "supported link",
+			text: "https://www.komoot.com/tour/1234567890",
+		},
+		{
+			name:       "unknown provider",
+			text:       "https://example.org/routes/1",
+			wantReason: "I don't recognise this route link, Strava, Komoot, RideWithGPS or Garmin Connect links are supported",
+		},
+		{
+			name:       "not a link",
+			text:       "scenic route along the river",
+			wantReason: "This is not a link",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.validateRouteLink(context.Background(), nil, tt.text)
+			if tt.wantReason == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			var verr *conversation.ValidationError
+
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.wantReason, verr.Reason)
+		})
+	}
+}
+
+func TestService_ParseRouteLink_CustomProvider(t *testing.T) {
+	s := &Service{routeLinks: newRouteLinks([]routelink.Provider{
+		routelink.NewProvider("Bikemap", []string{"www.bikemap.net"}, regexp.MustCompile(`^/[a-z]{2}/r/(\d+)/?$`)),
+	})}
+
+	got, err := s.parseRouteLink("www.bikemap.net/en/r/1234567/")
+	require.NoError(t, err)
+	assert.Equal(t, &models.RouteLink{
+		Provider: "Bikemap",
+		ID:       "1234567",
+		URL:      "https://www.bikemap.net/en/r/1234567/",
+	}, got)
+
+	assert.Equal(t, "Please paste a link to the route on Strava, Komoot, RideWithGPS, Garmin Connect or Bikemap, "+
+		"or upload the route GPX file", s.routePrompt())
+}
+
+func TestFormatRouteLink(t *testing.T) {
+	assert.Equal(t, "not set", formatRouteLink(nil))
+	assert.Equal(t, "Strava – https://www.strava.com/routes/1", formatRouteLink(&models.RouteLink{
+		Provider: "Strava",
+		ID:       "1",
+		URL:      "https://www.strava.com/routes/1",
+	}))
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/outbox"
+)
+
+// SendConfig configures delivery of outgoing messages within Telegram rate limits.
+type SendConfig struct {
+	// GlobalPerSecond is a maximum number of requests per second to all chats.
+	GlobalPerSecond int
+	// GroupPerMinute is a maximum number of requests per minute to a single group chat.
+	GroupPerMinute int
+	// MaxRetries is a maximum number of times a failed request is repeated.
+	MaxRetries int
+}
+
+const (
+	defaultSendGlobalPerSecond = 30
+	defaultSendGroupPerMinute  = 20
+	defaultSendMaxRetries      = 3
+)
+
+const (
+	// sendBackoff is a delay before the first repeat of a request failed with a transient error.
+	sendBackoff = time.Second
+	// sendMaxDelay is the longest delay before a repeat, handlers are not blocked for longer.
+	sendMaxDelay = time.Minute
+)
+
+// privateChatLimit keeps about one message per second in a private chat, allowing short bursts.
+var privateChatLimit = outbox.Limit{Count: 3, Period: 3 * time.Second}
+
+// WithSend configures delivery of outgoing messages.
+func WithSend(cfg SendConfig) Option {
+	return func(o *options) {
+		o.send = cfg
+	}
+}
+
+// newOutbox creates an outbox, which delivers messages within the configured limits.
+func newOutbox(cfg SendConfig) (*outbox.Outbox, error) {
+	o, err := outbox.New(outbox.Params{
+		Global:     outbox.Limit{Count: cfg.GlobalPerSecond, Period: time.Second},
+		Group:      outbox.Limit{Count: cfg.GroupPerMinute, Period: time.Minute},
+		Private:    privateChatLimit,
+		MaxRetries: cfg.MaxRetries,
+		Backoff:    sendBackoff,
+		MaxDelay:   sendMaxDelay,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid send config: %w", err)
+	}
+
+	return o, nil
+}
+
+// send delivers the message and returns it as sent.
+func (s *Service) send(ctx context.Context, msg *tgbotapi.SendMessageParams) (*tgbotapi.Message, error) {
+	return outbox.Do(ctx, s.outbox, msg.ChatID.ID, func() (*tgbotapi.Message, error) {
+		return s.bot.Client().SendMessage(msg)
+	})
+}
+
+// logSendStats logs outgoing messages metrics.
+func (s *Service) logSendStats(ctx context.Context) {
+	stats := s.outbox.Stats()
+
+	log.WithFields(ctx, log.Fields{
+		"sent":         stats.Sent,
+		"failed":       stats.Failed,
+		"retried":      stats.Retried,
+		"rate_limited": stats.RateLimited,
+	}).Info("Outgoing messages stats")
+}
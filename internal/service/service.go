@@ -5,12 +5,16 @@
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
-	tgbotapi "github.com/mymmrac/telego"
 	th "github.com/mymmrac/telego/telegohandler"
 	log "github.com/obalunenko/logger"
 
-	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/dispatch"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/outbox"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/permissions"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/routelink"
 	templates "github.com/obalunenko/telegram-ride-announcer-bot/internal/service/renderer"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/telegram"
 )
@@ -22,6 +26,12 @@
 	CmdStart = "start"
 	// CmdNewTrip is a command for creating a new trip.
 	CmdNewTrip = "newtrip"
+	// CmdEditTrip is a command for editing a published trip.
+	CmdEditTrip = "edittrip"
+	// CmdCancelTrip is a command for cancelling a published trip.
+	CmdCancelTrip = "canceltrip"
+	// CmdCancel is a command for canceling the conversation in progress.
+	CmdCancel = "cancel"
 	// CmdTrips is a command for getting trips.
 	CmdTrips = "trips"
 	// CmdSubscribe is a command for subscribing to trip.
@@ -32,101 +42,227 @@
 	CmdMyTrips = "mytrips"
 	// CmdSubscribed is a command for getting user's subscribed trips.
 	CmdSubscribed = "subscribed"
+	// CmdPolicy is a group command for getting or setting who may announce trips in the group.
+	CmdPolicy = "policy"
+	// CmdLeader is a group command for managing approved ride leaders.
+	CmdLeader = "leader"
 )
 
+// adminsCacheTTL is how long chat administrators are cached.
+const adminsCacheTTL = 5 * time.Minute
+
 // Service is a Telegram bot service.
 type Service struct {
 	bot       *telegram.Bot
 	backends  backends
 	templates templates.Renderer
+	location  *time.Location
+	webhook   *WebhookConfig
+	janitor   JanitorConfig
+	reminders ReminderConfig
+	lifecycle LifecycleConfig
+	shutdown  ShutdownConfig
+	dispatch  *dispatch.Dispatcher
+	outbox    *outbox.Outbox
+	perms     *permissions.Checker
+
+	routeLinks    *routelink.Registry
+	conversations *conversation.Engine
+
+	stopUpdates stopFunc
+	stopFns     []stopFunc
+}
+
+type options struct {
+	location  *time.Location
+	webhook   *WebhookConfig
+	janitor   JanitorConfig
+	reminders ReminderConfig
+	lifecycle LifecycleConfig
+	shutdown  ShutdownConfig
+	dispatch  DispatchConfig
+	send      SendConfig
+
+	routeProviders []routelink.Provider
+}
+
+// Option is a service option.
+type Option func(*options)
 
-	stopFns []stopFunc
+// WithLocation sets the timezone in which users enter trip dates and times.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
 }
 
 // New creates a new Service.
-func New(bot *telegram.Bot, b backends) (*Service, error) {
+func New(bot *telegram.Bot, b backends, opts ...Option) (*Service, error) {
 	if bot == nil {
 		return nil, errors.New("bot is nil")
 	}
 
+	params := options{
+		location: time.UTC,
+		lifecycle: LifecycleConfig{
+			FinishAfter:  defaultFinishAfter,
+			ArchiveAfter: defaultArchiveAfter,
+		},
+		shutdown: ShutdownConfig{
+			DrainTimeout: defaultDrainTimeout,
+		},
+		dispatch: DispatchConfig{
+			Workers:   defaultDispatchWorkers,
+			QueueSize: defaultDispatchQueueSize,
+		},
+		send: SendConfig{
+			GlobalPerSecond: defaultSendGlobalPerSecond,
+			GroupPerMinute:  defaultSendGroupPerMinute,
+			MaxRetries:      defaultSendMaxRetries,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	if params.location == nil {
+		return nil, errors.New("location is nil")
+	}
+
+	if params.webhook != nil {
+		if err := params.webhook.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := params.janitor.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := params.reminders.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := params.lifecycle.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := params.shutdown.validate(); err != nil {
+		return nil, err
+	}
+
+	dispatcher, err := newDispatcher(params.dispatch)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := newOutbox(params.send)
+	if err != nil {
+		return nil, err
+	}
+
 	tpls, err := templates.New()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load templates: %w", err)
 	}
 
-	return &Service{
+	s := &Service{
 		bot:       bot,
 		backends:  b,
 		templates: tpls,
+		location:  params.location,
+		webhook:   params.webhook,
+		janitor:   params.janitor,
+		reminders: params.reminders,
+		lifecycle: params.lifecycle,
+		shutdown:  params.shutdown,
+		dispatch:  dispatcher,
+		outbox:    sender,
+		perms:     permissions.NewChecker(bot.Client(), adminsCacheTTL),
 		stopFns:   nil,
-	}, nil
-}
-
-// Start is a helper function that will be called when the program starts.
-func (s *Service) Start(ctx context.Context) {
-	log.WithField(ctx, "Username", s.bot.Username()).Info("Authorized on account")
 
-	s.stopFns = append(s.stopFns, s.initHandlers(ctx))
-}
+		routeLinks: newRouteLinks(params.routeProviders),
+	}
 
-// Shutdown is a helper function that will be called when the program receives an interrupt signal.
-// It will gracefully shut down the bot by waiting for all requests to be processed before shutting down.
-func (s *Service) Shutdown(ctx context.Context) {
-	list, err := ops.ListSessions(ctx, s.backends)
+	s.conversations, err = s.newConversations()
 	if err != nil {
-		log.WithError(ctx, err).Error("Failed to get sessions")
-
-		return
+		return nil, fmt.Errorf("failed to register conversations: %w", err)
 	}
 
-	for _, sess := range list {
-		msg := fmt.Sprintf("I'm going to sleep. Bye, %s!", sess.User.Firstname)
+	return s, nil
+}
 
-		s.sendMessage(contextWithSession(ctx, sess), msg)
+// now returns current time in the service location.
+func (s *Service) now() time.Time {
+	return time.Now().In(s.location)
+}
 
-		if err = ops.DeleteSession(ctx, s.backends, sess); err != nil {
-			log.WithError(ctx, err).WithField("user_id", sess.User.ID).Warn("Failed to delete session")
+// Start is a helper function that will be called when the program starts.
+// It returns an error if updates can't be received, e.g. the webhook address is in use.
+func (s *Service) Start(ctx context.Context) error {
+	log.WithField(ctx, "Username", s.bot.Username()).Info("Authorized on account")
 
-			continue
-		}
+	stopHandlers, err := s.initHandlers(ctx)
+	if err != nil {
+		return err
 	}
 
-	log.Info(ctx, "Stop receiving updates")
-	s.bot.Client().StopLongPolling()
+	s.stopFns = append(s.stopFns,
+		stopHandlers, s.startJanitor(ctx), s.startLifecycle(ctx), s.startReminders(ctx))
 
-	for _, fn := range s.stopFns {
-		fn(ctx)
-	}
+	return nil
 }
 
 type stopFunc func(ctx context.Context)
 
-func (s *Service) initHandlers(ctx context.Context) stopFunc {
-	pollChan, err := s.bot.Client().UpdatesViaLongPolling(&tgbotapi.GetUpdatesParams{},
-		tgbotapi.WithLongPollingContext(ctx))
+func (s *Service) initHandlers(ctx context.Context) (stopFunc, error) {
+	updates, stopUpdates, err := s.receiveUpdates(ctx)
 	if err != nil {
-		log.WithError(ctx, err).Fatal("Failed to get updates via long polling")
+		return nil, fmt.Errorf("failed to receive updates: %w", err)
 	}
 
-	handler, err := th.NewBotHandler(s.bot.Client(), pollChan)
+	s.stopUpdates = stopUpdates
+
+	// Sequencing is stopped only after the handler, so updates already received are not lost.
+	seqCtx, stopSequence := context.WithCancel(context.WithoutCancel(ctx))
+
+	handler, err := th.NewBotHandler(s.bot.Client(), s.dispatch.Sequence(seqCtx, updates))
 	if err != nil {
-		log.WithError(ctx, err).Fatal("Failed to create bot handler")
+		stopSequence()
+		stopUpdates(ctx)
+
+		return nil, fmt.Errorf("failed to create bot handler: %w", err)
 	}
 
+	// Dispatcher goes first, so updates of the same user are never handled concurrently.
+	handler.Use(s.dispatch.Middleware())
 	handler.Use(s.panicRecovery())
-	handler.Use(s.setContextMiddleware(ctx))
+	// Handlers must not be interrupted by the shutdown signal, so in-flight updates are drained gracefully.
+	handler.Use(s.setContextMiddleware(context.WithoutCancel(ctx)))
 	handler.Use(s.setSessionMiddleware())
 	handler.Use(s.loggerMiddleware())
 
 	handler.Handle(s.helpHandler(), th.CommandEqual(CmdHelp))
 	handler.Handle(s.startHandler(ctx), th.CommandEqual(CmdStart))
 	handler.Handle(s.newTripHandler(), th.CommandEqual(CmdNewTrip))
+	handler.Handle(s.editTripHandler(), th.CommandEqual(CmdEditTrip))
+	handler.Handle(s.cancelTripHandler(), th.CommandEqual(CmdCancelTrip))
+	handler.Handle(s.cancelHandler(), th.CommandEqual(CmdCancel))
 	handler.Handle(s.tripsHandler(), th.CommandEqual(CmdTrips))
 	handler.Handle(s.subscribeHandler(), th.CommandEqual(CmdSubscribe))
 	handler.Handle(s.unsubscribeHandler(), th.CommandEqual(CmdUnsubscribe))
 	handler.Handle(s.myTripsHandler(), th.CommandEqual(CmdMyTrips))
 	handler.Handle(s.subscribedHandler(), th.CommandEqual(CmdSubscribed))
+	handler.Handle(s.policyHandler(), th.CommandEqual(CmdPolicy))
+	handler.Handle(s.leaderHandler(), th.CommandEqual(CmdLeader))
+	handler.Handle(s.tripsCallbackHandler(), th.CallbackDataPrefix(callbackTripsPrefix))
+	handler.Handle(s.announcementCallbackHandler(), th.CallbackDataPrefix(callbackAnnouncePrefix))
+	handler.Handle(s.publishCallbackHandler(), th.CallbackDataPrefix(callbackPublishPrefix))
+	handler.Handle(s.myChatMemberHandler(), th.AnyMyChatMember())
 	handler.Handle(s.notFoundHandler(ctx), th.AnyCommand())
+	handler.Handle(s.locationHandler(), anyLocation())
+	handler.Handle(s.documentHandler(), anyDocument())
 	handler.Handle(s.textHandler(), th.AnyMessageWithText())
 
 	go handler.Start()
@@ -134,8 +270,43 @@ func (s *Service) initHandlers(ctx context.Context) stopFunc {
 	return func(ctx context.Context) {
 		log.Info(ctx, "Stopping bot handler")
 
-		handler.Stop()
+		// Handler may already be stopping by itself when updates channel is closed,
+		// then StopWithContext waits for it without a deadline.
+		stopped := make(chan struct{})
+
+		go func() {
+			handler.StopWithContext(ctx)
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			// Drain deadline expired, updates still waiting for their turn are dropped.
+			s.dispatch.Abort()
+		}
+
+		stopSequence()
+		// Updates the bot handler skipped after stop still hold their turns.
+		s.dispatch.Release()
+
+		stats := s.dispatch.Stats()
+
+		log.WithFields(ctx, log.Fields{
+			"processed": stats.Processed,
+			"dropped":   stats.Dropped,
+			"queued":    stats.Queued,
+			"running":   stats.Running,
+			"throttled": stats.Throttled,
+			"max_wait":  stats.MaxWait.String(),
+		}).Info("Updates dispatch stats")
+
+		if ctx.Err() != nil {
+			log.WithError(ctx, context.Cause(ctx)).Warn("Bot handler stopped before all updates were handled")
+
+			return
+		}
 
 		log.Info(ctx, "Bot handler stopped")
-	}
+	}, nil
 }
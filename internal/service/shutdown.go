@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+// defaultDrainTimeout is how long in-flight updates are waited for on shutdown by default.
+const defaultDrainTimeout = 10 * time.Second
+
+// ShutdownConfig configures graceful shutdown.
+type ShutdownConfig struct {
+	// DrainTimeout is how long in-flight updates are waited for before the bot stops.
+	DrainTimeout time.Duration
+	// Announcement is sent to private chats of all users on shutdown, e.g. about planned maintenance.
+	// Nothing is sent when it's empty.
+	Announcement string
+}
+
+func (c ShutdownConfig) validate() error {
+	if c.DrainTimeout <= 0 {
+		return errors.New("shutdown: drain timeout must be positive")
+	}
+
+	return nil
+}
+
+// WithShutdown configures graceful shutdown.
+func WithShutdown(cfg ShutdownConfig) Option {
+	return func(o *options) {
+		o.shutdown = cfg
+	}
+}
+
+// Shutdown gracefully stops the bot. New updates are not received anymore and in-flight updates
+// are handled until the drain timeout expires, then background workers are stopped.
+// Sessions are kept, so conversations in progress are continued after restart.
+//
+// The passed context is usually canceled already by the shutdown signal, so it's used only for its values.
+func (s *Service) Shutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeoutCause(context.WithoutCancel(ctx), s.shutdown.DrainTimeout,
+		errors.New("shutdown: drain timeout exceeded"))
+	defer cancel()
+
+	if s.stopUpdates != nil {
+		s.stopUpdates(ctx)
+	}
+
+	for _, fn := range s.stopFns {
+		fn(ctx)
+	}
+
+	if s.shutdown.Announcement != "" {
+		s.announce(context.WithoutCancel(ctx), s.shutdown.Announcement)
+	}
+
+	s.logSendStats(ctx)
+}
+
+// announce sends the message to private chats of all users with a session.
+func (s *Service) announce(ctx context.Context, text string) {
+	list, err := ops.ListSessions(ctx, s.backends)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to list sessions for announcement")
+
+		return
+	}
+
+	chats := announcementChats(list)
+
+	for _, chatID := range chats {
+		s.sendMessageTo(ctx, chatID, text)
+	}
+
+	log.WithField(ctx, "chats", len(chats)).Info("Announcement sent")
+}
+
+// announcementChats returns unique private chats of the sessions, group chats are skipped.
+func announcementChats(list []*models.Session) []int64 {
+	seen := make(map[int64]struct{}, len(list))
+	chats := make([]int64, 0, len(list))
+
+	for _, sess := range list {
+		// Private chat ID is the same as the user ID.
+		if sess.ChatID != sess.User.ID {
+			continue
+		}
+
+		if _, ok := seen[sess.ChatID]; ok {
+			continue
+		}
+
+		seen[sess.ChatID] = struct{}{}
+
+		chats = append(chats, sess.ChatID)
+	}
+
+	return chats
+}
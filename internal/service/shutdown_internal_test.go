@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestShutdownConfig_Validate(t *testing.T) {
+	assert.NoError(t, ShutdownConfig{DrainTimeout: time.Second}.validate())
+	assert.NoError(t, ShutdownConfig{DrainTimeout: time.Second, Announcement: "Maintenance"}.validate())
+	assert.Error(t, ShutdownConfig{}.validate())
+	assert.Error(t, ShutdownConfig{DrainTimeout: -time.Second}.validate())
+}
+
+func TestAnnouncementChats(t *testing.T) {
+	session := func(userID, chatID int64) *models.Session {
+		return &models.Session{User: &models.User{ID: userID}, ChatID: chatID}
+	}
+
+	list := []*models.Session{
+		session(1, 1),
+		session(2, -100),
+		session(3, 3),
+		session(1, 1),
+	}
+
+	assert.Equal(t, []int64{1, 3}, announcementChats(list))
+	assert.Empty(t, announcementChats(nil))
+}
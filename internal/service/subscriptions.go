@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+func (s *Service) subscribeHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdSubscribe))
+
+		log.Debug(ctx, "Called subscribe handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		ref := commandArg(update)
+		if ref == "" {
+			s.listJoinableTrips(ctx, sess)
+
+			return
+		}
+
+		trip, err := ops.FindTrip(ctx, s.backends, ref)
+		if err != nil {
+			s.sendTripRefError(ctx, ref, err)
+
+			return
+		}
+
+		joined, err := ops.JoinTrip(ctx, s.backends, trip.ID, sess.User.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, ops.ErrTripNotPublished):
+				s.sendMessage(ctx, notJoinableText(trip.Status))
+			case errors.Is(err, ops.ErrAlreadyJoined):
+				s.sendMessage(ctx, fmt.Sprintf("You have already joined this trip. Use /%s command to see your trips.", CmdSubscribed))
+			default:
+				log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to join trip")
+			}
+
+			return
+		}
+
+		tripfmt, err := s.renderTrip(joined)
+		if err != nil {
+			log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to render trip")
+
+			return
+		}
+
+		s.sendHTML(ctx, fmt.Sprintf("You joined the trip!\n\n%s", tripfmt))
+	}
+}
+
+func (s *Service) unsubscribeHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdUnsubscribe))
+
+		log.Debug(ctx, "Called unsubscribe handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		ref := commandArg(update)
+		if ref == "" {
+			s.listLeavableTrips(ctx, sess)
+
+			return
+		}
+
+		trip, err := ops.FindTrip(ctx, s.backends, ref)
+		if err != nil {
+			s.sendTripRefError(ctx, ref, err)
+
+			return
+		}
+
+		if _, err = ops.LeaveTrip(ctx, s.backends, trip.ID, sess.User.ID); err != nil {
+			switch {
+			case errors.Is(err, ops.ErrNotJoined):
+				s.sendMessage(ctx, "You haven't joined this trip.")
+			case errors.Is(err, ops.ErrTripNotPublished):
+				s.sendMessage(ctx, notJoinableText(trip.Status))
+			default:
+				log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to leave trip")
+			}
+
+			return
+		}
+
+		s.sendMessage(ctx, fmt.Sprintf("You left the trip %q.", trip.Name))
+	}
+}
+
+func (s *Service) subscribedHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdSubscribed))
+
+		log.Debug(ctx, "Called subscribed handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		list, err := ops.ListJoinedTrips(ctx, s.backends, sess.User.ID)
+		if err != nil {
+			log.WithError(ctx, err).WithField("user_id", sess.User.ID).Error("Failed to list joined trips")
+
+			return
+		}
+
+		if len(list) == 0 {
+			msg := fmt.Sprintf("You haven't joined any trips yet. Use /%s command to join a trip.", CmdSubscribe)
+
+			s.sendMessage(ctx, msg)
+
+			return
+		}
+
+		msgtxt := "Trips you are going to:\n\n"
+
+		for i := range list {
+			if i > 0 {
+				msgtxt += "\n"
+			}
+
+			trip := list[i]
+
+			tripfmt, err := s.renderTrip(trip)
+			if err != nil {
+				log.WithError(ctx, err).WithField("trip_id", trip.ID).Error("Failed to render trip")
+
+				return
+			}
+
+			msgtxt += tripfmt
+		}
+
+		s.sendHTML(ctx, msgtxt)
+	}
+}
+
+// listJoinableTrips sends a list of published trips the user can join.
+func (s *Service) listJoinableTrips(ctx context.Context, sess *models.Session) {
+	list, err := ops.ListPublishedTrips(ctx, s.backends)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to list published trips")
+
+		return
+	}
+
+	joinable := make([]*models.Trip, 0, len(list))
+
+	for _, t := range list {
+		if !t.HasParticipant(sess.User.ID) {
+			joinable = append(joinable, t)
+		}
+	}
+
+	if len(joinable) == 0 {
+		s.sendMessage(ctx, "There are no trips to join.")
+
+		return
+	}
+
+	msg := fmt.Sprintf("Trips you can join:\n\n%s\nUse /%s <ID> command to join a trip.",
+		formatTripRefs(joinable), CmdSubscribe)
+
+	s.sendMessage(ctx, msg)
+}
+
+// listLeavableTrips sends a list of trips the user has joined.
+func (s *Service) listLeavableTrips(ctx context.Context, sess *models.Session) {
+	list, err := ops.ListJoinedTrips(ctx, s.backends, sess.User.ID)
+	if err != nil {
+		log.WithError(ctx, err).WithField("user_id", sess.User.ID).Error("Failed to list joined trips")
+
+		return
+	}
+
+	if len(list) == 0 {
+		s.sendMessage(ctx, "You haven't joined any trips yet.")
+
+		return
+	}
+
+	msg := fmt.Sprintf("Trips you have joined:\n\n%s\nUse /%s <ID> command to leave a trip.",
+		formatTripRefs(list), CmdUnsubscribe)
+
+	s.sendMessage(ctx, msg)
+}
+
+func (s *Service) sendTripRefError(ctx context.Context, ref string, err error) {
+	switch {
+	case errors.Is(err, ops.ErrTripNotFound):
+		s.sendMessage(ctx, fmt.Sprintf("Trip %q not found.", ref))
+	case errors.Is(err, ops.ErrAmbiguousTripRef):
+		s.sendMessage(ctx, fmt.Sprintf("Several trips match %q, please use the full trip ID.", ref))
+	default:
+		log.WithError(ctx, err).WithField("trip_ref", ref).Error("Failed to find trip")
+	}
+}
+
+// formatTripRefs formats trips as a list of short IDs with names and dates.
+func formatTripRefs(list []*models.Trip) string {
+	var sb strings.Builder
+
+	for _, t := range list {
+		sb.WriteString(fmt.Sprintf("%s - %s (%s)\n", t.ShortID(), t.Name, formatTripDate(t.StartsAt)))
+	}
+
+	return sb.String()
+}
+
+// commandArg returns the command argument of the update message, empty if there is none.
+func commandArg(update tgbotapi.Update) string {
+	if update.Message == nil {
+		return ""
+	}
+
+	_, _, args := tu.ParseCommand(update.Message.Text)
+
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+)
+
+// command returns an update with the command message the session user sent.
+func command(ctx context.Context, sess *models.Session, text string) tgbotapi.Update {
+	update := tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: sess.User.ID, FirstName: sess.User.Firstname},
+			Chat: tgbotapi.Chat{ID: sess.ChatID, Type: tgbotapi.ChatTypePrivate},
+			Text: text,
+		},
+	}
+
+	return update.WithContext(contextWithSession(ctx, sess))
+}
+
+func TestService_SubscriptionCommands(t *testing.T) {
+	ctx := context.Background()
+
+	const (
+		creatorID int64 = 1
+		riderID   int64 = 2
+	)
+
+	b := opstest.NewBackends(t)
+	s, api := newTestService(t, b)
+
+	opstest.NewUser(t, b, creatorID)
+	sess := opstest.NewSession(t, b, riderID)
+
+	startsAt := time.Now().Add(24 * time.Hour)
+
+	published := opstest.NewTrip(t, b, creatorID, startsAt, models.TripPublished).ShortID()
+	draft := opstest.NewTrip(t, b, creatorID, startsAt).ShortID()
+	cancelled := opstest.NewTrip(t, b, creatorID, startsAt, models.TripPublished, models.TripCancelled).ShortID()
+
+	testCases := []struct {
+		name    string
+		handler th.Handler
+		text    string
+		want    string
+		notWant string
+	}{
+		{
+			name:    "nothing joined",
+			handler: s.subscribedHandler(),
+			text:    "/subscribed",
+			want:    "You haven't joined any trips yet. Use /subscribe command to join a trip.",
+		},
+		{
+			name:    "nothing to leave",
+			handler: s.unsubscribeHandler(),
+			text:    "/unsubscribe",
+			want:    "You haven't joined any trips yet.",
+		},
+		{
+			name:    "list joinable",
+			handler: s.subscribeHandler(),
+			text:    "/subscribe",
+			want:    "Trips you can join:\n\n" + published + " - Morning ride",
+			notWant: draft,
+		},
+		{
+			name:    "leave not joined",
+			handler: s.unsubscribeHandler(),
+			text:    "/unsubscribe " + published,
+			want:    "You haven't joined this trip.",
+		},
+		{
+			name:    "subscribe",
+			handler: s.subscribeHandler(),
+			text:    "/subscribe " + published,
+			want:    "You joined the trip!",
+		},
+		{
+			name:    "subscribe twice",
+			handler: s.subscribeHandler(),
+			text:    "/subscribe " + published,
+			want:    "You have already joined this trip. Use /subscribed command to see your trips.",
+		},
+		{
+			name:    "nothing else to join",
+			handler: s.subscribeHandler(),
+			text:    "/subscribe",
+			want:    "There are no trips to join.",
+		},
+		{
+			name:    "subscribe draft",
+			handler: s.subscribeHandler(),
+			text:    "/subscribe " + draft,
+			want:    "This trip is not published yet.",
+		},
+		{
+			name:    "subscribe cancelled",
+			handler: s.subscribeHandler(),
+			text:    "/subscribe " + cancelled,
+			want:    "This trip is cancelled.",
+		},
+		{
+			name:    "subscribe unknown",
+			handler: s.subscribeHandler(),
+			text:    "/subscribe nope",
+			want:    `Trip "nope" not found.`,
+		},
+		{
+			name:    "list subscribed",
+			handler: s.subscribedHandler(),
+			text:    "/subscribed",
+			want:    "Trips you are going to:",
+		},
+		{
+			name:    "list leavable",
+			handler: s.unsubscribeHandler(),
+			text:    "/unsubscribe",
+			want:    "Trips you have joined:\n\n" + published + " - Morning ride",
+		},
+		{
+			name:    "unsubscribe cancelled",
+			handler: s.unsubscribeHandler(),
+			text:    "/unsubscribe " + cancelled,
+			want:    "This trip is cancelled.",
+		},
+		{
+			name:    "unsubscribe",
+			handler: s.unsubscribeHandler(),
+			text:    "/unsubscribe " + published,
+			want:    `You left the trip "Morning ride".`,
+		},
+		{
+			name:    "unsubscribe twice",
+			handler: s.unsubscribeHandler(),
+			text:    "/unsubscribe " + published,
+			want:    "You haven't joined this trip.",
+		},
+	}
+
+	// Cases run in order, each one continues from the state the previous left.
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.handler(nil, command(ctx, sess, tt.text))
+
+			sent := api.sent()
+			require.Len(t, sent, 1)
+			assert.Equal(t, riderID, sent[0].ChatID)
+			assert.Contains(t, sent[0].Text, tt.want)
+
+			if tt.notWant != "" {
+				assert.NotContains(t, sent[0].Text, tt.notWant)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/gpx"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+)
+
+// maxTrackFileSize is a maximum size of the uploaded GPX file in bytes.
+const maxTrackFileSize = 10 << 20
+
+const gpxMimeType = "application/gpx+xml"
+
+// checkTrackDocument returns the reason the document is not accepted as a track, empty if it's fine.
+// Telegram clients often send GPX files as application/octet-stream, so the file extension is checked too.
+func checkTrackDocument(doc *tgbotapi.Document) string {
+	if doc.MimeType != gpxMimeType && !strings.EqualFold(path.Ext(doc.FileName), ".gpx") {
+		return "Please upload a GPX file"
+	}
+
+	if doc.FileSize > maxTrackFileSize {
+		return fmt.Sprintf("The file is too big, the limit is %d MB", maxTrackFileSize>>20)
+	}
+
+	return ""
+}
+
+// newTrack returns the track of the uploaded document with the stats parsed from it.
+func newTrack(doc *tgbotapi.Document, stats *gpx.Stats) *models.Track {
+	return &models.Track{
+		FileID:        doc.FileID,
+		FileName:      doc.FileName,
+		Distance:      stats.Distance,
+		HasElevation:  stats.HasElevation,
+		ElevationGain: stats.ElevationGain,
+		ElevationLoss: stats.ElevationLoss,
+		MaxGradient:   stats.MaxGradient,
+		MinLatitude:   stats.Bounds.MinLatitude,
+		MinLongitude:  stats.Bounds.MinLongitude,
+		MaxLatitude:   stats.Bounds.MaxLatitude,
+		MaxLongitude:  stats.Bounds.MaxLongitude,
+	}
+}
+
+// formatTrack returns the track stats shown to users, e.g. "42.2 km, ↗ 512 m, ↘ 498 m, max gradient 9.8%".
+func formatTrack(track *models.Track) string {
+	if track == nil {
+		return "not set"
+	}
+
+	text := fmt.Sprintf("%.1f km", track.Distance/1000)
+
+	if track.HasElevation {
+		text += fmt.Sprintf(", ↗ %.0f m, ↘ %.0f m, max gradient %.1f%%",
+			track.ElevationGain, track.ElevationLoss, track.MaxGradient)
+	}
+
+	return text
+}
+
+func sameTrack(a, b *models.Track) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// documentHandler handles uploaded files, which are accepted as the trip track.
+func (s *Service) documentHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", "document"))
+
+		log.Debug(ctx, "Called document handler")
+
+		sess := sessionFromContext(ctx)
+		if sess == nil {
+			log.Error(ctx, "Session is nil")
+
+			return
+		}
+
+		_, ok := s.conversations.Active(sess)
+		if !ok || !sess.UserState.State.IsAny(models.StateNewTripTrack, models.StateEditTripTrack) {
+			s.sendMessage(ctx, "I don't need a file right now.")
+
+			return
+		}
+
+		s.runConversation(ctx, sess, func(ctx context.Context) error {
+			return s.storeTrackDocument(ctx, sess, update.Message.Document)
+		})
+	}
+}
+
+// storeTrackDocument downloads and parses the uploaded GPX file and stores the track of the trip
+// being created or edited. Files which are not valid GPX are rejected and the step is asked again.
+func (s *Service) storeTrackDocument(ctx context.Context, sess *models.Session, doc *tgbotapi.Document) error {
+	ctx = log.ContextWithLogger(ctx, log.WithFields(ctx, log.Fields{
+		"file_name": doc.FileName,
+		"file_size": doc.FileSize,
+	}))
+
+	if reason := checkTrackDocument(doc); reason != "" {
+		return s.conversations.Reject(ctx, sess, reason)
+	}
+
+	data, err := s.downloadFile(doc.FileID)
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to download track file")
+
+		return s.conversations.Reject(ctx, sess, "I couldn't download the file, please try again")
+	}
+
+	stats, err := gpx.Parse(bytes.NewReader(data))
+	if err != nil {
+		log.WithError(ctx, err).Info("Failed to parse track file")
+
+		reason := "I can't read this GPX file"
+		if errors.Is(err, gpx.ErrNoPoints) {
+			reason = "This GPX file has no track or route"
+		}
+
+		return s.conversations.Reject(ctx, sess, reason)
+	}
+
+	params := ops.UpdateTripParams{
+		Track: newTrack(doc, stats),
+	}
+
+	var next models.State
+
+	if sess.UserState.State == models.StateEditTripTrack {
+		next, err = s.applyTripEdit(ctx, sess, params)
+	} else {
+		err = s.updateDraftTrip(ctx, sess, params)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.conversations.Advance(ctx, sess, next)
+}
+
+// downloadFile downloads the file sent to the bot.
+func (s *Service) downloadFile(fileID string) ([]byte, error) {
+	file, err := s.bot.Client().GetFile(&tgbotapi.GetFileParams{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	data, err := tu.DownloadFile(s.bot.Client().FileDownloadURL(file.FilePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	return data, nil
+}
+
+// anyDocument matches messages with an uploaded file.
+func anyDocument() th.Predicate {
+	return func(update tgbotapi.Update) bool {
+		return update.Message != nil && update.Message.Document != nil
+	}
+}
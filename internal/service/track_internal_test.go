@@ -0,0 +1,112 @@
+package service
+
+import (
+	"testing"
+
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/gpx"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestCheckTrackDocument(t *testing.T) {
+	testCases := []struct {
+		name string
+		doc  tgbotapi.Document
+		want string
+	}{
+		{
+			name: "gpx mime type",
+			doc:  tgbotapi.Document{FileName: "ride", MimeType: gpxMimeType, FileSize: 1024},
+		},
+		{
+			name: "gpx extension",
+			doc:  tgbotapi.Document{FileName: "Morning Ride.GPX", MimeType: "application/octet-stream", FileSize: 1024},
+		},
+		{
+			name: "not gpx",
+			doc:  tgbotapi.Document{FileName: "ride.fit", MimeType: "application/octet-stream", FileSize: 1024},
+			want: "Please upload a GPX file",
+		},
+		{
+			name: "too big",
+			doc:  tgbotapi.Document{FileName: "ride.gpx", FileSize: maxTrackFileSize + 1},
+			want: "The file is too big, the limit is 10 MB",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checkTrackDocument(&tt.doc))
+		})
+	}
+}
+
+func TestNewTrack(t *testing.T) {
+	doc := &tgbotapi.Document{FileID: "file-id", FileName: "ride.gpx"}
+
+	stats := &gpx.Stats{
+		Distance:      42195,
+		HasElevation:  true,
+		ElevationGain: 512,
+		ElevationLoss: 498,
+		MaxGradient:   9.8,
+		Bounds: gpx.Bounds{
+			MinLatitude:  50.4,
+			MinLongitude: 30.4,
+			MaxLatitude:  50.6,
+			MaxLongitude: 30.7,
+		},
+	}
+
+	assert.Equal(t, &models.Track{
+		FileID:        "file-id",
+		FileName:      "ride.gpx",
+		Distance:      42195,
+		HasElevation:  true,
+		ElevationGain: 512,
+		ElevationLoss: 498,
+		MaxGradient:   9.8,
+		MinLatitude:   50.4,
+		MinLongitude:  30.4,
+		MaxLatitude:   50.6,
+		MaxLongitude:  30.7,
+	}, newTrack(doc, stats))
+}
+
+func TestFormatTrack(t *testing.T) {
+	testCases := []struct {
+		name  string
+		track *models.Track
+		want  string
+	}{
+		{
+			name: "not set",
+			want: "not set",
+		},
+		{
+			name:  "without elevation",
+			track: &models.Track{FileID: "file-id", Distance: 42195},
+			want:  "42.2 km",
+		},
+		{
+			name: "with elevation",
+			track: &models.Track{
+				FileID:        "file-id",
+				Distance:      85340,
+				HasElevation:  true,
+				ElevationGain: 1204.4,
+				ElevationLoss: 1180.6,
+				MaxGradient:   12.46,
+			},
+			want: "85.3 km, ↗ 1204 m, ↘ 1181 m, max gradient 12.5%",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatTrack(tt.track))
+		})
+	}
+}
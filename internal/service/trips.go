@@ -1,11 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	tgbotapi "github.com/mymmrac/telego"
 	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
 	log "github.com/obalunenko/logger"
 
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
 	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
 )
 
 func (s *Service) newTripHandler() th.Handler {
@@ -23,28 +32,284 @@ func (s *Service) newTripHandler() th.Handler {
 			return
 		}
 
-		tripStates := []models.State{
-			models.StateNewTrip,
-			models.StateNewTripName,
-			models.StateNewTripDate,
-			models.StateNewTripTime,
-			models.StateNewTripDescription,
-			models.StateNewTripConfirm,
-		}
+		s.runConversation(ctx, sess, func(ctx context.Context) error {
+			// Trip creation is in progress, ask the current step again.
+			if c, ok := s.conversations.Active(sess); ok && c.Command == CmdNewTrip {
+				return s.conversations.Ask(ctx, sess)
+			}
 
-		if !sess.UserState.State.IsAny(tripStates...) {
-			sess.UserState.State = models.StateNewTrip
 			sess.UserState.Trip = nil
 
-			if err := s.saveSession(ctx, sess); err != nil {
-				log.WithError(ctx, err).Error("Failed to save session")
+			return s.conversations.Start(ctx, sess, CmdNewTrip)
+		})
+	}
+}
+
+// newTripConversation describes the trip creation wizard.
+//
+// TODO: Ask for an optional photo.
+func (s *Service) newTripConversation() *conversation.Conversation {
+	return &conversation.Conversation{
+		Command: CmdNewTrip,
+		Begin:   s.beginNewTrip,
+		Cancel:  s.cancelNewTrip,
+		Steps: []conversation.Step{
+			{
+				State:    models.StateNewTripName,
+				Prompt:   staticPrompt("Please enter trip name", nil),
+				Validate: s.validateTripName,
+				Store:    s.storeTripName,
+				Next:     models.StateNewTripDate,
+			},
+			{
+				State:    models.StateNewTripDate,
+				Prompt:   staticPrompt(tripDatePrompt, tripDateKeyboard()),
+				Validate: s.validateTripDate,
+				Store:    s.storeTripDate,
+				Next:     models.StateNewTripTime,
+				Back:     models.StateNewTripName,
+			},
+			{
+				State:    models.StateNewTripTime,
+				Prompt:   staticPrompt(tripTimePrompt, tripTimeKeyboard()),
+				Validate: s.validateTripTime,
+				Store:    s.storeTripTime,
+				Next:     models.StateNewTripDescription,
+				Back:     models.StateNewTripDate,
+			},
+			{
+				State:    models.StateNewTripDescription,
+				Prompt:   staticPrompt("Please enter trip description", nil),
+				Validate: s.validateTripDescription,
+				Store:    s.storeTripDescription,
+				Next:     models.StateNewTripMeetingPoint,
+				Back:     models.StateNewTripTime,
+				Optional: true,
+			},
+			{
+				State:    models.StateNewTripMeetingPoint,
+				Prompt:   staticPrompt(meetingPointPrompt, meetingPointKeyboard()),
+				Validate: s.validateMeetingPoint,
+				Store:    s.storeTripMeetingPoint,
+				Next:     models.StateNewTripTrack,
+				Back:     models.StateNewTripDescription,
+				Optional: true,
+			},
+			{
+				// Route link is typed, GPX file is uploaded as a document, see documentHandler.
+				State:    models.StateNewTripTrack,
+				Prompt:   staticPrompt(s.routePrompt(), nil),
+				Validate: s.validateRouteLink,
+				Store:    s.storeTripRouteLink,
+				Next:     models.StateNewTripConfirm,
+				Back:     models.StateNewTripMeetingPoint,
+				Optional: true,
+			},
+			{
+				State:    models.StateNewTripConfirm,
+				Prompt:   s.confirmTripPrompt,
+				Validate: s.validateTripConfirm,
+				Store:    s.storeTripConfirm,
+				Next:     models.StateNewTripPublish,
+				Back:     models.StateNewTripTrack,
+			},
+			{
+				// Publish chat is chosen with inline buttons, see publishCallbackHandler.
+				State:  models.StateNewTripPublish,
+				Prompt: s.publishChatPrompt,
+				Back:   models.StateNewTripConfirm,
+			},
+		},
+	}
+}
 
-				return
-			}
+// beginNewTrip creates a draft trip, which is filled in on the following steps.
+func (s *Service) beginNewTrip(ctx context.Context, sess *models.Session) error {
+	if sess.UserState.Trip != nil {
+		return nil
+	}
+
+	t, err := ops.CreateTrip(ctx, s.backends, ops.CreateTripParams{
+		Name:        "",
+		Description: "",
+		StartsAt:    time.Time{}.In(s.location),
+		CreatedBy:   sess.User.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create trip: %w", err)
+	}
+
+	sess.UserState.Trip = t
+
+	return nil
+}
+
+// cancelNewTrip deletes the draft trip. Published trips are never kept in the session, so it's always a draft.
+func (s *Service) cancelNewTrip(ctx context.Context, sess *models.Session) error {
+	if sess.UserState.Trip == nil {
+		return nil
+	}
+
+	if err := ops.DeleteTrip(ctx, s.backends, sess.UserState.Trip.ID); err != nil {
+		return fmt.Errorf("failed to delete trip: %w", err)
+	}
+
+	sess.UserState.Trip = nil
+
+	return nil
+}
+
+func (s *Service) storeTripName(ctx context.Context, sess *models.Session, name string) (models.State, error) {
+	name = strings.TrimSpace(name)
+
+	return 0, s.updateDraftTrip(ctx, sess, ops.UpdateTripParams{
+		Name: &name,
+	})
+}
+
+func (s *Service) storeTripDescription(ctx context.Context, sess *models.Session, description string) (models.State, error) {
+	description = strings.TrimSpace(description)
+
+	return 0, s.updateDraftTrip(ctx, sess, ops.UpdateTripParams{
+		Description: &description,
+	})
+}
+
+func (s *Service) confirmTripPrompt(_ context.Context, sess *models.Session) (*conversation.Prompt, error) {
+	trip := sess.UserState.Trip
+
+	if sess.User.ID != trip.CreatedBy.ID {
+		return nil, fmt.Errorf("user %d is not the creator of the trip %s", sess.User.ID, trip.ID)
+	}
+
+	tripfmt, err := s.renderTrip(trip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render trip: %w", err)
+	}
+
+	return &conversation.Prompt{
+		Text:     fmt.Sprintf("%s\n\nPlease confirm", tripfmt),
+		HTML:     true,
+		Keyboard: confirmKeyboard(),
+	}, nil
+}
+
+func confirmKeyboard() *tgbotapi.ReplyKeyboardMarkup {
+	return tu.Keyboard(
+		tu.KeyboardRow(
+			tu.KeyboardButton(confirmYes),
+		),
+		tu.KeyboardRow(
+			tu.KeyboardButton(confirmNo),
+		),
+	).WithResizeKeyboard().WithInputFieldPlaceholder("Confirm").WithOneTimeKeyboard()
+}
+
+func (s *Service) storeTripConfirm(ctx context.Context, sess *models.Session, text string) (models.State, error) {
+	confirmed, err := parseConfirm(text)
+	if err != nil {
+		return 0, err
+	}
+
+	if !confirmed {
+		if err := ops.DeleteTrip(ctx, s.backends, sess.UserState.Trip.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete trip: %w", err)
 		}
 
-		if err := s.createTrip(ctx, update); err != nil {
-			log.WithError(ctx, err).Error("Failed to create trip")
+		sess.UserState.Trip = nil
+
+		s.sendMessage(ctx, "Your trip is canceled. Thank you!")
+
+		return models.StateStart, nil
+	}
+
+	if sess.User.ID != sess.UserState.Trip.CreatedBy.ID {
+		return 0, fmt.Errorf("user %d is not the creator of the trip %s", sess.User.ID, sess.UserState.Trip.ID)
+	}
+
+	return 0, nil
+}
+
+func (s *Service) publishChatPrompt(ctx context.Context, sess *models.Session) (*conversation.Prompt, error) {
+	return nil, s.askPublishChat(ctx, sess)
+}
+
+// updateDraftTrip updates the trip in progress and keeps the session copy in sync.
+func (s *Service) updateDraftTrip(ctx context.Context, sess *models.Session, params ops.UpdateTripParams) error {
+	if sess.UserState.Trip == nil {
+		return errors.New("no trip in progress")
+	}
+
+	trip, err := ops.UpdateTrip(ctx, s.backends, sess.UserState.Trip.ID, params)
+	if err != nil {
+		return fmt.Errorf("failed to update trip: %w", err)
+	}
+
+	sess.UserState.Trip = trip
+
+	return nil
+}
+
+// forgetTrip drops the trip from the session, the trip itself is kept as is.
+func (s *Service) forgetTrip(_ context.Context, sess *models.Session) error {
+	sess.UserState.Trip = nil
+
+	return nil
+}
+
+// userTrips returns trips created by the user, which status satisfies keep.
+func (s *Service) userTrips(ctx context.Context, user *models.User, keep func(models.TripStatus) bool) ([]*models.Trip, error) {
+	list, err := ops.ListTripsByUser(ctx, s.backends, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trips: %w", err)
+	}
+
+	result := make([]*models.Trip, 0, len(list))
+
+	for _, t := range list {
+		if keep(t.Status) {
+			result = append(result, t)
+		}
+	}
+
+	return result, nil
+}
+
+// tripSelectKeyboard returns a keyboard with a button for each trip, see matchTripRef.
+func tripSelectKeyboard(list []*models.Trip) *tgbotapi.ReplyKeyboardMarkup {
+	rows := make([][]tgbotapi.KeyboardButton, 0, len(list))
+
+	for _, t := range list {
+		rows = append(rows, tu.KeyboardRow(tu.KeyboardButton(fmt.Sprintf("%s - %s", t.ShortID(), t.Name))))
+	}
+
+	return tu.Keyboard(rows...).WithResizeKeyboard().WithInputFieldPlaceholder("Trip ID").WithOneTimeKeyboard()
+}
+
+// matchTripRef finds the trip chosen with tripSelectKeyboard button or by the typed trip ID.
+func matchTripRef(list []*models.Trip, text string) (*models.Trip, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	ref := strings.ToLower(fields[0])
+
+	for _, t := range list {
+		if t.ShortID() == ref || t.ID.String() == ref {
+			return t, true
 		}
 	}
+
+	return nil, false
+}
+
+// staticPrompt returns a prompt function with constant text and keyboard.
+func staticPrompt(text string, keyboard tgbotapi.ReplyMarkup) conversation.PromptFunc {
+	return func(context.Context, *models.Session) (*conversation.Prompt, error) {
+		return &conversation.Prompt{
+			Text:     text,
+			Keyboard: keyboard,
+		}, nil
+	}
 }
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+func TestMatchTripRef(t *testing.T) {
+	first := &models.Trip{ID: uuid.Must(uuid.FromString("0a1b2c3d-0000-4000-8000-000000000001")), Name: "Morning ride"}
+	second := &models.Trip{ID: uuid.Must(uuid.FromString("9f8e7d6c-0000-4000-8000-000000000002")), Name: "Evening ride"}
+
+	list := []*models.Trip{first, second}
+
+	testCases := []struct {
+		name   string
+		text   string
+		want   *models.Trip
+		wantOK bool
+	}{
+		{name: "button", text: "0a1b2c3d - Morning ride", want: first, wantOK: true},
+		{name: "short ID", text: "9F8E7D6C", want: second, wantOK: true},
+		{name: "full ID", text: second.ID.String(), want: second, wantOK: true},
+		{name: "name only", text: "Morning ride"},
+		{name: "unknown", text: "deadbeef"},
+		{name: "empty", text: "  "},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchTripRef(list, tt.text)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+	tgbotapi "github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/outbox"
+)
+
+const (
+	// tripsPageSize is a number of trips shown on a single page of the trips list.
+	tripsPageSize = 5
+
+	callbackTripsPrefix  = "trips:"
+	callbackTripsPage    = callbackTripsPrefix + "page:"
+	callbackTripsDetails = callbackTripsPrefix + "details:"
+)
+
+func (s *Service) tripsHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "command_handler", CmdTrips))
+
+		log.Debug(ctx, "Called trips handler")
+
+		list, err := ops.ListUpcomingTrips(ctx, s.backends, s.now())
+		if err != nil {
+			log.WithError(ctx, err).Error("Failed to list upcoming trips")
+
+			return
+		}
+
+		if len(list) == 0 {
+			msg := fmt.Sprintf("There are no upcoming trips. Use /%s command to create a new trip.", CmdNewTrip)
+
+			s.sendMessage(ctx, msg)
+
+			return
+		}
+
+		text, keyboard := tripsPage(list, 0)
+
+		if err = s.sendWithKeyboard(ctx, text, keyboard); err != nil {
+			log.WithError(ctx, err).Error("Failed to send trips list")
+		}
+	}
+}
+
+func (s *Service) tripsCallbackHandler() th.Handler {
+	return func(_ *tgbotapi.Bot, update tgbotapi.Update) {
+		ctx := update.Context()
+
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "callback_handler", CmdTrips))
+
+		query := update.CallbackQuery
+
+		log.WithField(ctx, "data", query.Data).Debug("Called trips callback handler")
+
+		defer s.answerCallback(ctx, query.ID, "")
+
+		switch data := query.Data; {
+		case strings.HasPrefix(data, callbackTripsPage):
+			page, err := strconv.Atoi(strings.TrimPrefix(data, callbackTripsPage))
+			if err != nil {
+				log.WithError(ctx, err).Warn("Invalid trips page")
+
+				return
+			}
+
+			s.showTripsPage(ctx, query, page)
+		case strings.HasPrefix(data, callbackTripsDetails):
+			id, err := uuid.FromString(strings.TrimPrefix(data, callbackTripsDetails))
+			if err != nil {
+				log.WithError(ctx, err).Warn("Invalid trip ID")
+
+				return
+			}
+
+			s.showTripDetails(ctx, id)
+		default:
+			log.Warn(ctx, "Unexpected trips callback")
+		}
+	}
+}
+
+// showTripsPage replaces the trips list message with the requested page.
+func (s *Service) showTripsPage(ctx context.Context, query *tgbotapi.CallbackQuery, page int) {
+	if query.Message == nil || !query.Message.IsAccessible() {
+		log.Warn(ctx, "Trips list message is not accessible")
+
+		return
+	}
+
+	list, err := ops.ListUpcomingTrips(ctx, s.backends, s.now())
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to list upcoming trips")
+
+		return
+	}
+
+	params := &tgbotapi.EditMessageTextParams{
+		ChatID:    tu.ID(query.Message.GetChat().ID),
+		MessageID: query.Message.GetMessageID(),
+	}
+
+	if len(list) == 0 {
+		params.Text = "There are no upcoming trips."
+	} else {
+		params.Text, params.ReplyMarkup = tripsPage(list, page)
+	}
+
+	_, err = outbox.Do(ctx, s.outbox, query.Message.GetChat().ID, func() (*tgbotapi.Message, error) {
+		return s.bot.Client().EditMessageText(params)
+	})
+	if err != nil {
+		log.WithError(ctx, err).Error("Failed to edit trips list")
+	}
+}
+
+// showTripDetails sends the trip card.
+func (s *Service) showTripDetails(ctx context.Context, id uuid.UUID) {
+	trip, err := ops.GetTrip(ctx, s.backends, id)
+	if err != nil {
+		log.WithError(ctx, err).WithField("trip_id", id).Error("Failed to get trip")
+
+		return
+	}
+
+	tripfmt, err := s.renderTrip(trip)
+	if err != nil {
+		log.WithError(ctx, err).WithField("trip_id", id).Error("Failed to render trip")
+
+		return
+	}
+
+	s.sendHTML(ctx, fmt.Sprintf("%s\n\nUse /%s %s command to join the trip.", tripfmt, CmdSubscribe, trip.ShortID()))
+}
+
+func (s *Service) answerCallback(ctx context.Context, queryID, text string) {
+	if err := s.bot.Client().AnswerCallbackQuery(tu.CallbackQuery(queryID).WithText(text)); err != nil {
+		log.WithError(ctx, err).Warn("Failed to answer callback query")
+	}
+}
+
+// tripsPage returns a text and a keyboard of the trips list page, page number is clamped to the existing pages.
+func tripsPage(list []*models.Trip, page int) (string, *tgbotapi.InlineKeyboardMarkup) {
+	pages := (len(list) + tripsPageSize - 1) / tripsPageSize
+
+	page = max(0, min(page, pages-1))
+
+	start := page * tripsPageSize
+	end := min(start+tripsPageSize, len(list))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, end-start+1)
+
+	for _, t := range list[start:end] {
+		rows = append(rows, tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(fmt.Sprintf("Details: %s", t.Name)).
+				WithCallbackData(callbackTripsDetails+t.ID.String()),
+		))
+	}
+
+	nav := make([]tgbotapi.InlineKeyboardButton, 0, 2)
+
+	if page > 0 {
+		nav = append(nav, tu.InlineKeyboardButton("« Prev").
+			WithCallbackData(callbackTripsPage+strconv.Itoa(page-1)))
+	}
+
+	if page < pages-1 {
+		nav = append(nav, tu.InlineKeyboardButton("Next »").
+			WithCallbackData(callbackTripsPage+strconv.Itoa(page+1)))
+	}
+
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	text := fmt.Sprintf("Upcoming trips (page %d of %d):\n\n%s", page+1, pages, formatTripRefs(list[start:end]))
+
+	return text, tu.InlineKeyboard(rows...)
+}
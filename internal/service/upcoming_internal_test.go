@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/ops/opstest"
+)
+
+func TestTripsPage(t *testing.T) {
+	// 12 trips make two full pages and a last page with 2 trips.
+	list := make([]*models.Trip, 12)
+	for i := range list {
+		list[i] = &models.Trip{ID: uuid.Must(uuid.NewV4()), Name: fmt.Sprintf("Ride %d", i)}
+	}
+
+	testCases := []struct {
+		name      string
+		list      []*models.Trip
+		page      int
+		wantPage  string
+		wantTrips []*models.Trip
+		wantNav   []string
+	}{
+		{
+			name:      "first page",
+			list:      list,
+			page:      0,
+			wantPage:  "page 1 of 3",
+			wantTrips: list[:5],
+			wantNav:   []string{callbackTripsPage + "1"},
+		},
+		{
+			name:      "middle page",
+			list:      list,
+			page:      1,
+			wantPage:  "page 2 of 3",
+			wantTrips: list[5:10],
+			wantNav:   []string{callbackTripsPage + "0", callbackTripsPage + "2"},
+		},
+		{
+			name:      "last page",
+			list:      list,
+			page:      2,
+			wantPage:  "page 3 of 3",
+			wantTrips: list[10:],
+			wantNav:   []string{callbackTripsPage + "1"},
+		},
+		{
+			name:      "page after the last",
+			list:      list,
+			page:      7,
+			wantPage:  "page 3 of 3",
+			wantTrips: list[10:],
+			wantNav:   []string{callbackTripsPage + "1"},
+		},
+		{
+			name:      "negative page",
+			list:      list,
+			page:      -1,
+			wantPage:  "page 1 of 3",
+			wantTrips: list[:5],
+			wantNav:   []string{callbackTripsPage + "1"},
+		},
+		{
+			name:      "single page",
+			list:      list[:5],
+			page:      0,
+			wantPage:  "page 1 of 1",
+			wantTrips: list[:5],
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			text, keyboard := tripsPage(tt.list, tt.page)
+
+			assert.Contains(t, text, tt.wantPage)
+
+			var (
+				details []string
+				nav     []string
+			)
+
+			for i, row := range keyboard.InlineKeyboard {
+				for _, button := range row {
+					if i < len(tt.wantTrips) {
+						details = append(details, button.CallbackData)
+
+						continue
+					}
+
+					nav = append(nav, button.CallbackData)
+				}
+			}
+
+			wantDetails := make([]string, 0, len(tt.wantTrips))
+
+			for _, trip := range tt.wantTrips {
+				wantDetails = append(wantDetails, callbackTripsDetails+trip.ID.String())
+
+				assert.Contains(t, text, trip.ShortID())
+			}
+
+			assert.Equal(t, wantDetails, details)
+			assert.Equal(t, tt.wantNav, nav)
+		})
+	}
+}
+
+// tripsCallback returns an update with the callback query from the trips list message.
+func tripsCallback(ctx context.Context, chatID int64, data string) tgbotapi.Update {
+	update := tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:   "query",
+			From: tgbotapi.User{ID: chatID},
+			Message: &tgbotapi.Message{
+				MessageID: 10,
+				Chat:      tgbotapi.Chat{ID: chatID, Type: tgbotapi.ChatTypePrivate},
+			},
+			Data: data,
+		},
+	}
+
+	return update.WithContext(ctx)
+}
+
+func TestService_Trips(t *testing.T) {
+	ctx := context.Background()
+
+	const riderID int64 = 2
+
+	b := opstest.NewBackends(t)
+	s, api := newTestService(t, b)
+
+	sess := opstest.NewSession(t, b, riderID)
+
+	s.tripsHandler()(nil, command(ctx, sess, "/trips"))
+
+	sent := api.sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "There are no upcoming trips. Use /newtrip command to create a new trip.", sent[0].Text)
+
+	s.tripsCallbackHandler()(nil, tripsCallback(ctx, riderID, callbackTripsPage+"1"))
+
+	sent = api.sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "editMessageText", sent[0].Method)
+	assert.Equal(t, "There are no upcoming trips.", sent[0].Text)
+	assert.Empty(t, sent[0].callbackData())
+	assert.Equal(t, []string{""}, api.answered())
+
+	for range tripsPageSize + 1 {
+		opstest.NewTrip(t, b, riderID, time.Now().Add(24*time.Hour), models.TripPublished)
+	}
+
+	s.tripsHandler()(nil, command(ctx, sess, "/trips"))
+
+	sent = api.sent()
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0].Text, "Upcoming trips (page 1 of 2)")
+	assert.Contains(t, sent[0].callbackData(), callbackTripsPage+"1")
+
+	testCases := []struct {
+		name     string
+		data     string
+		wantEdit bool
+		wantPage string
+		wantNav  string
+	}{
+		{
+			name:     "next page",
+			data:     callbackTripsPage + "1",
+			wantEdit: true,
+			wantPage: "page 2 of 2",
+			wantNav:  callbackTripsPage + "0",
+		},
+		{
+			name:     "out of range page",
+			data:     callbackTripsPage + "5",
+			wantEdit: true,
+			wantPage: "page 2 of 2",
+			wantNav:  callbackTripsPage + "0",
+		},
+		{
+			name:     "previous page",
+			data:     callbackTripsPage + "0",
+			wantEdit: true,
+			wantPage: "page 1 of 2",
+			wantNav:  callbackTripsPage + "1",
+		},
+		{
+			name: "invalid page",
+			data: callbackTripsPage + "next",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s.tripsCallbackHandler()(nil, tripsCallback(ctx, riderID, tt.data))
+
+			// Callback query is always answered, so the client stops showing the progress.
+			assert.Equal(t, []string{""}, api.answered())
+
+			sent := api.sent()
+			if !tt.wantEdit {
+				assert.Empty(t, sent)
+
+				return
+			}
+
+			require.Len(t, sent, 1)
+			assert.Equal(t, "editMessageText", sent[0].Method)
+			assert.Equal(t, riderID, sent[0].ChatID)
+			assert.Equal(t, 10, sent[0].MessageID)
+			assert.Contains(t, sent[0].Text, tt.wantPage)
+			assert.Contains(t, sent[0].callbackData(), tt.wantNav)
+		})
+	}
+}
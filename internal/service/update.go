@@ -0,0 +1,64 @@
+package service
+
+import (
+	tgbotapi "github.com/mymmrac/telego"
+)
+
+// updateSender returns a user that sent the update and a chat the update came from.
+// For updates without a chat, such as inline queries, and for chat membership changes the chat is
+// the private chat with the user, which has the same ID as the user. It returns false when the update has no user, e.g. channel posts or polls.
+func updateSender(update tgbotapi.Update) (*tgbotapi.User, int64, bool) {
+	switch {
+	case update.Message != nil:
+		return messageSender(update.Message)
+	case update.EditedMessage != nil:
+		return messageSender(update.EditedMessage)
+	case update.BusinessMessage != nil:
+		return messageSender(update.BusinessMessage)
+	case update.EditedBusinessMessage != nil:
+		return messageSender(update.EditedBusinessMessage)
+	case update.CallbackQuery != nil:
+		q := update.CallbackQuery
+
+		if q.Message != nil {
+			return &q.From, q.Message.GetChat().ID, true
+		}
+
+		// Callback from a message sent via inline mode.
+		return &q.From, q.From.ID, true
+	case update.InlineQuery != nil:
+		return &update.InlineQuery.From, update.InlineQuery.From.ID, true
+	case update.ChosenInlineResult != nil:
+		return &update.ChosenInlineResult.From, update.ChosenInlineResult.From.ID, true
+	case update.MyChatMember != nil:
+		// Membership changes are performed in groups, but the session is bound to the private chat.
+		return &update.MyChatMember.From, update.MyChatMember.From.ID, true
+	case update.ChatMember != nil:
+		return &update.ChatMember.From, update.ChatMember.From.ID, true
+	case update.ChatJoinRequest != nil:
+		return &update.ChatJoinRequest.From, update.ChatJoinRequest.UserChatID, true
+	case update.MessageReaction != nil && update.MessageReaction.User != nil:
+		return update.MessageReaction.User, update.MessageReaction.Chat.ID, true
+	case update.PollAnswer != nil && update.PollAnswer.User != nil:
+		return update.PollAnswer.User, update.PollAnswer.User.ID, true
+	case update.ShippingQuery != nil:
+		return &update.ShippingQuery.From, update.ShippingQuery.From.ID, true
+	case update.PreCheckoutQuery != nil:
+		return &update.PreCheckoutQuery.From, update.PreCheckoutQuery.From.ID, true
+	case update.PurchasedPaidMedia != nil:
+		return &update.PurchasedPaidMedia.From, update.PurchasedPaidMedia.From.ID, true
+	case update.BusinessConnection != nil:
+		return &update.BusinessConnection.User, update.BusinessConnection.UserChatID, true
+	default:
+		return nil, 0, false
+	}
+}
+
+func messageSender(msg *tgbotapi.Message) (*tgbotapi.User, int64, bool) {
+	if msg.From == nil {
+		// Messages sent on behalf of a chat have no user.
+		return nil, 0, false
+	}
+
+	return msg.From, msg.Chat.ID, true
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateSender(t *testing.T) {
+	user := tgbotapi.User{ID: 1, Username: "user"}
+	group := tgbotapi.Chat{ID: -100, Type: tgbotapi.ChatTypeSupergroup}
+
+	type expected struct {
+		userID int64
+		chatID int64
+		ok     bool
+	}
+
+	testCases := []struct {
+		name   string
+		update tgbotapi.Update
+		want   expected
+	}{
+		{
+			name: "Message",
+			update: tgbotapi.Update{
+				Message: &tgbotapi.Message{From: &user, Chat: group},
+			},
+			want: expected{userID: 1, chatID: -100, ok: true},
+		},
+		{
+			name: "Message on behalf of chat",
+			update: tgbotapi.Update{
+				Message: &tgbotapi.Message{SenderChat: &group, Chat: group},
+			},
+			want: expected{ok: false},
+		},
+		{
+			name: "Edited message",
+			update: tgbotapi.Update{
+				EditedMessage: &tgbotapi.Message{From: &user, Chat: group},
+			},
+			want: expected{userID: 1, chatID: -100, ok: true},
+		},
+		{
+			name: "Callback query",
+			update: tgbotapi.Update{
+				CallbackQuery: &tgbotapi.CallbackQuery{
+					From:    user,
+					Message: &tgbotapi.Message{Chat: group},
+				},
+			},
+			want: expected{userID: 1, chatID: -100, ok: true},
+		},
+		{
+			name: "Callback query from inline message",
+			update: tgbotapi.Update{
+				CallbackQuery: &tgbotapi.CallbackQuery{
+					From:            user,
+					InlineMessageID: "inline",
+				},
+			},
+			want: expected{userID: 1, chatID: 1, ok: true},
+		},
+		{
+			name: "Inline query",
+			update: tgbotapi.Update{
+				InlineQuery: &tgbotapi.InlineQuery{From: user},
+			},
+			want: expected{userID: 1, chatID: 1, ok: true},
+		},
+		{
+			name: "My chat member",
+			update: tgbotapi.Update{
+				MyChatMember: &tgbotapi.ChatMemberUpdated{From: user, Chat: group},
+			},
+			want: expected{userID: 1, chatID: 1, ok: true},
+		},
+		{
+			name: "Anonymous poll answer",
+			update: tgbotapi.Update{
+				PollAnswer: &tgbotapi.PollAnswer{VoterChat: &group},
+			},
+			want: expected{ok: false},
+		},
+		{
+			name: "Channel post",
+			update: tgbotapi.Update{
+				ChannelPost: &tgbotapi.Message{Chat: group},
+			},
+			want: expected{ok: false},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			from, chatID, ok := updateSender(tt.update)
+			assert.Equal(t, tt.want.ok, ok)
+
+			if !tt.want.ok {
+				assert.Nil(t, from)
+
+				return
+			}
+
+			assert.Equal(t, tt.want.userID, from.ID)
+			assert.Equal(t, tt.want.chatID, chatID)
+		})
+	}
+}
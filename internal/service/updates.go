@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	tgbotapi "github.com/mymmrac/telego"
+	log "github.com/obalunenko/logger"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/dispatch"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/telegram"
+)
+
+// WebhookConfig is a configuration of receiving updates via webhook.
+type WebhookConfig struct {
+	// URL is a public HTTPS URL Telegram sends updates to.
+	URL string
+	// Path is a path the listener serves updates on, it may differ from the URL path behind a proxy.
+	Path string
+	// ListenAddr is an address the listener binds to, e.g. ":8080".
+	ListenAddr string
+	// SecretToken is sent by Telegram in every request and validated by the listener.
+	SecretToken string
+	// CertFile and KeyFile enable TLS on the listener, leave them empty when TLS is terminated by a proxy.
+	CertFile string
+	KeyFile  string
+}
+
+// secretTokenRegexp matches a secret token allowed by Telegram.
+var secretTokenRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+func (c WebhookConfig) validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("webhook url %q must be an absolute https url", c.URL)
+	}
+
+	if len(c.Path) == 0 || c.Path[0] != '/' {
+		return fmt.Errorf("webhook path %q must start with /", c.Path)
+	}
+
+	if c.ListenAddr == "" {
+		return errors.New("webhook listen address is empty")
+	}
+
+	if !secretTokenRegexp.MatchString(c.SecretToken) {
+		return errors.New("webhook secret token must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+	}
+
+	return nil
+}
+
+// WithWebhook makes the service receive updates via webhook instead of long polling.
+func WithWebhook(cfg WebhookConfig) Option {
+	return func(o *options) {
+		o.webhook = &cfg
+	}
+}
+
+// DispatchConfig configures concurrent processing of updates.
+// Updates of the same user are always handled one by one in the order they were received.
+type DispatchConfig struct {
+	// Workers is a maximum number of updates handled at the same time.
+	Workers int
+	// QueueSize is a maximum number of updates waiting or being handled,
+	// receiving of new updates is paused when it's reached.
+	QueueSize int
+}
+
+const (
+	defaultDispatchWorkers   = 16
+	defaultDispatchQueueSize = 256
+)
+
+// WithDispatch configures concurrent processing of updates.
+func WithDispatch(cfg DispatchConfig) Option {
+	return func(o *options) {
+		o.dispatch = cfg
+	}
+}
+
+// newDispatcher creates a dispatcher, which orders updates by the sender.
+func newDispatcher(cfg DispatchConfig) (*dispatch.Dispatcher, error) {
+	d, err := dispatch.New(dispatch.Params{
+		Workers:   cfg.Workers,
+		QueueSize: cfg.QueueSize,
+		Key: func(update tgbotapi.Update) (int64, bool) {
+			from, _, ok := updateSender(update)
+			if !ok {
+				return 0, false
+			}
+
+			return from.ID, true
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid dispatch config: %w", err)
+	}
+
+	return d, nil
+}
+
+// receiveUpdates starts receiving updates via webhook if it is configured, via long polling otherwise.
+// Returned function stops receiving updates.
+func (s *Service) receiveUpdates(ctx context.Context) (<-chan tgbotapi.Update, stopFunc, error) {
+	if s.webhook != nil {
+		return s.receiveViaWebhook(ctx, *s.webhook)
+	}
+
+	return s.receiveViaLongPolling(ctx)
+}
+
+func (s *Service) receiveViaLongPolling(ctx context.Context) (<-chan tgbotapi.Update, stopFunc, error) {
+	// Telegram doesn't deliver updates via long polling while a webhook is set.
+	if err := s.bot.Client().DeleteWebhook(&tgbotapi.DeleteWebhookParams{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	updates, err := s.bot.Client().UpdatesViaLongPolling(&tgbotapi.GetUpdatesParams{},
+		tgbotapi.WithLongPollingContext(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get updates via long polling: %w", err)
+	}
+
+	log.Info(ctx, "Receiving updates via long polling")
+
+	return updates, func(ctx context.Context) {
+		log.Info(ctx, "Stop receiving updates")
+
+		s.bot.Client().StopLongPolling()
+	}, nil
+}
+
+func (s *Service) receiveViaWebhook(ctx context.Context, cfg WebhookConfig) (<-chan tgbotapi.Update, stopFunc, error) {
+	srv, err := telegram.NewWebhookServer(telegram.WebhookServerParams{
+		SecretToken: cfg.SecretToken,
+		CertFile:    cfg.CertFile,
+		KeyFile:     cfg.KeyFile,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create webhook server: %w", err)
+	}
+
+	// Address is bound before the webhook is set, so startup errors are returned instead of failing in background.
+	if err = srv.Listen(cfg.ListenAddr); err != nil {
+		return nil, nil, fmt.Errorf("failed to start webhook server: %w", err)
+	}
+
+	updates, err := s.bot.Client().UpdatesViaWebhook(cfg.Path,
+		tgbotapi.WithWebhookServer(srv),
+		tgbotapi.WithWebhookSet(&tgbotapi.SetWebhookParams{
+			URL:         cfg.URL,
+			SecretToken: cfg.SecretToken,
+		}))
+	if err != nil {
+		if serr := srv.Stop(ctx); serr != nil {
+			log.WithError(ctx, serr).Error("Failed to stop webhook server")
+		}
+
+		return nil, nil, fmt.Errorf("failed to get updates via webhook: %w", err)
+	}
+
+	go func() {
+		if err := s.bot.Client().StartWebhook(cfg.ListenAddr); err != nil {
+			log.WithError(ctx, err).Error("Webhook server failed")
+		}
+	}()
+
+	log.WithFields(ctx, log.Fields{
+		"listen_addr": cfg.ListenAddr,
+		"path":        cfg.Path,
+		"tls":         cfg.CertFile != "",
+	}).Info("Receiving updates via webhook")
+
+	return updates, func(ctx context.Context) {
+		log.Info(ctx, "Stop receiving updates")
+
+		// Webhook is kept set, so Telegram queues updates until the bot is back.
+		if err := s.bot.Client().StopWebhookWithContext(ctx); err != nil {
+			log.WithError(ctx, err).Error("Failed to stop webhook server")
+		}
+	}, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookConfig_validate(t *testing.T) {
+	valid := WebhookConfig{
+		URL:         "https://example.com/bot",
+		Path:        "/bot",
+		ListenAddr:  ":8080",
+		SecretToken: "s3cret_token-1",
+	}
+
+	testCases := []struct {
+		name    string
+		modify  func(c *WebhookConfig)
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "valid",
+			modify:  func(*WebhookConfig) {},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "plain http url",
+			modify:  func(c *WebhookConfig) { c.URL = "http://example.com/bot" },
+			wantErr: assert.Error,
+		},
+		{
+			name:    "relative url",
+			modify:  func(c *WebhookConfig) { c.URL = "/bot" },
+			wantErr: assert.Error,
+		},
+		{
+			name:    "path without leading slash",
+			modify:  func(c *WebhookConfig) { c.Path = "bot" },
+			wantErr: assert.Error,
+		},
+		{
+			name:    "empty listen address",
+			modify:  func(c *WebhookConfig) { c.ListenAddr = "" },
+			wantErr: assert.Error,
+		},
+		{
+			name:    "empty secret token",
+			modify:  func(c *WebhookConfig) { c.SecretToken = "" },
+			wantErr: assert.Error,
+		},
+		{
+			name:    "secret token with forbidden characters",
+			modify:  func(c *WebhookConfig) { c.SecretToken = "secret token!" },
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+
+			tt.wantErr(t, cfg.validate())
+		})
+	}
+}
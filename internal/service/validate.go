@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/models"
+)
+
+const (
+	// maxTripNameLen is a maximum length of the trip name in characters.
+	maxTripNameLen = 100
+	// maxTripDescriptionLen is a maximum length of the trip description in characters,
+	// so the announcement fits into a single Telegram message.
+	maxTripDescriptionLen = 2000
+	// maxCancelReasonLen is a maximum length of the trip cancellation reason in characters.
+	maxCancelReasonLen = 200
+)
+
+const (
+	confirmYes = "yes"
+	confirmNo  = "no"
+)
+
+func (s *Service) validateTripName(_ context.Context, _ *models.Session, text string) error {
+	return validateText("Trip name", text, maxTripNameLen)
+}
+
+func (s *Service) validateTripDescription(_ context.Context, _ *models.Session, text string) error {
+	return validateText("Trip description", text, maxTripDescriptionLen)
+}
+
+func (s *Service) validateCancelReason(_ context.Context, _ *models.Session, text string) error {
+	return validateText("Cancellation reason", text, maxCancelReasonLen)
+}
+
+func (s *Service) validateTripConfirm(_ context.Context, _ *models.Session, text string) error {
+	_, err := parseConfirm(text)
+
+	return err
+}
+
+// validateText checks that the text is not blank and not longer than maxLen characters.
+func validateText(field, text string, maxLen int) error {
+	text = strings.TrimSpace(text)
+
+	if text == "" {
+		return conversation.Invalid(fmt.Sprintf("%s can't be empty", field))
+	}
+
+	if n := utf8.RuneCountInString(text); n > maxLen {
+		return conversation.Invalid(fmt.Sprintf("%s is too long (%d characters), the limit is %d", field, n, maxLen))
+	}
+
+	return nil
+}
+
+// parseConfirm parses a strict yes or no answer.
+func parseConfirm(text string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case confirmYes:
+		return true, nil
+	case confirmNo:
+		return false, nil
+	default:
+		return false, conversation.Invalid(fmt.Sprintf("Please answer %q or %q", confirmYes, confirmNo))
+	}
+}
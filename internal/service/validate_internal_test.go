@@ -0,0 +1,143 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/conversation"
+)
+
+func TestValidateText(t *testing.T) {
+	testCases := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			text: "Ride",
+		},
+		{
+			name: "max length in characters",
+			text: strings.Repeat("й", 10),
+		},
+		{
+			name:    "empty",
+			text:    "",
+			wantErr: "Trip name can't be empty",
+		},
+		{
+			name:    "blank",
+			text:    " \n\t",
+			wantErr: "Trip name can't be empty",
+		},
+		{
+			name:    "too long",
+			text:    strings.Repeat("a", 11),
+			wantErr: "Trip name is too long (11 characters), the limit is 10",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateText("Trip name", tt.text, 10)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			var verr *conversation.ValidationError
+
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.wantErr, verr.Reason)
+		})
+	}
+}
+
+func TestParseConfirm(t *testing.T) {
+	testCases := []struct {
+		text    string
+		want    bool
+		wantErr bool
+	}{
+		{text: "yes", want: true},
+		{text: " Yes ", want: true},
+		{text: "no", want: false},
+		{text: "NO", want: false},
+		{text: "y", wantErr: true},
+		{text: "sure", wantErr: true},
+		{text: "", wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseConfirm(tt.text)
+			if tt.wantErr {
+				var verr *conversation.ValidationError
+
+				require.ErrorAs(t, err, &verr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateTripStart(t *testing.T) {
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		startsAt time.Time
+		hasTime  bool
+		wantErr  error
+	}{
+		{
+			name:     "today without time",
+			startsAt: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "yesterday without time",
+			startsAt: time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC),
+			wantErr:  errDateInPast,
+		},
+		{
+			name:     "later today",
+			startsAt: now.Add(time.Hour),
+			hasTime:  true,
+		},
+		{
+			name:     "earlier today",
+			startsAt: now.Add(-time.Hour),
+			hasTime:  true,
+			wantErr:  errTimeInPast,
+		},
+		{
+			name:     "now",
+			startsAt: now,
+			hasTime:  true,
+			wantErr:  errTimeInPast,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTripStart(tt.startsAt, tt.hasTime, now)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/obalunenko/logger"
+)
+
+// startWorker runs fn every interval until the returned function is called.
+// When runNow is set, fn also runs right away, e.g. to catch up after downtime.
+func startWorker(ctx context.Context, name string, interval time.Duration, runNow bool,
+	fn func(ctx context.Context),
+) stopFunc {
+	ctx, cancel := context.WithCancel(log.ContextWithLogger(ctx, log.WithField(ctx, "worker", name)))
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		if runNow {
+			fn(ctx)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fn(ctx)
+			}
+		}
+	}()
+
+	return func(ctx context.Context) {
+		ctx = log.ContextWithLogger(ctx, log.WithField(ctx, "worker", name))
+
+		log.Info(ctx, "Stopping worker")
+
+		cancel()
+		wg.Wait()
+
+		log.Info(ctx, "Worker stopped")
+	}
+}
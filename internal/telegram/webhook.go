@@ -0,0 +1,169 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/mymmrac/telego"
+)
+
+const (
+	// maxWebhookBodySize limits the size of the update request body.
+	maxWebhookBodySize = 1 << 20
+	readHeaderTimeout  = 10 * time.Second
+)
+
+// WebhookServerParams is a params for NewWebhookServer function.
+type WebhookServerParams struct {
+	// SecretToken is expected in the X-Telegram-Bot-Api-Secret-Token header of every request.
+	SecretToken string
+	// CertFile and KeyFile enable TLS, leave them empty when TLS is terminated by a proxy.
+	CertFile string
+	KeyFile  string
+}
+
+// WebhookServer is an HTTP server receiving updates from Telegram.
+// It implements tgbotapi.WebhookServer.
+type WebhookServer struct {
+	server      *http.Server
+	listener    net.Listener
+	mux         *http.ServeMux
+	secretToken string
+	certFile    string
+	keyFile     string
+}
+
+// NewWebhookServer creates a new WebhookServer.
+func NewWebhookServer(p WebhookServerParams) (*WebhookServer, error) {
+	if p.SecretToken == "" {
+		return nil, errors.New("secret token is empty")
+	}
+
+	if (p.CertFile == "") != (p.KeyFile == "") {
+		return nil, errors.New("both certificate and key files must be set for TLS")
+	}
+
+	mux := http.NewServeMux()
+
+	return &WebhookServer{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+		mux:         mux,
+		secretToken: p.SecretToken,
+		certFile:    p.CertFile,
+		keyFile:     p.KeyFile,
+	}, nil
+}
+
+// Listen loads the TLS certificate and binds the address, so a busy port or an invalid certificate
+// are reported before the server starts. Start serves on the bound address.
+func (w *WebhookServer) Listen(address string) error {
+	if w.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(w.certFile, w.keyFile)
+		if err != nil {
+			return fmt.Errorf("webhook server: load certificate: %w", err)
+		}
+
+		w.server.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("webhook server: %w", err)
+	}
+
+	w.listener = ln
+
+	return nil
+}
+
+// Start serves requests, it blocks until the server is stopped.
+// The address is bound unless Listen was called before.
+func (w *WebhookServer) Start(address string) error {
+	if w.listener == nil {
+		if err := w.Listen(address); err != nil {
+			return err
+		}
+	}
+
+	var err error
+
+	if w.server.TLSConfig != nil {
+		err = w.server.ServeTLS(w.listener, "", "")
+	} else {
+		err = w.server.Serve(w.listener)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("webhook server: %w", err)
+	}
+
+	return nil
+}
+
+// Stop gracefully stops the server.
+func (w *WebhookServer) Stop(ctx context.Context) error {
+	err := w.server.Shutdown(ctx)
+
+	// Shutdown closes the listener only if serving has started.
+	if w.listener != nil {
+		if cerr := w.listener.Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) && err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// RegisterHandler registers the update handler for the path.
+// Only POST requests with a valid secret token are passed to the handler.
+func (w *WebhookServer) RegisterHandler(path string, handler tgbotapi.WebhookHandler) error {
+	w.mux.HandleFunc(path, func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		token := r.Header.Get(tgbotapi.WebhookSecretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(w.secretToken)) != 1 {
+			rw.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		data, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxWebhookBodySize))
+		if err != nil {
+			rw.WriteHeader(http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		if err = handler(context.WithoutCancel(r.Context()), data); err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	return nil
+}
+
+// ServeHTTP serves requests with registered handlers.
+func (w *WebhookServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mux.ServeHTTP(rw, r)
+}
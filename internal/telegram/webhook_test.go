@@ -0,0 +1,228 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhookServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  WebhookServerParams
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "plain http",
+			params:  WebhookServerParams{SecretToken: "secret"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "tls",
+			params:  WebhookServerParams{SecretToken: "secret", CertFile: "cert.pem", KeyFile: "key.pem"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "empty secret token",
+			params:  WebhookServerParams{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "certificate without key",
+			params:  WebhookServerParams{SecretToken: "secret", CertFile: "cert.pem"},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookServer(tt.params)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestWebhookServer_RegisterHandler(t *testing.T) {
+	const path = "/webhook"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		handlerErr error
+		wantStatus int
+		wantData   string
+	}{
+		{
+			name:       "valid request",
+			method:     http.MethodPost,
+			path:       path,
+			token:      "secret",
+			wantStatus: http.StatusOK,
+			wantData:   `{"update_id":1}`,
+		},
+		{
+			name:       "wrong secret token",
+			method:     http.MethodPost,
+			path:       path,
+			token:      "wrong",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing secret token",
+			method:     http.MethodPost,
+			path:       path,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			path:       path,
+			token:      "secret",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "unknown path",
+			method:     http.MethodPost,
+			path:       "/other",
+			token:      "secret",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "handler error",
+			method:     http.MethodPost,
+			path:       path,
+			token:      "secret",
+			handlerErr: errors.New("handler error"),
+			wantStatus: http.StatusInternalServerError,
+			wantData:   `{"update_id":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := NewWebhookServer(WebhookServerParams{SecretToken: "secret"})
+			require.NoError(t, err)
+
+			var got string
+
+			err = srv.RegisterHandler(path, func(_ context.Context, data []byte) error {
+				got = string(data)
+
+				return tt.handlerErr
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"update_id":1}`))
+
+			if tt.token != "" {
+				req.Header.Set(tgbotapi.WebhookSecretTokenHeader, tt.token)
+			}
+
+			rec := httptest.NewRecorder()
+
+			srv.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantData, got)
+		})
+	}
+}
+
+func TestWebhookServer_Listen(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, busy.Close())
+	})
+
+	tests := []struct {
+		name    string
+		params  WebhookServerParams
+		address string
+	}{
+		{
+			name:    "address in use",
+			params:  WebhookServerParams{SecretToken: "secret"},
+			address: busy.Addr().String(),
+		},
+		{
+			name: "invalid certificate",
+			params: WebhookServerParams{
+				SecretToken: "secret",
+				CertFile:    filepath.Join(t.TempDir(), "cert.pem"),
+				KeyFile:     filepath.Join(t.TempDir(), "key.pem"),
+			},
+			address: "127.0.0.1:0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := NewWebhookServer(tt.params)
+			require.NoError(t, err)
+
+			require.Error(t, srv.Listen(tt.address))
+			require.Error(t, srv.Start(tt.address), "start must fail the same way without listen")
+		})
+	}
+}
+
+func TestWebhookServer_StartAndStop(t *testing.T) {
+	srv, err := NewWebhookServer(WebhookServerParams{SecretToken: "secret"})
+	require.NoError(t, err)
+
+	require.NoError(t, srv.RegisterHandler("/webhook", func(context.Context, []byte) error {
+		return nil
+	}))
+
+	require.NoError(t, srv.Listen("127.0.0.1:0"))
+
+	address := srv.listener.Addr().String()
+
+	started := make(chan error, 1)
+
+	go func() {
+		started <- srv.Start(address)
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+address+"/webhook", strings.NewReader(`{"update_id":1}`))
+	require.NoError(t, err)
+
+	req.Header.Set(tgbotapi.WebhookSecretTokenHeader, "secret")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, srv.Stop(context.Background()))
+	require.NoError(t, <-started)
+}
+
+func TestWebhookServer_StopBeforeStart(t *testing.T) {
+	srv, err := NewWebhookServer(WebhookServerParams{SecretToken: "secret"})
+	require.NoError(t, err)
+
+	require.NoError(t, srv.Listen("127.0.0.1:0"))
+
+	address := srv.listener.Addr().String()
+
+	require.NoError(t, srv.Stop(context.Background()))
+
+	// Address is released even though the server never served.
+	ln, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+}
@@ -0,0 +1,348 @@
+// Package timeparse parses trip dates and times entered by users in free form.
+//
+// Supported date inputs (case-insensitive, English and Ukrainian):
+//   - relative words: today, tomorrow, day after tomorrow, сьогодні, завтра, післязавтра;
+//   - weekday names: saturday, sat, next friday, on monday, субота, наступна п'ятниця;
+//   - ISO dates: 2026-10-20;
+//   - localized numeric dates: 20.10.2026, 20/10/2026, 20.10.26, 20.10, 20/10;
+//   - dates with month names: 20 Oct 2026, 20 October, Oct 20, 20 жовтня.
+//
+// Dates without a year resolve to the nearest upcoming date.
+//
+// Supported time inputs: 9:30, 09.30, 0930, 9h30, 9, 9am, 9:30 pm.
+package timeparse
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidDate is returned when input can't be parsed as a date.
+	ErrInvalidDate = errors.New("invalid date")
+	// ErrInvalidTime is returned when input can't be parsed as a time of day.
+	ErrInvalidTime = errors.New("invalid time")
+)
+
+const daysInWeek = 7
+
+var relativeDays = map[string]int{
+	"today":              0,
+	"tomorrow":           1,
+	"day after tomorrow": 2,
+	"сьогодні":           0,
+	"завтра":             1,
+	"післязавтра":        2,
+}
+
+var weekdays = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"tues":      time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"thur":      time.Thursday,
+	"thurs":     time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"понеділок": time.Monday,
+	"пн":        time.Monday,
+	"вівторок":  time.Tuesday,
+	"вт":        time.Tuesday,
+	"середа":    time.Wednesday,
+	"середу":    time.Wednesday,
+	"ср":        time.Wednesday,
+	"четвер":    time.Thursday,
+	"чт":        time.Thursday,
+	"п'ятниця":  time.Friday,
+	"п'ятницю":  time.Friday,
+	"пт":        time.Friday,
+	"субота":    time.Saturday,
+	"суботу":    time.Saturday,
+	"сб":        time.Saturday,
+	"неділя":    time.Sunday,
+	"неділю":    time.Sunday,
+	"нд":        time.Sunday,
+}
+
+// weekdayPrefixes are words that may precede weekday name.
+// Next means that the current weekday resolves to the next week.
+var weekdayPrefixes = map[string]bool{
+	"on":        false,
+	"this":      false,
+	"next":      true,
+	"у":         false,
+	"в":         false,
+	"цей":       false,
+	"цю":        false,
+	"наступний": true,
+	"наступна":  true,
+	"наступну":  true,
+	"наступне":  true,
+}
+
+// localizedMonths maps localized month names to English ones understood by time.Parse.
+var localizedMonths = map[string]string{
+	"січня":     "january",
+	"січень":    "january",
+	"лютого":    "february",
+	"лютий":     "february",
+	"березня":   "march",
+	"березень":  "march",
+	"квітня":    "april",
+	"квітень":   "april",
+	"травня":    "may",
+	"травень":   "may",
+	"червня":    "june",
+	"червень":   "june",
+	"липня":     "july",
+	"липень":    "july",
+	"серпня":    "august",
+	"серпень":   "august",
+	"вересня":   "september",
+	"вересень":  "september",
+	"жовтня":    "october",
+	"жовтень":   "october",
+	"листопада": "november",
+	"листопад":  "november",
+	"грудня":    "december",
+	"грудень":   "december",
+}
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"2.1.2006",
+	"2/1/2006",
+	"2.1.06",
+	"2/1/06",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2 2006",
+	"January 2 2006",
+}
+
+// yearlessLayouts are resolved to the nearest upcoming date.
+var yearlessLayouts = []string{
+	"2.1",
+	"2/1",
+	"2 Jan",
+	"2 January",
+	"Jan 2",
+	"January 2",
+}
+
+// ParseDate parses a date relative to now.
+// Returned time is the midnight of the date in now's location.
+func ParseDate(input string, now time.Time) (time.Time, error) {
+	s := normalize(input)
+	if s == "" {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	today := midnight(now)
+
+	if days, ok := relativeDays[s]; ok {
+		return today.AddDate(0, 0, days), nil
+	}
+
+	if d, ok := parseWeekday(s, today); ok {
+		return d, nil
+	}
+
+	s = translateMonths(s)
+
+	loc := now.Location()
+
+	for _, layout := range dateLayouts {
+		if d, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return d, nil
+		}
+	}
+
+	for _, layout := range yearlessLayouts {
+		d, err := time.ParseInLocation(layout, s, loc)
+		if err != nil {
+			continue
+		}
+
+		return nextDate(today, d.Month(), d.Day()), nil
+	}
+
+	return time.Time{}, ErrInvalidDate
+}
+
+// nextDate returns the first date with the month and day not before today.
+// February 29 is moved to the next leap year instead of rolling over to March 1.
+func nextDate(today time.Time, month time.Month, day int) time.Time {
+	for year := today.Year(); ; year++ {
+		d := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+		if d.Day() == day && !d.Before(today) {
+			return d
+		}
+	}
+}
+
+var (
+	timeRe      = regexp.MustCompile(`^(\d{1,2})(?:[:.h](\d{2}))?\s*(am|pm)?$`)
+	timeDigitRe = regexp.MustCompile(`^(\d{2})(\d{2})$`)
+)
+
+var timePrefixes = []string{"at ", "о ", "об "}
+
+const (
+	maxHour   = 23
+	maxMinute = 59
+	halfDay   = 12
+)
+
+// ParseTime parses a time of day and returns hour and minute.
+func ParseTime(input string) (hour, minute int, err error) {
+	s := normalize(input)
+
+	for _, p := range timePrefixes {
+		s = strings.TrimPrefix(s, p)
+	}
+
+	var meridiem string
+
+	if m := timeDigitRe.FindStringSubmatch(s); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		minute, _ = strconv.Atoi(m[2])
+	} else if m = timeRe.FindStringSubmatch(s); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+
+		meridiem = m[3]
+	} else {
+		return 0, 0, ErrInvalidTime
+	}
+
+	if meridiem != "" {
+		if hour < 1 || hour > halfDay {
+			return 0, 0, ErrInvalidTime
+		}
+
+		hour %= halfDay
+
+		if meridiem == "pm" {
+			hour += halfDay
+		}
+	}
+
+	if hour > maxHour || minute > maxMinute {
+		return 0, 0, ErrInvalidTime
+	}
+
+	return hour, minute, nil
+}
+
+// ParseDateTime parses a date with an optional time of day separated by space, e.g. "tomorrow 9:30".
+// hasTime reports whether time of day was present in the input.
+func ParseDateTime(input string, now time.Time) (t time.Time, hasTime bool, err error) {
+	s := normalize(input)
+
+	if d, err := ParseDate(s, now); err == nil {
+		return d, false, nil
+	}
+
+	for _, p := range timePrefixes {
+		s = strings.ReplaceAll(s, " "+p, " ")
+	}
+
+	idx := strings.LastIndex(s, " ")
+	if idx < 0 {
+		return time.Time{}, false, ErrInvalidDate
+	}
+
+	datePart, timePart := s[:idx], s[idx+1:]
+
+	// Meridiem may be separated from the time with space, e.g. "9:30 pm".
+	if timePart == "am" || timePart == "pm" {
+		idx = strings.LastIndex(datePart, " ")
+		if idx < 0 {
+			return time.Time{}, false, ErrInvalidDate
+		}
+
+		datePart, timePart = datePart[:idx], datePart[idx+1:]+timePart
+	}
+
+	d, err := ParseDate(datePart, now)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	hour, minute, err := ParseTime(timePart)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return Combine(d, hour, minute), true, nil
+}
+
+// Combine returns time of the given date with hour and minute set.
+func Combine(date time.Time, hour, minute int) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+}
+
+func parseWeekday(s string, today time.Time) (time.Time, bool) {
+	var next bool
+
+	if prefix, rest, ok := strings.Cut(s, " "); ok {
+		isNext, known := weekdayPrefixes[prefix]
+		if !known {
+			return time.Time{}, false
+		}
+
+		next, s = isNext, rest
+	}
+
+	wd, ok := weekdays[s]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	days := (int(wd) - int(today.Weekday()) + daysInWeek) % daysInWeek
+	if days == 0 && next {
+		days = daysInWeek
+	}
+
+	return today.AddDate(0, 0, days), true
+}
+
+func translateMonths(s string) string {
+	fields := strings.Fields(s)
+
+	for i, f := range fields {
+		if en, ok := localizedMonths[f]; ok {
+			fields[i] = en
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// normalize lower-cases input, collapses whitespaces, removes commas and unifies apostrophes.
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	s = strings.NewReplacer(",", " ", "’", "'", "ʼ", "'").Replace(s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,181 @@
+package timeparse_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/obalunenko/telegram-ride-announcer-bot/internal/timeparse"
+)
+
+// now is Saturday, 17 October 2026 15:04 in Kyiv.
+var now = time.Date(2026, time.October, 17, 15, 4, 0, 0, kyiv())
+
+func kyiv() *time.Location {
+	loc, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		panic(err)
+	}
+
+	return loc
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, kyiv())
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr error
+	}{
+		{input: "today", want: date(2026, time.October, 17)},
+		{input: " Tomorrow ", want: date(2026, time.October, 18)},
+		{input: "day after tomorrow", want: date(2026, time.October, 19)},
+		{input: "Завтра", want: date(2026, time.October, 18)},
+		{input: "післязавтра", want: date(2026, time.October, 19)},
+		{input: "saturday", want: date(2026, time.October, 17)},
+		{input: "next saturday", want: date(2026, time.October, 24)},
+		{input: "on Sun", want: date(2026, time.October, 18)},
+		{input: "friday", want: date(2026, time.October, 23)},
+		{input: "наступну суботу", want: date(2026, time.October, 24)},
+		{input: "п’ятниця", want: date(2026, time.October, 23)},
+		{input: "2026-10-20", want: date(2026, time.October, 20)},
+		{input: "20.10.2026", want: date(2026, time.October, 20)},
+		{input: "20/10/2026", want: date(2026, time.October, 20)},
+		{input: "1.11.26", want: date(2026, time.November, 1)},
+		{input: "20.10", want: date(2026, time.October, 20)},
+		{input: "10.01", want: date(2027, time.January, 10)},
+		{input: "20 Oct 2026", want: date(2026, time.October, 20)},
+		{input: "October 20, 2026", want: date(2026, time.October, 20)},
+		{input: "20 october", want: date(2026, time.October, 20)},
+		{input: "Jan 5", want: date(2027, time.January, 5)},
+		{input: "29.02", want: date(2028, time.February, 29)},
+		{input: "29 Feb", want: date(2028, time.February, 29)},
+		{input: "20 жовтня", want: date(2026, time.October, 20)},
+		{input: "5 січня 2027", want: date(2027, time.January, 5)},
+		{input: "", wantErr: timeparse.ErrInvalidDate},
+		{input: "someday", wantErr: timeparse.ErrInvalidDate},
+		{input: "next week", wantErr: timeparse.ErrInvalidDate},
+		{input: "32.10.2026", wantErr: timeparse.ErrInvalidDate},
+		{input: "30.02", wantErr: timeparse.ErrInvalidDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := timeparse.ParseDate(tt.input, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	type expected struct {
+		hour   int
+		minute int
+		err    error
+	}
+
+	tests := []struct {
+		input string
+		want  expected
+	}{
+		{input: "9:30", want: expected{hour: 9, minute: 30}},
+		{input: "09.30", want: expected{hour: 9, minute: 30}},
+		{input: "0930", want: expected{hour: 9, minute: 30}},
+		{input: "21h15", want: expected{hour: 21, minute: 15}},
+		{input: "7", want: expected{hour: 7}},
+		{input: "at 7", want: expected{hour: 7}},
+		{input: "о 7:05", want: expected{hour: 7, minute: 5}},
+		{input: "9am", want: expected{hour: 9}},
+		{input: "9:30 PM", want: expected{hour: 21, minute: 30}},
+		{input: "12am", want: expected{hour: 0}},
+		{input: "12pm", want: expected{hour: 12}},
+		{input: "24:00", want: expected{err: timeparse.ErrInvalidTime}},
+		{input: "10:60", want: expected{err: timeparse.ErrInvalidTime}},
+		{input: "13pm", want: expected{err: timeparse.ErrInvalidTime}},
+		{input: "morning", want: expected{err: timeparse.ErrInvalidTime}},
+		{input: "", want: expected{err: timeparse.ErrInvalidTime}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			hour, minute, err := timeparse.ParseTime(tt.input)
+			if tt.want.err != nil {
+				assert.ErrorIs(t, err, tt.want.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.hour, hour)
+			assert.Equal(t, tt.want.minute, minute)
+		})
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	type expected struct {
+		t       time.Time
+		hasTime bool
+		err     bool
+	}
+
+	tests := []struct {
+		input string
+		want  expected
+	}{
+		{
+			input: "2026-10-20 09:30",
+			want:  expected{t: time.Date(2026, time.October, 20, 9, 30, 0, 0, kyiv()), hasTime: true},
+		},
+		{
+			input: "tomorrow at 9am",
+			want:  expected{t: time.Date(2026, time.October, 18, 9, 0, 0, 0, kyiv()), hasTime: true},
+		},
+		{
+			input: "next friday 6:30 pm",
+			want:  expected{t: time.Date(2026, time.October, 23, 18, 30, 0, 0, kyiv()), hasTime: true},
+		},
+		{
+			input: "завтра о 8:00",
+			want:  expected{t: time.Date(2026, time.October, 18, 8, 0, 0, 0, kyiv()), hasTime: true},
+		},
+		{
+			input: "20 October",
+			want:  expected{t: date(2026, time.October, 20), hasTime: false},
+		},
+		{
+			input: "tomorrow noon",
+			want:  expected{err: true},
+		},
+		{
+			input: "9:30",
+			want:  expected{err: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, hasTime, err := timeparse.ParseDateTime(tt.input, now)
+			if tt.want.err {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.hasTime, hasTime)
+			assert.True(t, tt.want.t.Equal(got), "want %s, got %s", tt.want.t, got)
+		})
+	}
+}